@@ -7,6 +7,7 @@
 package server
 
 import (
+	_ "github.com/go-kratos/kratos/v2/errors"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -24,8 +25,19 @@ const (
 type ErrorReason int32
 
 const (
-	ErrorReason_SUCCESS        ErrorReason = 0
-	ErrorReason_USER_NOT_FOUND ErrorReason = 1
+	ErrorReason_SUCCESS             ErrorReason = 0
+	ErrorReason_USER_NOT_FOUND      ErrorReason = 1
+	ErrorReason_USER_ALREADY_EXISTS ErrorReason = 2
+	// sql.ErrNoRows on a query without a more specific reason
+	ErrorReason_RECORD_NOT_FOUND ErrorReason = 3
+	// duplicate key (mysql 1062) without a more specific reason
+	ErrorReason_ALREADY_EXISTS ErrorReason = 4
+	// foreign key constraint fails (mysql 1451, 1452)
+	ErrorReason_CONFLICT ErrorReason = 5
+	// deadlock or lock wait timeout (mysql 1213, 1205), safe to retry
+	ErrorReason_RETRYABLE ErrorReason = 6
+	// the caller is authenticated but not allowed to do it
+	ErrorReason_FORBIDDEN ErrorReason = 7
 )
 
 // Enum value maps for ErrorReason.
@@ -33,10 +45,22 @@ var (
 	ErrorReason_name = map[int32]string{
 		0: "SUCCESS",
 		1: "USER_NOT_FOUND",
+		2: "USER_ALREADY_EXISTS",
+		3: "RECORD_NOT_FOUND",
+		4: "ALREADY_EXISTS",
+		5: "CONFLICT",
+		6: "RETRYABLE",
+		7: "FORBIDDEN",
 	}
 	ErrorReason_value = map[string]int32{
-		"SUCCESS":        0,
-		"USER_NOT_FOUND": 1,
+		"SUCCESS":             0,
+		"USER_NOT_FOUND":      1,
+		"USER_ALREADY_EXISTS": 2,
+		"RECORD_NOT_FOUND":    3,
+		"ALREADY_EXISTS":      4,
+		"CONFLICT":            5,
+		"RETRYABLE":           6,
+		"FORBIDDEN":           7,
 	}
 )
 
@@ -72,10 +96,16 @@ var File_server_error_reason_proto protoreflect.FileDescriptor
 const file_server_error_reason_proto_rawDesc = "" +
 	"\n" +
 	"\x19server/error_reason.proto\x12\n" +
-	"api.server*.\n" +
+	"api.server\x1a\x13errors/errors.proto*\xc7\x01\n" +
 	"\vErrorReason\x12\v\n" +
-	"\aSUCCESS\x10\x00\x12\x12\n" +
-	"\x0eUSER_NOT_FOUND\x10\x01B1\n" +
+	"\aSUCCESS\x10\x00\x12\x18\n" +
+	"\x0eUSER_NOT_FOUND\x10\x01\x1a\x04\xa8E\x94\x03\x12\x1d\n" +
+	"\x13USER_ALREADY_EXISTS\x10\x02\x1a\x04\xa8E\x99\x03\x12\x1a\n" +
+	"\x10RECORD_NOT_FOUND\x10\x03\x1a\x04\xa8E\x94\x03\x12\x18\n" +
+	"\x0eALREADY_EXISTS\x10\x04\x1a\x04\xa8E\x99\x03\x12\x12\n" +
+	"\bCONFLICT\x10\x05\x1a\x04\xa8E\x99\x03\x12\x13\n" +
+	"\tRETRYABLE\x10\x06\x1a\x04\xa8E\xf7\x03\x12\x13\n" +
+	"\tFORBIDDEN\x10\a\x1a\x04\xa8E\x93\x03B1\n" +
 	"\n" +
 	"api.serverP\x01Z!server-template/api/server;serverb\x06proto3"
 
@@ -13,7 +13,11 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
+	_ "server-template/api/auth"
+	_ "server-template/api/log"
 	sync "sync"
 	unsafe "unsafe"
 )
@@ -129,24 +133,555 @@ func (x *CreateUserReply) GetId() string {
 	return ""
 }
 
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Country       string                 `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`
+	Email         string                 `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_server_server_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_server_server_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_server_server_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *User) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *User) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *User) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *User) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *User) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *User) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type GetUserReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserReq) Reset() {
+	*x = GetUserReq{}
+	mi := &file_server_server_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserReq) ProtoMessage() {}
+
+func (x *GetUserReq) ProtoReflect() protoreflect.Message {
+	mi := &file_server_server_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserReq.ProtoReflect.Descriptor instead.
+func (*GetUserReq) Descriptor() ([]byte, []int) {
+	return file_server_server_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetUserReq) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetUserReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserReply) Reset() {
+	*x = GetUserReply{}
+	mi := &file_server_server_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserReply) ProtoMessage() {}
+
+func (x *GetUserReply) ProtoReflect() protoreflect.Message {
+	mi := &file_server_server_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserReply.ProtoReflect.Descriptor instead.
+func (*GetUserReply) Descriptor() ([]byte, []int) {
+	return file_server_server_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetUserReply) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type ListUsersReq struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// default 20, max 100
+	PageSize int32 `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token returned by the previous call, empty for the first page
+	PageToken     string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUsersReq) Reset() {
+	*x = ListUsersReq{}
+	mi := &file_server_server_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersReq) ProtoMessage() {}
+
+func (x *ListUsersReq) ProtoReflect() protoreflect.Message {
+	mi := &file_server_server_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersReq.ProtoReflect.Descriptor instead.
+func (*ListUsersReq) Descriptor() ([]byte, []int) {
+	return file_server_server_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListUsersReq) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListUsersReq) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListUsersReply struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Users []*User                `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	// empty when there are no more pages
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUsersReply) Reset() {
+	*x = ListUsersReply{}
+	mi := &file_server_server_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUsersReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUsersReply) ProtoMessage() {}
+
+func (x *ListUsersReply) ProtoReflect() protoreflect.Message {
+	mi := &file_server_server_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUsersReply.ProtoReflect.Descriptor instead.
+func (*ListUsersReply) Descriptor() ([]byte, []int) {
+	return file_server_server_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListUsersReply) GetUsers() []*User {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ListUsersReply) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type UpdateUserReq struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Id      string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name    string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Country string                 `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`
+	Email   string                 `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	// fields to update, supports name, country and email
+	UpdateMask    *fieldmaskpb.FieldMask `protobuf:"bytes,5,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateUserReq) Reset() {
+	*x = UpdateUserReq{}
+	mi := &file_server_server_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateUserReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUserReq) ProtoMessage() {}
+
+func (x *UpdateUserReq) ProtoReflect() protoreflect.Message {
+	mi := &file_server_server_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateUserReq.ProtoReflect.Descriptor instead.
+func (*UpdateUserReq) Descriptor() ([]byte, []int) {
+	return file_server_server_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateUserReq) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateUserReq) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateUserReq) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *UpdateUserReq) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *UpdateUserReq) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type UpdateUserReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateUserReply) Reset() {
+	*x = UpdateUserReply{}
+	mi := &file_server_server_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateUserReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateUserReply) ProtoMessage() {}
+
+func (x *UpdateUserReply) ProtoReflect() protoreflect.Message {
+	mi := &file_server_server_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateUserReply.ProtoReflect.Descriptor instead.
+func (*UpdateUserReply) Descriptor() ([]byte, []int) {
+	return file_server_server_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateUserReply) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+type DeleteUserReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteUserReq) Reset() {
+	*x = DeleteUserReq{}
+	mi := &file_server_server_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteUserReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserReq) ProtoMessage() {}
+
+func (x *DeleteUserReq) ProtoReflect() protoreflect.Message {
+	mi := &file_server_server_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserReq.ProtoReflect.Descriptor instead.
+func (*DeleteUserReq) Descriptor() ([]byte, []int) {
+	return file_server_server_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeleteUserReq) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RestoreUserReq struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreUserReq) Reset() {
+	*x = RestoreUserReq{}
+	mi := &file_server_server_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreUserReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreUserReq) ProtoMessage() {}
+
+func (x *RestoreUserReq) ProtoReflect() protoreflect.Message {
+	mi := &file_server_server_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreUserReq.ProtoReflect.Descriptor instead.
+func (*RestoreUserReq) Descriptor() ([]byte, []int) {
+	return file_server_server_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RestoreUserReq) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
 var File_server_server_proto protoreflect.FileDescriptor
 
 const file_server_server_proto_rawDesc = "" +
 	"\n" +
 	"\x13server/server.proto\x12\n" +
-	"api.server\x1a\x1bbuf/validate/validate.proto\x1a$gnostic/openapi/v3/annotations.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\"\xe7\x02\n" +
+	"api.server\x1a\x0fauth/auth.proto\x1a\x1bbuf/validate/validate.proto\x1a$gnostic/openapi/v3/annotations.proto\x1a\x1cgoogle/api/annotations.proto\x1a\x1bgoogle/protobuf/empty.proto\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\rlog/log.proto\"\xeb\x02\n" +
 	"\rCreateUserReq\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12)\n" +
-	"\tlast_name\x18\x02 \x01(\tB\f\xbaH\t\xc8\x01\x01r\x04\x10\x02\x18\x1eR\blastName\x12 \n" +
-	"\x05email\x18\x03 \x01(\tB\n" +
-	"\xbaH\a\xc8\x01\x01r\x02`\x01R\x05email:\xf4\x01\xbaH\xf0\x01\x1a\x7f\n" +
+	"\tlast_name\x18\x02 \x01(\tB\f\xbaH\t\xc8\x01\x01r\x04\x10\x02\x18\x1eR\blastName\x12$\n" +
+	"\x05email\x18\x03 \x01(\tB\x0e\xbaH\a\xc8\x01\x01r\x02`\x01\x88\xb5\x18\x01R\x05email:\xf4\x01\xbaH\xf0\x01\x1a\x7f\n" +
 	"\x0ename.not.email\x124first name and last name cannot be the same as email\x1a7this.name != this.email && this.last_name != this.email\x1am\n" +
 	"\x0fname.length.max\x122name and last name must be less than 30 characters\x1a&size(this.name + this.last_name) <= 30\"!\n" +
 	"\x0fCreateUserReply\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id2\xca\x01\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xd6\x01\n" +
+	"\x04User\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\acountry\x18\x03 \x01(\tR\acountry\x12\x1a\n" +
+	"\x05email\x18\x04 \x01(\tB\x04\x88\xb5\x18\x01R\x05email\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"7\n" +
+	"\n" +
+	"GetUserReq\x12)\n" +
+	"\x02id\x18\x01 \x01(\tB\x19\xbaH\x16r\x142\x12^[1-9][0-9]{0,18}$R\x02id\"4\n" +
+	"\fGetUserReply\x12$\n" +
+	"\x04user\x18\x01 \x01(\v2\x10.api.server.UserR\x04user\"U\n" +
+	"\fListUsersReq\x12&\n" +
+	"\tpage_size\x18\x01 \x01(\x05B\t\xbaH\x06\x1a\x04\x18d(\x00R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\"`\n" +
+	"\x0eListUsersReply\x12&\n" +
+	"\x05users\x18\x01 \x03(\v2\x10.api.server.UserR\x05users\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\x9d\x03\n" +
+	"\rUpdateUserReq\x12)\n" +
+	"\x02id\x18\x01 \x01(\tB\x19\xbaH\x16r\x142\x12^[1-9][0-9]{0,18}$R\x02id\x12\x1b\n" +
+	"\x04name\x18\x02 \x01(\tB\a\xbaH\x04r\x02\x18\x1eR\x04name\x12\"\n" +
+	"\acountry\x18\x03 \x01(\tB\b\xbaH\x05r\x03\x18\xff\x01R\acountry\x12$\n" +
+	"\x05email\x18\x04 \x01(\tB\x0e\xbaH\a\xd8\x01\x01r\x02`\x01\x88\xb5\x18\x01R\x05email\x12C\n" +
+	"\vupdate_mask\x18\x05 \x01(\v2\x1a.google.protobuf.FieldMaskB\x06\xbaH\x03\xc8\x01\x01R\n" +
+	"updateMask:\xb4\x01\xbaH\xb0\x01\x1a\xad\x01\n" +
+	"\x11update_mask.paths\x121update_mask only supports name, country and email\x1aethis.update_mask.paths.size() > 0 && this.update_mask.paths.all(p, p in ['name', 'country', 'email'])\"7\n" +
+	"\x0fUpdateUserReply\x12$\n" +
+	"\x04user\x18\x01 \x01(\v2\x10.api.server.UserR\x04user\":\n" +
+	"\rDeleteUserReq\x12)\n" +
+	"\x02id\x18\x01 \x01(\tB\x19\xbaH\x16r\x142\x12^[1-9][0-9]{0,18}$R\x02id\";\n" +
+	"\x0eRestoreUserReq\x12)\n" +
+	"\x02id\x18\x01 \x01(\tB\x19\xbaH\x16r\x142\x12^[1-9][0-9]{0,18}$R\x02id2\x8c\x06\n" +
 	"\x06Server\x12p\n" +
 	"\n" +
-	"CreateUser\x12\x19.api.server.CreateUserReq\x1a\x1b.api.server.CreateUserReply\"*\xbaG\r\x12\vcreate user\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/user/create\x12N\n" +
+	"CreateUser\x12\x19.api.server.CreateUserReq\x1a\x1b.api.server.CreateUserReply\"*\xbaG\r\x12\vcreate user\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/user/create\x12_\n" +
+	"\aGetUser\x12\x16.api.server.GetUserReq\x1a\x18.api.server.GetUserReply\"\"\xbaG\n" +
+	"\x12\bget user\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/user/{id}\x12l\n" +
+	"\tListUsers\x12\x18.api.server.ListUsersReq\x1a\x1a.api.server.ListUsersReply\")\xbaG\f\x12\n" +
+	"list users\x8a\xb5\x18\x05admin\x82\xd3\xe4\x93\x02\v\x12\t/v1/users\x12n\n" +
+	"\n" +
+	"UpdateUser\x12\x19.api.server.UpdateUserReq\x1a\x1b.api.server.UpdateUserReply\"(\xbaG\r\x12\vupdate user\x82\xd3\xe4\x93\x02\x12:\x01*2\r/v1/user/{id}\x12t\n" +
+	"\n" +
+	"DeleteUser\x12\x19.api.server.DeleteUserReq\x1a\x16.google.protobuf.Empty\"3\xbaG\x12\x12\x10soft delete user\x8a\xb5\x18\x05admin\x82\xd3\xe4\x93\x02\x0f*\r/v1/user/{id}\x12\x8a\x01\n" +
+	"\vRestoreUser\x12\x1a.api.server.RestoreUserReq\x1a\x16.google.protobuf.Empty\"G\xbaG\x1b\x12\x19restore soft deleted user\x8a\xb5\x18\x05admin\x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/v1/user/{id}/restore\x12N\n" +
 	"\x04ping\x12\x16.google.protobuf.Empty\x1a\x16.google.protobuf.Empty\"\x16\xbaG\x06\x12\x04ping\x82\xd3\xe4\x93\x02\a\x12\x05/pingB#Z!server-template/api/server;serverb\x06proto3"
 
 var (
@@ -161,22 +696,49 @@ func file_server_server_proto_rawDescGZIP() []byte {
 	return file_server_server_proto_rawDescData
 }
 
-var file_server_server_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_server_server_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_server_server_proto_goTypes = []any{
-	(*CreateUserReq)(nil),   // 0: api.server.CreateUserReq
-	(*CreateUserReply)(nil), // 1: api.server.CreateUserReply
-	(*emptypb.Empty)(nil),   // 2: google.protobuf.Empty
+	(*CreateUserReq)(nil),         // 0: api.server.CreateUserReq
+	(*CreateUserReply)(nil),       // 1: api.server.CreateUserReply
+	(*User)(nil),                  // 2: api.server.User
+	(*GetUserReq)(nil),            // 3: api.server.GetUserReq
+	(*GetUserReply)(nil),          // 4: api.server.GetUserReply
+	(*ListUsersReq)(nil),          // 5: api.server.ListUsersReq
+	(*ListUsersReply)(nil),        // 6: api.server.ListUsersReply
+	(*UpdateUserReq)(nil),         // 7: api.server.UpdateUserReq
+	(*UpdateUserReply)(nil),       // 8: api.server.UpdateUserReply
+	(*DeleteUserReq)(nil),         // 9: api.server.DeleteUserReq
+	(*RestoreUserReq)(nil),        // 10: api.server.RestoreUserReq
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+	(*fieldmaskpb.FieldMask)(nil), // 12: google.protobuf.FieldMask
+	(*emptypb.Empty)(nil),         // 13: google.protobuf.Empty
 }
 var file_server_server_proto_depIdxs = []int32{
-	0, // 0: api.server.Server.CreateUser:input_type -> api.server.CreateUserReq
-	2, // 1: api.server.Server.ping:input_type -> google.protobuf.Empty
-	1, // 2: api.server.Server.CreateUser:output_type -> api.server.CreateUserReply
-	2, // 3: api.server.Server.ping:output_type -> google.protobuf.Empty
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	11, // 0: api.server.User.created_at:type_name -> google.protobuf.Timestamp
+	11, // 1: api.server.User.updated_at:type_name -> google.protobuf.Timestamp
+	2,  // 2: api.server.GetUserReply.user:type_name -> api.server.User
+	2,  // 3: api.server.ListUsersReply.users:type_name -> api.server.User
+	12, // 4: api.server.UpdateUserReq.update_mask:type_name -> google.protobuf.FieldMask
+	2,  // 5: api.server.UpdateUserReply.user:type_name -> api.server.User
+	0,  // 6: api.server.Server.CreateUser:input_type -> api.server.CreateUserReq
+	3,  // 7: api.server.Server.GetUser:input_type -> api.server.GetUserReq
+	5,  // 8: api.server.Server.ListUsers:input_type -> api.server.ListUsersReq
+	7,  // 9: api.server.Server.UpdateUser:input_type -> api.server.UpdateUserReq
+	9,  // 10: api.server.Server.DeleteUser:input_type -> api.server.DeleteUserReq
+	10, // 11: api.server.Server.RestoreUser:input_type -> api.server.RestoreUserReq
+	13, // 12: api.server.Server.ping:input_type -> google.protobuf.Empty
+	1,  // 13: api.server.Server.CreateUser:output_type -> api.server.CreateUserReply
+	4,  // 14: api.server.Server.GetUser:output_type -> api.server.GetUserReply
+	6,  // 15: api.server.Server.ListUsers:output_type -> api.server.ListUsersReply
+	8,  // 16: api.server.Server.UpdateUser:output_type -> api.server.UpdateUserReply
+	13, // 17: api.server.Server.DeleteUser:output_type -> google.protobuf.Empty
+	13, // 18: api.server.Server.RestoreUser:output_type -> google.protobuf.Empty
+	13, // 19: api.server.Server.ping:output_type -> google.protobuf.Empty
+	13, // [13:20] is the sub-list for method output_type
+	6,  // [6:13] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_server_server_proto_init() }
@@ -190,7 +752,7 @@ func file_server_server_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_server_server_proto_rawDesc), len(file_server_server_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -21,16 +21,31 @@ var _ = binding.EncodeURL
 const _ = http.SupportPackageIsVersion1
 
 const OperationServerCreateUser = "/api.server.Server/CreateUser"
+const OperationServerDeleteUser = "/api.server.Server/DeleteUser"
+const OperationServerGetUser = "/api.server.Server/GetUser"
+const OperationServerListUsers = "/api.server.Server/ListUsers"
 const OperationServerping = "/api.server.Server/ping"
+const OperationServerRestoreUser = "/api.server.Server/RestoreUser"
+const OperationServerUpdateUser = "/api.server.Server/UpdateUser"
 
 type ServerHTTPServer interface {
 	CreateUser(context.Context, *CreateUserReq) (*CreateUserReply, error)
+	DeleteUser(context.Context, *DeleteUserReq) (*emptypb.Empty, error)
+	GetUser(context.Context, *GetUserReq) (*GetUserReply, error)
+	ListUsers(context.Context, *ListUsersReq) (*ListUsersReply, error)
 	Ping(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	RestoreUser(context.Context, *RestoreUserReq) (*emptypb.Empty, error)
+	UpdateUser(context.Context, *UpdateUserReq) (*UpdateUserReply, error)
 }
 
 func RegisterServerHTTPServer(s *http.Server, srv ServerHTTPServer) {
 	r := s.Route("/")
 	r.POST("/v1/user/create", _Server_CreateUser0_HTTP_Handler(srv))
+	r.GET("/v1/user/{id}", _Server_GetUser0_HTTP_Handler(srv))
+	r.GET("/v1/users", _Server_ListUsers0_HTTP_Handler(srv))
+	r.PATCH("/v1/user/{id}", _Server_UpdateUser0_HTTP_Handler(srv))
+	r.DELETE("/v1/user/{id}", _Server_DeleteUser0_HTTP_Handler(srv))
+	r.POST("/v1/user/{id}/restore", _Server_RestoreUser0_HTTP_Handler(srv))
 	r.GET("/ping", _Server_Ping0_HTTP_Handler(srv))
 }
 
@@ -56,6 +71,119 @@ func _Server_CreateUser0_HTTP_Handler(srv ServerHTTPServer) func(ctx http.Contex
 	}
 }
 
+func _Server_GetUser0_HTTP_Handler(srv ServerHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in GetUserReq
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationServerGetUser)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.GetUser(ctx, req.(*GetUserReq))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*GetUserReply)
+		return ctx.Result(200, reply)
+	}
+}
+
+func _Server_ListUsers0_HTTP_Handler(srv ServerHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in ListUsersReq
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationServerListUsers)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.ListUsers(ctx, req.(*ListUsersReq))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*ListUsersReply)
+		return ctx.Result(200, reply)
+	}
+}
+
+func _Server_UpdateUser0_HTTP_Handler(srv ServerHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in UpdateUserReq
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationServerUpdateUser)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.UpdateUser(ctx, req.(*UpdateUserReq))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*UpdateUserReply)
+		return ctx.Result(200, reply)
+	}
+}
+
+func _Server_DeleteUser0_HTTP_Handler(srv ServerHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in DeleteUserReq
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationServerDeleteUser)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.DeleteUser(ctx, req.(*DeleteUserReq))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*emptypb.Empty)
+		return ctx.Result(200, reply)
+	}
+}
+
+func _Server_RestoreUser0_HTTP_Handler(srv ServerHTTPServer) func(ctx http.Context) error {
+	return func(ctx http.Context) error {
+		var in RestoreUserReq
+		if err := ctx.Bind(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindQuery(&in); err != nil {
+			return err
+		}
+		if err := ctx.BindVars(&in); err != nil {
+			return err
+		}
+		http.SetOperation(ctx, OperationServerRestoreUser)
+		h := ctx.Middleware(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.RestoreUser(ctx, req.(*RestoreUserReq))
+		})
+		out, err := h(ctx, &in)
+		if err != nil {
+			return err
+		}
+		reply := out.(*emptypb.Empty)
+		return ctx.Result(200, reply)
+	}
+}
+
 func _Server_Ping0_HTTP_Handler(srv ServerHTTPServer) func(ctx http.Context) error {
 	return func(ctx http.Context) error {
 		var in emptypb.Empty
@@ -77,7 +205,12 @@ func _Server_Ping0_HTTP_Handler(srv ServerHTTPServer) func(ctx http.Context) err
 
 type ServerHTTPClient interface {
 	CreateUser(ctx context.Context, req *CreateUserReq, opts ...http.CallOption) (rsp *CreateUserReply, err error)
+	DeleteUser(ctx context.Context, req *DeleteUserReq, opts ...http.CallOption) (rsp *emptypb.Empty, err error)
+	GetUser(ctx context.Context, req *GetUserReq, opts ...http.CallOption) (rsp *GetUserReply, err error)
+	ListUsers(ctx context.Context, req *ListUsersReq, opts ...http.CallOption) (rsp *ListUsersReply, err error)
 	Ping(ctx context.Context, req *emptypb.Empty, opts ...http.CallOption) (rsp *emptypb.Empty, err error)
+	RestoreUser(ctx context.Context, req *RestoreUserReq, opts ...http.CallOption) (rsp *emptypb.Empty, err error)
+	UpdateUser(ctx context.Context, req *UpdateUserReq, opts ...http.CallOption) (rsp *UpdateUserReply, err error)
 }
 
 type ServerHTTPClientImpl struct {
@@ -101,6 +234,45 @@ func (c *ServerHTTPClientImpl) CreateUser(ctx context.Context, in *CreateUserReq
 	return &out, nil
 }
 
+func (c *ServerHTTPClientImpl) DeleteUser(ctx context.Context, in *DeleteUserReq, opts ...http.CallOption) (*emptypb.Empty, error) {
+	var out emptypb.Empty
+	pattern := "/v1/user/{id}"
+	path := binding.EncodeURL(pattern, in, true)
+	opts = append(opts, http.Operation(OperationServerDeleteUser))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "DELETE", path, nil, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *ServerHTTPClientImpl) GetUser(ctx context.Context, in *GetUserReq, opts ...http.CallOption) (*GetUserReply, error) {
+	var out GetUserReply
+	pattern := "/v1/user/{id}"
+	path := binding.EncodeURL(pattern, in, true)
+	opts = append(opts, http.Operation(OperationServerGetUser))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "GET", path, nil, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *ServerHTTPClientImpl) ListUsers(ctx context.Context, in *ListUsersReq, opts ...http.CallOption) (*ListUsersReply, error) {
+	var out ListUsersReply
+	pattern := "/v1/users"
+	path := binding.EncodeURL(pattern, in, true)
+	opts = append(opts, http.Operation(OperationServerListUsers))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "GET", path, nil, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
 func (c *ServerHTTPClientImpl) Ping(ctx context.Context, in *emptypb.Empty, opts ...http.CallOption) (*emptypb.Empty, error) {
 	var out emptypb.Empty
 	pattern := "/ping"
@@ -113,3 +285,29 @@ func (c *ServerHTTPClientImpl) Ping(ctx context.Context, in *emptypb.Empty, opts
 	}
 	return &out, nil
 }
+
+func (c *ServerHTTPClientImpl) RestoreUser(ctx context.Context, in *RestoreUserReq, opts ...http.CallOption) (*emptypb.Empty, error) {
+	var out emptypb.Empty
+	pattern := "/v1/user/{id}/restore"
+	path := binding.EncodeURL(pattern, in, false)
+	opts = append(opts, http.Operation(OperationServerRestoreUser))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "POST", path, in, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *ServerHTTPClientImpl) UpdateUser(ctx context.Context, in *UpdateUserReq, opts ...http.CallOption) (*UpdateUserReply, error) {
+	var out UpdateUserReply
+	pattern := "/v1/user/{id}"
+	path := binding.EncodeURL(pattern, in, false)
+	opts = append(opts, http.Operation(OperationServerUpdateUser))
+	opts = append(opts, http.PathTemplate(pattern))
+	err := c.cc.Invoke(ctx, "PATCH", path, in, &out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
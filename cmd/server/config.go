@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"server-template/internal/conf"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
+)
+
+const configUsage = "usage: server config validate | print [-redacted=false] [-conf config.yaml]"
+
+// runConfig checks or prints the config after every override is applied, nothing is connected
+func runConfig(args []string) error {
+	fs, cf := newFlagSet("config")
+	redacted := fs.Bool("redacted", true, "replace secrets with ***")
+	args = parseArgs(fs, args)
+	if len(args) != 1 {
+		return errors.New(configUsage)
+	}
+
+	switch args[0] {
+	case "validate":
+		c, _, err := cf.load()
+		if err != nil {
+			return err
+		}
+		c.Close()
+		fmt.Printf("%s is valid\n", cf.conf)
+		return nil
+	case "print":
+		c, bc, err := cf.load()
+		if err != nil {
+			return err
+		}
+		c.Close()
+		if *redacted {
+			bc = conf.Redact(bc)
+		}
+		return printConfig(bc)
+	}
+	return errors.New(configUsage)
+}
+
+// printConfig prints bc as yaml with the field names of the config file
+func printConfig(bc *conf.Config) error {
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(bc)
+	if err != nil {
+		return errors.Wrap(err, "marshal config failed")
+	}
+	var v map[string]any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return errors.Wrap(err, "unmarshal config failed")
+	}
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "marshal config yaml failed")
+	}
+	fmt.Print(string(out))
+	return nil
+}
@@ -1,19 +1,31 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-kratos/kratos/v2"
 	"github.com/go-kratos/kratos/v2/config"
 	"github.com/go-kratos/kratos/v2/config/file"
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
 	"github.com/go-kratos/kratos/v2/transport/grpc"
 	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/protobuf/encoding/protojson"
 
 	"server-template/internal/conf"
+	"server-template/internal/data"
+	"server-template/internal/server"
+	"server-template/pkg/confsource"
+	"server-template/pkg/feature"
 	pkgLog "server-template/pkg/log"
 	"server-template/pkg/middleware"
+	"server-template/pkg/tracer"
 
 	_ "go.uber.org/automaxprocs"
 )
@@ -24,64 +36,200 @@ var (
 	Name string
 	// Version is the version of the compiled software.
 	Version string
-	// flagconf is the config flag.
-	flagconf string
 
 	id, _ = os.Hostname()
 )
 
-func init() {
-	flag.StringVar(&flagconf, "conf", "../../configs", "config path, eg: -conf config.yaml")
+const usage = `usage: server <command> [flags] [args]
+
+commands:
+  serve                          start the grpc, http and background servers, the default command
+  migrate up|down [n]|status|force <version>
+                                 run the embedded migrations on the master db
+  config validate                check the config and exit
+  config print [-redacted=false] print the merged config as yaml, secrets are redacted by default
+  version                        print the version and build info
+  seed                           insert the dev fixtures, refused when env is prod
+
+flags of every command but version, before or after the args:
+  -conf  config path, eg: -conf config.yaml
+  -set   override a config field, repeatable, eg: -set db.master.max_open_conn=50
+`
+
+// envPrefix of the config environment variables, eg: APP_DB_MASTER_DSN or APP_DB_MASTER_DSN_FILE
+const envPrefix = "APP"
+
+// configFlags locate the config, shared by the commands that need it
+type configFlags struct {
+	conf string
+	// set overrides config fields, after the file and the environment.
+	set confsource.Sets
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *configFlags) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	cf := &configFlags{}
+	fs.StringVar(&cf.conf, "conf", "config.yaml", "config path, eg: -conf config.yaml")
+	fs.Var(&cf.set, "set", "override a config field, repeatable, eg: -set db.master.max_open_conn=50")
+	return fs, cf
+}
+
+// parseArgs parses the flags found anywhere among args and returns the positional args,
+// eg: migrate down -conf config.yaml 2
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		// a negative number is an arg, eg: migrate force -1
+		for len(args) > 0 && isNegativeNumber(args[0]) {
+			positional = append(positional, args[0])
+			args = args[1:]
+		}
+		_ = fs.Parse(args) // exits on error
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func isNegativeNumber(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n < 0
+}
+
+// load reads and validates the config: the file, then APP_ environment variables, then -set flags.
+// Close the returned source when done.
+func (cf *configFlags) load() (config.Config, *conf.Config, error) {
+	desc := (&conf.Config{}).ProtoReflect().Descriptor()
+	c := config.New(
+		config.WithSource(
+			confsource.Layered(
+				file.NewSource(cf.conf),
+				confsource.Env(envPrefix, desc),
+				confsource.Flags(cf.set, desc),
+			),
+		),
+	)
+	if err := c.Load(); err != nil {
+		c.Close()
+		return nil, nil, err
+	}
+	bc, err := conf.Scan(c)
+	if err != nil {
+		c.Close()
+		return nil, nil, err
+	}
+	return c, bc, nil
 }
 
-func newApp(logger log.Logger, gs *grpc.Server, hs *http.Server) *kratos.App {
+// newLogger installs the configured logger as the default of pkg/log and kratos
+func newLogger(bc *conf.Config) log.Logger {
+	pkgLog.Init(bc.Log, Version, bc.Env)
+	logger := log.With(
+		pkgLog.Default(),
+		"requestId", middleware.RequestId(),
+		pkgLog.TraceIDKey, tracing.TraceID(),
+		pkgLog.SpanIDKey, tracing.SpanID(),
+	)
+	log.SetLogger(logger)
+	if b, err := protojson.Marshal(conf.Redact(bc)); err == nil {
+		log.Debugf("config: %s", b)
+	}
+	return logger
+}
+
+// newConfigWatcher lists the components that follow config reloads
+func newConfigWatcher(src config.Config, bc *conf.Config, state *server.MiddlewareState, db *data.DB, admin *server.AdminServer, logger log.Logger) *server.ConfigWatcher {
+	return server.NewConfigWatcher(src, bc, admin.LevelHandler(), logger, state, db)
+}
+
+func newApp(logger log.Logger, gs *grpc.Server, hs *http.Server, js *server.JobServer, cw *server.ConfigWatcher, as *server.AdminServer, ob *server.OutboxRelay, qs *server.QueueServer, h *server.Health) *kratos.App {
 	return kratos.New(
 		kratos.ID(id),
 		kratos.Name(Name),
 		kratos.Version(Version),
 		kratos.Metadata(map[string]string{}),
 		kratos.Logger(logger),
+		kratos.BeforeStop(h.BeforeStop),
 		kratos.Server(
 			gs,
 			hs,
+			js,
+			cw,
+			as,
+			ob,
+			qs,
 		),
 	)
 }
 
 func main() {
-	flag.Parse()
+	// without a command the binary serves, so `server -conf config.yaml` keeps working
+	cmd, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
 
-	c := config.New(
-		config.WithSource(
-			file.NewSource(flagconf),
-		),
-	)
-	defer c.Close()
+	var err error
+	switch cmd {
+	case "serve":
+		err = runServe(args)
+	case "migrate":
+		err = runMigrate(args)
+	case "config":
+		err = runConfig(args)
+	case "version":
+		runVersion()
+	case "seed":
+		err = runSeed(args)
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", cmd, err)
+		os.Exit(1)
+	}
+}
 
-	if err := c.Load(); err != nil {
-		panic(err)
+func runServe(args []string) error {
+	fs, cf := newFlagSet("serve")
+	if args = parseArgs(fs, args); len(args) > 0 {
+		return fmt.Errorf("unexpected args: %v", args)
 	}
 
-	var bc conf.Config
-	if err := c.Scan(&bc); err != nil {
-		panic(err)
+	c, bc, err := cf.load()
+	if err != nil {
+		return err
 	}
+	defer c.Close()
 
-	pkgLog.Init(bc.Log, Version, bc.Env)
-	logger := log.With(
-		pkgLog.Default(),
-		"requestId", middleware.RequestId(),
-	)
-	log.SetLogger(logger)
+	logger := newLogger(bc)
+	feature.Set(bc.Features)
+
+	shutdownTracer, err := tracer.Init(bc.Trace, Name, Version, bc.Env)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		if err := shutdownTracer(ctx); err != nil {
+			log.Errorf("shutdown tracer failed, err: %+v", err)
+		}
+	}()
 
-	app, cleanup, err := wireApp(&bc, logger)
+	app, cleanup, err := wireApp(bc, c, logger)
 	if err != nil {
-		panic(err)
+		return err
 	}
 	defer cleanup()
 
 	// start and wait for stop signal
-	if err := app.Run(); err != nil {
-		panic(err)
-	}
+	return app.Run()
 }
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantArgs []string
+		wantConf string
+	}{
+		{args: []string{"up"}, wantArgs: []string{"up"}, wantConf: "config.yaml"},
+		{args: []string{"down", "-conf", "a.yaml", "2"}, wantArgs: []string{"down", "2"}, wantConf: "a.yaml"},
+		{args: []string{"-conf", "a.yaml", "force", "-1"}, wantArgs: []string{"force", "-1"}, wantConf: "a.yaml"},
+		{args: []string{"force", "-1", "-conf", "a.yaml"}, wantArgs: []string{"force", "-1"}, wantConf: "a.yaml"},
+		{args: []string{"force", "--", "-1"}, wantArgs: []string{"force", "-1"}, wantConf: "config.yaml"},
+	}
+	for _, tt := range tests {
+		fs, cf := newFlagSet("migrate")
+		require.Equal(t, tt.wantArgs, parseArgs(fs, tt.args), tt.args)
+		require.Equal(t, tt.wantConf, cf.conf, tt.args)
+	}
+}
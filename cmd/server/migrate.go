@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"server-template/internal/data"
+
+	"github.com/pkg/errors"
+)
+
+const migrateUsage = "usage: server migrate up | down [n] | status | force <version> [-conf config.yaml]"
+
+// runMigrate runs the embedded migrations on the master db, without taking the auto_migrate lock
+func runMigrate(args []string) error {
+	fs, cf := newFlagSet("migrate")
+	args = parseArgs(fs, args)
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	c, bc, err := cf.load()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	m, err := data.NewMigrator(bc.Db.Master, newLogger(bc))
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		return m.Up()
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			if n, err = strconv.Atoi(args[1]); err != nil {
+				return errors.Wrapf(err, "invalid steps %q", args[1])
+			}
+		}
+		return m.Down(n)
+	case "force":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.Wrapf(err, "invalid version %q", args[1])
+		}
+		return m.Force(version)
+	case "status":
+		return printMigrateStatus(m)
+	}
+	return errors.New(migrateUsage)
+}
+
+func printMigrateStatus(m *data.Migrator) error {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	list, err := m.Status()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d, dirty: %v\n", version, dirty)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS")
+	for _, s := range list {
+		status := "pending"
+		if s.Applied {
+			status = "applied"
+		}
+		if dirty && s.Version == version {
+			status = "dirty"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Identifier, status)
+	}
+	return w.Flush()
+}
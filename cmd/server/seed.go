@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	pb "server-template/api/server"
+	"server-template/pkg/env"
+
+	"github.com/pkg/errors"
+)
+
+// seedUsers are the dev fixtures, seeding twice skips the existing ones
+var seedUsers = []struct {
+	name  string
+	email string
+}{
+	{"alice", "alice@example.com"},
+	{"bob", "bob@example.com"},
+	{"carol", "carol@example.com"},
+}
+
+// runSeed inserts the dev fixtures through the biz layer, the servers are not started
+func runSeed(args []string) error {
+	fs, cf := newFlagSet("seed")
+	if args = parseArgs(fs, args); len(args) > 0 {
+		return errors.Errorf("unexpected args: %v", args)
+	}
+
+	c, bc, err := cf.load()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if bc.Env == env.ModeProd || bc.Env == env.ModeEmpty {
+		return errors.Errorf("seed is only allowed in dev and test, env: %q", bc.Env)
+	}
+
+	user, cleanup, err := wireSeed(bc, newLogger(bc))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, u := range seedUsers {
+		id, err := user.CreateUser(ctx, u.name, u.email)
+		if pb.IsAlreadyExists(err) {
+			fmt.Printf("skip %s, already exists\n", u.email)
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "seed user %s failed", u.email)
+		}
+		fmt.Printf("created user %d %s\n", id, u.email)
+	}
+	return nil
+}
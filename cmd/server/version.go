@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// runVersion prints the ldflags name and version, and the vcs info stamped by go build
+func runVersion() {
+	name, version := Name, Version
+	info, ok := debug.ReadBuildInfo()
+	if name == "" && ok {
+		name = info.Main.Path
+	}
+	if version == "" && ok {
+		version = info.Main.Version
+	}
+	fmt.Printf("%s %s\n", name, version)
+	if !ok {
+		return
+	}
+	fmt.Printf("go: %s\n", info.GoVersion)
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision", "vcs.time", "vcs.modified", "GOOS", "GOARCH":
+			fmt.Printf("%s: %s\n", s.Key, s.Value)
+		}
+	}
+}
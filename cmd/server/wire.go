@@ -13,15 +13,24 @@ import (
 	"server-template/internal/service"
 
 	"github.com/go-kratos/kratos/v2"
+	"github.com/go-kratos/kratos/v2/config"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/google/wire"
 )
 
 // wireApp init kratos application.
-func wireApp(*conf.Config, log.Logger) (*kratos.App, func(), error) {
+func wireApp(*conf.Config, config.Config, log.Logger) (*kratos.App, func(), error) {
 	panic(wire.Build(
 		server.ProviderSet, data.ProviderSet, biz.ProviderSet, service.ProviderSet,
-		wire.FieldsOf(new(*conf.Config), "Server", "Redis", "Db"),
-		newApp,
+		wire.FieldsOf(new(*conf.Config), "Server", "Redis", "Db", "Job", "Outbox", "Queue"),
+		newConfigWatcher, newApp,
+	))
+}
+
+// wireSeed init the user biz without the servers, for the seed command.
+func wireSeed(*conf.Config, log.Logger) (*biz.UserBiz, func(), error) {
+	panic(wire.Build(
+		data.ProviderSet, biz.ProviderSet,
+		wire.FieldsOf(new(*conf.Config), "Server", "Redis", "Db", "Outbox", "Queue"),
 	))
 }
@@ -8,6 +8,7 @@ package main
 
 import (
 	"github.com/go-kratos/kratos/v2"
+	"github.com/go-kratos/kratos/v2/config"
 	"github.com/go-kratos/kratos/v2/log"
 	"server-template/internal/biz"
 	"server-template/internal/conf"
@@ -23,35 +24,101 @@ import (
 // Injectors from wire.go:
 
 // wireApp init kratos application.
-func wireApp(config *conf.Config, logger log.Logger) (*kratos.App, func(), error) {
-	confServer := config.Server
-	db := config.Db
-	dataDB, cleanup, err := data.NewDB(db, logger)
+func wireApp(confConfig *conf.Config, configConfig config.Config, logger log.Logger) (*kratos.App, func(), error) {
+	confServer := confConfig.Server
+	middlewareState, err := server.NewMiddlewareState(confServer, logger)
 	if err != nil {
 		return nil, nil, err
 	}
-	dataData, cleanup2, err := data.NewData(dataDB, logger)
+	redis := confConfig.Redis
+	universalClient, cleanup, err := data.NewRedis(redis)
+	if err != nil {
+		return nil, nil, err
+	}
+	middlewares, err := server.NewMiddlewares(confServer, middlewareState, universalClient, logger)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
 	}
+	db := confConfig.Db
+	dataDB, cleanup2, err := data.NewDB(db, universalClient, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	dataData, cleanup3, err := data.NewData(dataDB, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
 	transaction := data.NewTransaction(dataData)
+	fencer := data.NewFencer(dataData)
 	userRepo := data.NewUserRepo(dataData)
-	redis := config.Redis
-	universalClient, cleanup3, err := data.NewRedis(redis)
+	authorizer := biz.NewAuthorizer(confServer)
+	outbox := confConfig.Outbox
+	eventPublisher := data.NewEventPublisher(outbox, dataData)
+	queue := confConfig.Queue
+	enqueuer := data.NewJobQueue(queue, universalClient)
+	userBiz := biz.NewUserBiz(transaction, fencer, userRepo, authorizer, eventPublisher, enqueuer, universalClient, logger)
+	serverService := service.NewServerService(userBiz, logger, confConfig)
+	grpcServer := server.NewGRPCServer(confServer, middlewares, serverService)
+	v := data.NewHealthCheckers(dataDB, universalClient)
+	health := server.NewHealth(confServer, v, logger)
+	httpServer := server.NewHTTPServer(confServer, middlewares, health, serverService)
+	job := confConfig.Job
+	adminServer := server.NewAdminServer(confServer, logger)
+	jobServer, err := server.NewJobServer(job, userBiz, universalClient, adminServer, logger)
 	if err != nil {
+		cleanup3()
 		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	userBiz := biz.NewUserBiz(transaction, userRepo, universalClient, logger)
-	serverService := service.NewServerService(userBiz, logger, config)
-	grpcServer := server.NewGRPCServer(confServer, serverService, logger)
-	httpServer := server.NewHTTPServer(confServer, serverService, logger)
-	app := newApp(logger, grpcServer, httpServer)
+	configWatcher := newConfigWatcher(configConfig, confConfig, middlewareState, dataDB, adminServer, logger)
+	store := data.NewOutboxStore(dataData)
+	outboxRelay := server.NewOutboxRelay(outbox, store, universalClient, logger)
+	queueServer := server.NewQueueServer(queue, userBiz, universalClient, logger)
+	app := newApp(logger, grpcServer, httpServer, jobServer, configWatcher, adminServer, outboxRelay, queueServer, health)
 	return app, func() {
 		cleanup3()
 		cleanup2()
 		cleanup()
 	}, nil
 }
+
+// wireSeed init the user biz without the servers, for the seed command.
+func wireSeed(confConfig *conf.Config, logger log.Logger) (*biz.UserBiz, func(), error) {
+	db := confConfig.Db
+	redis := confConfig.Redis
+	universalClient, cleanup, err := data.NewRedis(redis)
+	if err != nil {
+		return nil, nil, err
+	}
+	dataDB, cleanup2, err := data.NewDB(db, universalClient, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	dataData, cleanup3, err := data.NewData(dataDB, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	transaction := data.NewTransaction(dataData)
+	fencer := data.NewFencer(dataData)
+	userRepo := data.NewUserRepo(dataData)
+	confServer := confConfig.Server
+	authorizer := biz.NewAuthorizer(confServer)
+	outbox := confConfig.Outbox
+	eventPublisher := data.NewEventPublisher(outbox, dataData)
+	queue := confConfig.Queue
+	enqueuer := data.NewJobQueue(queue, universalClient)
+	userBiz := biz.NewUserBiz(transaction, fencer, userRepo, authorizer, eventPublisher, enqueuer, universalClient, logger)
+	return userBiz, func() {
+		cleanup3()
+		cleanup2()
+		cleanup()
+	}, nil
+}
@@ -3,12 +3,44 @@ package biz
 import (
 	"context"
 
+	pb "server-template/api/server"
+	"server-template/internal/conf"
+	"server-template/pkg/policy"
+
 	"github.com/google/wire"
 )
 
 // ProviderSet is biz providers.
-var ProviderSet = wire.NewSet(NewUserBiz)
+var ProviderSet = wire.NewSet(NewUserBiz, NewAuthorizer)
+
+// ErrStaleFence rejects a write of a lock holder whose lock was taken over meanwhile
+var ErrStaleFence = pb.ErrorConflict("lock was taken over by a newer holder")
 
 type Transaction interface {
 	InTx(context.Context, func(ctx context.Context) error) error
 }
+
+// Fencer guards the writes made while holding a distributed lock, see lock.Lease
+type Fencer interface {
+	// Fence records token as the latest of lock, it fails with ErrStaleFence when a newer token already did.
+	// Call it first in the transaction it guards, the fence stays locked until the commit.
+	Fence(ctx context.Context, lock string, token int64) error
+}
+
+// Authorizer answers whether the caller in ctx may perform action on resource
+type Authorizer interface {
+	Can(ctx context.Context, action string, resource policy.Resource) bool
+}
+
+// NewAuthorizer registers the ownership rules, admin can do everything.
+// Requests without a caller are allowed only when authentication is disabled.
+func NewAuthorizer(c *conf.Server) Authorizer {
+	opts := []policy.Option{policy.WithSuperRoles(RoleAdmin)}
+	if !c.GetAuth().GetEnable() {
+		opts = append(opts, policy.WithAllowAnonymous())
+	}
+	e := policy.NewEngine(opts...)
+	e.Register(ResourceUser, ActionRead, policy.Owner)
+	e.Register(ResourceUser, ActionUpdate, policy.Owner)
+	return e
+}
@@ -1,2 +1,13 @@
 package biz
 
+const (
+	RoleAdmin = "admin"
+)
+
+// policy resources and actions
+const (
+	ResourceUser = "user"
+
+	ActionRead   = "read"
+	ActionUpdate = "update"
+)
@@ -0,0 +1,32 @@
+package biz
+
+import (
+	"context"
+)
+
+// event topics, consumers subscribe to them through the outbox sink
+const (
+	TopicUserCreated  = "user.created"
+	TopicUserUpdated  = "user.updated"
+	TopicUserDeleted  = "user.deleted"
+	TopicUserRestored = "user.restored"
+)
+
+// Event is a domain event, its payload is encoded as json
+type Event struct {
+	Topic       string
+	AggregateID string
+	Payload     any
+}
+
+// EventPublisher stores events in the outbox, call it inside the transaction of the change that raised
+// them so they are published if and only if the change commits
+type EventPublisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+// UserEvent is the payload of the user topics, personal data is left out, consumers read it from the api
+type UserEvent struct {
+	ID     int64    `json:"id"`
+	Fields []string `json:"fields,omitempty"` // updated fields of user.updated
+}
@@ -0,0 +1,13 @@
+package biz
+
+import (
+	"server-template/pkg/queue"
+)
+
+// background jobs, enqueued by biz and handled by the queue workers
+var JobSendWelcomeEmail = queue.NewJob[WelcomeEmail]("user.welcome_email")
+
+// WelcomeEmail is the payload of JobSendWelcomeEmail, the address is read when the job runs
+type WelcomeEmail struct {
+	UserID int64 `json:"user_id"`
+}
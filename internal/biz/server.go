@@ -2,37 +2,95 @@ package biz
 
 import (
 	"context"
+	"strconv"
+	"time"
 
+	pb "server-template/api/server"
+	"server-template/pkg"
+	lock "server-template/pkg/lock"
+	"server-template/pkg/policy"
+	"server-template/pkg/queue"
+
+	kerrors "github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-redis/redis/v8"
 	"github.com/pkg/errors"
 )
 
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var (
+	ErrUserNotFound      = pb.ErrorUserNotFound("user not found")
+	ErrUserAlreadyExists = pb.ErrorUserAlreadyExists("user already exists")
+	ErrForbidden         = pb.ErrorForbidden("permission denied")
+	ErrInvalidPageToken  = kerrors.BadRequest("PAGE_TOKEN", "invalid page token")
+	ErrInvalidMaskPath   = kerrors.BadRequest("UPDATE_MASK", "invalid update mask path")
+)
+
+type User struct {
+	ID        int64
+	Name      string
+	Country   string
+	Email     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 type UserRepo interface {
 	CreateUser(ctx context.Context, name string) (int64, error)
-	UpdateUserInfo(ctx context.Context, name, country string) (int64, error)
+	UpdateUserInfo(ctx context.Context, id int64, name, country string) (int64, error)
 	CreateUserDetail(ctx context.Context, id int64, email string) (int64, error)
+	UpdateUserEmail(ctx context.Context, id int64, email string) (int64, error)
+	GetUser(ctx context.Context, id int64) (*User, error)
+	// GetUserForUpdate reads the user from master and locks the row, it must be called in a transaction
+	GetUserForUpdate(ctx context.Context, id int64) (*User, error)
+	// ListUsers returns at most limit users whose id is greater than afterID, ordered by id
+	ListUsers(ctx context.Context, afterID int64, limit int32) ([]*User, error)
+	SoftDeleteUser(ctx context.Context, id int64) (int64, error)
+	RestoreUser(ctx context.Context, id int64) (int64, error)
+	// PurgeUsers hard deletes at most limit users soft deleted before the given time
+	PurgeUsers(ctx context.Context, before time.Time, limit int32) (int64, error)
 }
 
 type UserBiz struct {
-	tx   Transaction
-	repo UserRepo
-	Rdb  redis.UniversalClient
-	log  *log.Helper
+	tx     Transaction
+	fencer Fencer
+	repo   UserRepo
+	authz  Authorizer
+	events EventPublisher
+	jobs   queue.Enqueuer
+	Rdb    redis.UniversalClient
+	log    *log.Helper
 }
 
 func NewUserBiz(
-	tx Transaction, repo UserRepo,
+	tx Transaction, fencer Fencer, repo UserRepo, authz Authorizer, events EventPublisher, jobs queue.Enqueuer,
 	redisCli redis.UniversalClient, logger log.Logger,
 ) *UserBiz {
 	return &UserBiz{
-		tx:   tx,
-		repo: repo,
-		Rdb:  redisCli,
-		log:  log.NewHelper(log.With(logger, "module", "biz/user")),
+		tx:     tx,
+		fencer: fencer,
+		repo:   repo,
+		authz:  authz,
+		events: events,
+		jobs:   jobs,
+		Rdb:    redisCli,
+		log:    log.NewHelper(log.With(logger, "module", "biz/user")),
 	}
 }
 
+// publish stores a user event in the outbox, in the transaction of ctx
+func (u *UserBiz) publish(ctx context.Context, topic string, id int64, fields ...string) error {
+	return u.events.Publish(ctx, Event{
+		Topic:       topic,
+		AggregateID: strconv.FormatInt(id, 10),
+		Payload:     UserEvent{ID: id, Fields: fields},
+	})
+}
+
 func (u *UserBiz) CreateUser(ctx context.Context, name, email string) (int64, error) {
 	var (
 		id  int64
@@ -44,12 +102,201 @@ func (u *UserBiz) CreateUser(ctx context.Context, name, email string) (int64, er
 		if err != nil {
 			return err
 		}
-		_, err = u.repo.CreateUserDetail(ctx, id, email)
-		return err
+		if _, err = u.repo.CreateUserDetail(ctx, id, email); err != nil {
+			return err
+		}
+		return u.publish(ctx, TopicUserCreated, id)
 	})
 	if err != nil {
 		return 0, errors.Wrap(err, "user create fail")
 	}
 
+	// the user is created even if the email can't be queued
+	if _, err = JobSendWelcomeEmail.Enqueue(ctx, u.jobs, WelcomeEmail{UserID: id}); err != nil {
+		u.log.WithContext(ctx).Warnf("enqueue welcome email of user %d failed, err: %+v", id, err)
+	}
+
 	return id, nil
 }
+
+// SendWelcomeEmail handles JobSendWelcomeEmail, plug the mail provider in here
+func (u *UserBiz) SendWelcomeEmail(ctx context.Context, job WelcomeEmail) error {
+	// not found is retried too, the replica may lag behind the insert
+	user, err := u.repo.GetUser(ctx, job.UserID)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		u.log.WithContext(ctx).Infof("user %d has no email, skip welcome email", user.ID)
+		return nil
+	}
+	u.log.WithContext(ctx).Infof("welcome email sent to user %d", user.ID)
+	return nil
+}
+
+func (u *UserBiz) GetUser(ctx context.Context, id int64) (*User, error) {
+	userID := strconv.FormatInt(id, 10)
+	if !u.authz.Can(ctx, ActionRead, policy.Resource{Type: ResourceUser, ID: userID, OwnerID: userID}) {
+		return nil, ErrForbidden
+	}
+	return u.repo.GetUser(ctx, id)
+}
+
+// ListUsers pages through users by id, nextPageToken is empty on the last page
+func (u *UserBiz) ListUsers(ctx context.Context, pageToken string, pageSize int32) (users []*User, nextPageToken string, err error) {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	var afterID int64
+	if pageToken != "" {
+		afterID, err = pkg.DecodePageToken(pageToken)
+		if err != nil {
+			return nil, "", ErrInvalidPageToken.WithCause(err)
+		}
+	}
+
+	// fetch one more row to know whether there is a next page
+	users, err = u.repo.ListUsers(ctx, afterID, pageSize+1)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "list users fail")
+	}
+	if len(users) > int(pageSize) {
+		users = users[:pageSize]
+		nextPageToken = pkg.EncodePageToken(users[len(users)-1].ID)
+	}
+
+	return users, nextPageToken, nil
+}
+
+// UpdateUser updates the fields of user listed in paths, supported paths are name, country and email
+func (u *UserBiz) UpdateUser(ctx context.Context, id int64, user *User, paths []string) (*User, error) {
+	userID := strconv.FormatInt(id, 10)
+	if !u.authz.Can(ctx, ActionUpdate, policy.Resource{Type: ResourceUser, ID: userID, OwnerID: userID}) {
+		return nil, ErrForbidden
+	}
+
+	var (
+		updated      *User
+		updateInfo   bool
+		updateDetail bool
+	)
+	for _, path := range paths {
+		switch path {
+		case "name", "country":
+			updateInfo = true
+		case "email":
+			updateDetail = true
+		default:
+			return nil, ErrInvalidMaskPath.WithMetadata(map[string]string{"path": path})
+		}
+	}
+
+	err := u.tx.InTx(ctx, func(ctx context.Context) error {
+		old, err := u.repo.GetUserForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			switch path {
+			case "name":
+				old.Name = user.Name
+			case "country":
+				old.Country = user.Country
+			case "email":
+				old.Email = user.Email
+			}
+		}
+
+		if updateInfo {
+			if _, err = u.repo.UpdateUserInfo(ctx, id, old.Name, old.Country); err != nil {
+				return err
+			}
+		}
+		if updateDetail {
+			rows, err := u.repo.UpdateUserEmail(ctx, id, old.Email)
+			if err != nil {
+				return err
+			}
+			if rows == 0 {
+				if _, err = u.repo.CreateUserDetail(ctx, id, old.Email); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err = u.publish(ctx, TopicUserUpdated, id, paths...); err != nil {
+			return err
+		}
+		updated, err = u.repo.GetUserForUpdate(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "user update fail")
+	}
+
+	return updated, nil
+}
+
+// DeleteUser soft deletes the user, it can be restored until purged.
+// The email is released at once, so it can be reused by a new user
+func (u *UserBiz) DeleteUser(ctx context.Context, id int64) error {
+	return u.tx.InTx(ctx, func(ctx context.Context) error {
+		rows, err := u.repo.SoftDeleteUser(ctx, id)
+		if err != nil {
+			return errors.Wrap(err, "user delete fail")
+		}
+		if rows == 0 {
+			return ErrUserNotFound
+		}
+		return u.publish(ctx, TopicUserDeleted, id)
+	})
+}
+
+// RestoreUser fails with ALREADY_EXISTS when the email has been taken by another user since the delete
+func (u *UserBiz) RestoreUser(ctx context.Context, id int64) error {
+	return u.tx.InTx(ctx, func(ctx context.Context) error {
+		rows, err := u.repo.RestoreUser(ctx, id)
+		if err != nil {
+			return errors.Wrap(err, "user restore fail")
+		}
+		if rows == 0 {
+			return ErrUserNotFound
+		}
+		return u.publish(ctx, TopicUserRestored, id)
+	})
+}
+
+// PurgeDeletedUsers hard deletes users soft deleted longer than retention, in batches of batchSize.
+// Called with the Context of a lock.Lease every batch is fenced by its token.
+func (u *UserBiz) PurgeDeletedUsers(ctx context.Context, retention time.Duration, batchSize int32) (int64, error) {
+	before := time.Now().Add(-retention)
+
+	var total int64
+	for {
+		var rows int64
+		err := u.tx.InTx(ctx, func(ctx context.Context) (err error) {
+			// a run that lost its lock stops before purging the same rows as the new holder
+			if lease, ok := lock.FromContext(ctx); ok {
+				if err := u.fencer.Fence(ctx, lease.Key(), lease.Token()); err != nil {
+					return err
+				}
+			}
+			rows, err = u.repo.PurgeUsers(ctx, before, batchSize)
+			return err
+		})
+		if err != nil {
+			return total, errors.Wrap(err, "purge deleted users fail")
+		}
+		total += rows
+		if rows < int64(batchSize) {
+			return total, nil
+		}
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package biz
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"server-template/internal/conf"
+	lock "server-template/pkg/lock"
+	"server-template/pkg/middleware/auth"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTx struct{}
+
+func (fakeTx) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// fakeFencer keeps the fence of every lock
+type fakeFencer map[string]int64
+
+func (f fakeFencer) Fence(ctx context.Context, lock string, token int64) error {
+	if token < f[lock] {
+		return ErrStaleFence
+	}
+	f[lock] = token
+	return nil
+}
+
+type fakeEvents struct {
+	events []Event
+}
+
+func (f *fakeEvents) Publish(ctx context.Context, events ...Event) error {
+	f.events = append(f.events, events...)
+	return nil
+}
+
+type fakeJobs struct {
+	jobs []string
+}
+
+func (f *fakeJobs) Enqueue(ctx context.Context, job string, payload []byte) (string, error) {
+	f.jobs = append(f.jobs, job)
+	return "1-0", nil
+}
+
+// fakeRepo keeps users in memory, a user without email has no detail row
+type fakeRepo struct {
+	users   map[int64]*User
+	deleted map[int64]bool
+	calls   []string
+	purged  []int64 // rows returned by each PurgeUsers call
+}
+
+func newFakeRepo(users ...*User) *fakeRepo {
+	r := &fakeRepo{users: map[int64]*User{}, deleted: map[int64]bool{}}
+	for _, u := range users {
+		r.users[u.ID] = u
+	}
+	return r
+}
+
+func (r *fakeRepo) CreateUser(ctx context.Context, name string) (int64, error) {
+	r.calls = append(r.calls, "CreateUser")
+	id := int64(len(r.users) + 1)
+	r.users[id] = &User{ID: id, Name: name}
+	return id, nil
+}
+
+func (r *fakeRepo) UpdateUserInfo(ctx context.Context, id int64, name, country string) (int64, error) {
+	r.calls = append(r.calls, "UpdateUserInfo")
+	r.users[id].Name, r.users[id].Country = name, country
+	return 1, nil
+}
+
+func (r *fakeRepo) CreateUserDetail(ctx context.Context, id int64, email string) (int64, error) {
+	r.calls = append(r.calls, "CreateUserDetail")
+	r.users[id].Email = email
+	return 1, nil
+}
+
+func (r *fakeRepo) UpdateUserEmail(ctx context.Context, id int64, email string) (int64, error) {
+	r.calls = append(r.calls, "UpdateUserEmail")
+	if r.users[id].Email == "" {
+		return 0, nil
+	}
+	r.users[id].Email = email
+	return 1, nil
+}
+
+func (r *fakeRepo) GetUser(ctx context.Context, id int64) (*User, error) {
+	u, ok := r.users[id]
+	if !ok || r.deleted[id] {
+		return nil, ErrUserNotFound
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (r *fakeRepo) GetUserForUpdate(ctx context.Context, id int64) (*User, error) {
+	return r.GetUser(ctx, id)
+}
+
+func (r *fakeRepo) ListUsers(ctx context.Context, afterID int64, limit int32) ([]*User, error) {
+	return nil, nil
+}
+
+func (r *fakeRepo) SoftDeleteUser(ctx context.Context, id int64) (int64, error) {
+	if _, ok := r.users[id]; !ok || r.deleted[id] {
+		return 0, nil
+	}
+	r.deleted[id] = true
+	return 1, nil
+}
+
+func (r *fakeRepo) RestoreUser(ctx context.Context, id int64) (int64, error) {
+	if !r.deleted[id] {
+		return 0, nil
+	}
+	delete(r.deleted, id)
+	return 1, nil
+}
+
+func (r *fakeRepo) PurgeUsers(ctx context.Context, before time.Time, limit int32) (int64, error) {
+	r.calls = append(r.calls, "PurgeUsers")
+	if len(r.purged) == 0 {
+		return 0, nil
+	}
+	rows := r.purged[0]
+	r.purged = r.purged[1:]
+	return rows, nil
+}
+
+func newTestBiz(repo UserRepo) (*UserBiz, *fakeEvents) {
+	events := &fakeEvents{}
+	return NewUserBiz(fakeTx{}, fakeFencer{}, repo, NewAuthorizer(&conf.Server{}), events, &fakeJobs{}, nil, log.DefaultLogger), events
+}
+
+func TestGetUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		claims  *auth.Claims
+		wantErr error
+	}{
+		{name: "owner", claims: &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}}},
+		{name: "admin", claims: &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "2"}, Roles: []string{RoleAdmin}}},
+		{name: "other user", claims: &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "2"}}, wantErr: ErrForbidden},
+		{name: "anonymous", wantErr: ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authz := NewAuthorizer(&conf.Server{Auth: &conf.Server_Auth{Enable: true}})
+			u := NewUserBiz(fakeTx{}, fakeFencer{}, newFakeRepo(&User{ID: 1, Name: "alice"}), authz, &fakeEvents{}, &fakeJobs{}, nil, log.DefaultLogger)
+			ctx := context.Background()
+			if tt.claims != nil {
+				ctx = auth.NewContext(ctx, tt.claims)
+			}
+
+			got, err := u.GetUser(ctx, 1)
+			if tt.wantErr != nil {
+				require.True(t, errors.Is(err, tt.wantErr), err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, int64(1), got.ID)
+		})
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	tests := []struct {
+		name      string
+		user      *User
+		paths     []string
+		wantErr   error
+		wantCalls []string
+		want      *User
+	}{
+		{
+			name:    "invalid path",
+			user:    &User{ID: 1, Name: "alice"},
+			paths:   []string{"name", "id"},
+			wantErr: ErrInvalidMaskPath,
+		},
+		{
+			name:      "name only",
+			user:      &User{ID: 1, Name: "alice", Email: "a@x.io"},
+			paths:     []string{"name"},
+			wantCalls: []string{"UpdateUserInfo"},
+			want:      &User{ID: 1, Name: "bob", Email: "a@x.io"},
+		},
+		{
+			name:      "email updates the detail row",
+			user:      &User{ID: 1, Name: "alice", Email: "a@x.io"},
+			paths:     []string{"email"},
+			wantCalls: []string{"UpdateUserEmail"},
+			want:      &User{ID: 1, Name: "alice", Email: "b@x.io"},
+		},
+		{
+			name:      "email creates the missing detail row",
+			user:      &User{ID: 1, Name: "alice"},
+			paths:     []string{"email"},
+			wantCalls: []string{"UpdateUserEmail", "CreateUserDetail"},
+			want:      &User{ID: 1, Name: "alice", Email: "b@x.io"},
+		},
+		{
+			name:    "not found",
+			user:    &User{ID: 2, Name: "alice"},
+			paths:   []string{"name"},
+			wantErr: ErrUserNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo(tt.user)
+			u, events := newTestBiz(repo)
+
+			got, err := u.UpdateUser(context.Background(), 1, &User{Name: "bob", Email: "b@x.io"}, tt.paths)
+			if tt.wantErr != nil {
+				require.True(t, errors.Is(err, tt.wantErr), err)
+				require.Empty(t, events.events)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCalls, repo.calls)
+			require.Equal(t, tt.want, got)
+			require.Len(t, events.events, 1)
+			require.Equal(t, TopicUserUpdated, events.events[0].Topic)
+			require.Equal(t, UserEvent{ID: 1, Fields: tt.paths}, events.events[0].Payload)
+		})
+	}
+}
+
+func TestDeleteRestoreUser(t *testing.T) {
+	repo := newFakeRepo(&User{ID: 1, Name: "alice"})
+	u, events := newTestBiz(repo)
+	ctx := context.Background()
+
+	require.True(t, errors.Is(u.RestoreUser(ctx, 1), ErrUserNotFound))
+	require.NoError(t, u.DeleteUser(ctx, 1))
+	require.True(t, errors.Is(u.DeleteUser(ctx, 1), ErrUserNotFound))
+	require.True(t, errors.Is(u.DeleteUser(ctx, 2), ErrUserNotFound))
+	require.NoError(t, u.RestoreUser(ctx, 1))
+	require.True(t, errors.Is(u.RestoreUser(ctx, 1), ErrUserNotFound))
+
+	require.Len(t, events.events, 2)
+	require.Equal(t, TopicUserDeleted, events.events[0].Topic)
+	require.Equal(t, TopicUserRestored, events.events[1].Topic)
+}
+
+func TestPurgeDeletedUsers(t *testing.T) {
+	tests := []struct {
+		name      string
+		purged    []int64
+		wantTotal int64
+		wantCalls int
+	}{
+		{name: "nothing", wantTotal: 0, wantCalls: 1},
+		{name: "partial batch", purged: []int64{3}, wantTotal: 3, wantCalls: 1},
+		{name: "full batches", purged: []int64{10, 10, 4}, wantTotal: 24, wantCalls: 3},
+		{name: "full batches then empty", purged: []int64{10, 10}, wantTotal: 20, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepo()
+			repo.purged = tt.purged
+			u, _ := newTestBiz(repo)
+
+			total, err := u.PurgeDeletedUsers(context.Background(), time.Hour, 10)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantTotal, total)
+			require.Len(t, repo.calls, tt.wantCalls)
+		})
+	}
+}
+
+func TestPurgeDeletedUsersFenced(t *testing.T) {
+	m := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	ctx := context.Background()
+
+	// the first holder stalls, its lock expires and another replica takes it over
+	stale, err := lock.Acquire(ctx, "lock:purge", time.Minute, rdb)
+	require.NoError(t, err)
+	defer stale.Release(ctx)
+	m.Del("lock:purge")
+	fresh, err := lock.Acquire(ctx, "lock:purge", time.Minute, rdb)
+	require.NoError(t, err)
+	defer fresh.Release(ctx)
+	require.Greater(t, fresh.Token(), stale.Token())
+
+	repo := newFakeRepo()
+	fencer := fakeFencer{}
+	u := NewUserBiz(fakeTx{}, fencer, repo, NewAuthorizer(&conf.Server{}), &fakeEvents{}, &fakeJobs{}, nil, log.DefaultLogger)
+
+	repo.purged = []int64{3}
+	total, err := u.PurgeDeletedUsers(fresh.Context(), time.Hour, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+	require.Equal(t, fresh.Token(), fencer["lock:purge"])
+
+	repo.purged = []int64{3}
+	_, err = u.PurgeDeletedUsers(stale.Context(), time.Hour, 10)
+	require.True(t, errors.Is(err, ErrStaleFence), err)
+	require.Equal(t, []string{"PurgeUsers"}, repo.calls)
+}
@@ -7,6 +7,7 @@
 package conf
 
 import (
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	durationpb "google.golang.org/protobuf/types/known/durationpb"
@@ -22,6 +23,202 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type Server_HTTP_ErrorMode int32
+
+const (
+	Server_HTTP_ENVELOPE    Server_HTTP_ErrorMode = 0 // always respond 200, the error code is in the json envelope
+	Server_HTTP_STATUS_CODE Server_HTTP_ErrorMode = 1 // respond with the error code as http status
+)
+
+// Enum value maps for Server_HTTP_ErrorMode.
+var (
+	Server_HTTP_ErrorMode_name = map[int32]string{
+		0: "ENVELOPE",
+		1: "STATUS_CODE",
+	}
+	Server_HTTP_ErrorMode_value = map[string]int32{
+		"ENVELOPE":    0,
+		"STATUS_CODE": 1,
+	}
+)
+
+func (x Server_HTTP_ErrorMode) Enum() *Server_HTTP_ErrorMode {
+	p := new(Server_HTTP_ErrorMode)
+	*p = x
+	return p
+}
+
+func (x Server_HTTP_ErrorMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Server_HTTP_ErrorMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_conf_proto_enumTypes[0].Descriptor()
+}
+
+func (Server_HTTP_ErrorMode) Type() protoreflect.EnumType {
+	return &file_conf_proto_enumTypes[0]
+}
+
+func (x Server_HTTP_ErrorMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Server_HTTP_ErrorMode.Descriptor instead.
+func (Server_HTTP_ErrorMode) EnumDescriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 0, 0}
+}
+
+type Server_RateLimit_Identity int32
+
+const (
+	Server_RateLimit_IP      Server_RateLimit_Identity = 0
+	Server_RateLimit_USER    Server_RateLimit_Identity = 1 // jwt subject, requests without a token are not counted
+	Server_RateLimit_API_KEY Server_RateLimit_Identity = 2 // value of api_key_header, requests without it are not counted
+)
+
+// Enum value maps for Server_RateLimit_Identity.
+var (
+	Server_RateLimit_Identity_name = map[int32]string{
+		0: "IP",
+		1: "USER",
+		2: "API_KEY",
+	}
+	Server_RateLimit_Identity_value = map[string]int32{
+		"IP":      0,
+		"USER":    1,
+		"API_KEY": 2,
+	}
+)
+
+func (x Server_RateLimit_Identity) Enum() *Server_RateLimit_Identity {
+	p := new(Server_RateLimit_Identity)
+	*p = x
+	return p
+}
+
+func (x Server_RateLimit_Identity) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Server_RateLimit_Identity) Descriptor() protoreflect.EnumDescriptor {
+	return file_conf_proto_enumTypes[1].Descriptor()
+}
+
+func (Server_RateLimit_Identity) Type() protoreflect.EnumType {
+	return &file_conf_proto_enumTypes[1]
+}
+
+func (x Server_RateLimit_Identity) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Server_RateLimit_Identity.Descriptor instead.
+func (Server_RateLimit_Identity) EnumDescriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 3, 0}
+}
+
+type Trace_Exporter int32
+
+const (
+	Trace_NONE      Trace_Exporter = 0 // spans are created for log correlation but not exported
+	Trace_OTLP_GRPC Trace_Exporter = 1
+	Trace_OTLP_HTTP Trace_Exporter = 2
+	Trace_STDOUT    Trace_Exporter = 3
+	Trace_FILE      Trace_Exporter = 4 // one json span per line, works offline
+)
+
+// Enum value maps for Trace_Exporter.
+var (
+	Trace_Exporter_name = map[int32]string{
+		0: "NONE",
+		1: "OTLP_GRPC",
+		2: "OTLP_HTTP",
+		3: "STDOUT",
+		4: "FILE",
+	}
+	Trace_Exporter_value = map[string]int32{
+		"NONE":      0,
+		"OTLP_GRPC": 1,
+		"OTLP_HTTP": 2,
+		"STDOUT":    3,
+		"FILE":      4,
+	}
+)
+
+func (x Trace_Exporter) Enum() *Trace_Exporter {
+	p := new(Trace_Exporter)
+	*p = x
+	return p
+}
+
+func (x Trace_Exporter) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Trace_Exporter) Descriptor() protoreflect.EnumDescriptor {
+	return file_conf_proto_enumTypes[2].Descriptor()
+}
+
+func (Trace_Exporter) Type() protoreflect.EnumType {
+	return &file_conf_proto_enumTypes[2]
+}
+
+func (x Trace_Exporter) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Trace_Exporter.Descriptor instead.
+func (Trace_Exporter) EnumDescriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{7, 0}
+}
+
+type Job_Misfire int32
+
+const (
+	Job_SKIP     Job_Misfire = 0 // runs missed while late or down are dropped, the job runs once for the latest of them
+	Job_CATCH_UP Job_Misfire = 1 // runs missed while late or down are run one after another, at most 10
+)
+
+// Enum value maps for Job_Misfire.
+var (
+	Job_Misfire_name = map[int32]string{
+		0: "SKIP",
+		1: "CATCH_UP",
+	}
+	Job_Misfire_value = map[string]int32{
+		"SKIP":     0,
+		"CATCH_UP": 1,
+	}
+)
+
+func (x Job_Misfire) Enum() *Job_Misfire {
+	p := new(Job_Misfire)
+	*p = x
+	return p
+}
+
+func (x Job_Misfire) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Job_Misfire) Descriptor() protoreflect.EnumDescriptor {
+	return file_conf_proto_enumTypes[3].Descriptor()
+}
+
+func (Job_Misfire) Type() protoreflect.EnumType {
+	return &file_conf_proto_enumTypes[3]
+}
+
+func (x Job_Misfire) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Job_Misfire.Descriptor instead.
+func (Job_Misfire) EnumDescriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{10, 0}
+}
+
 type Config struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Env           string                 `protobuf:"bytes,1,opt,name=env,proto3" json:"env,omitempty"`
@@ -29,6 +226,11 @@ type Config struct {
 	Db            *DB                    `protobuf:"bytes,3,opt,name=db,proto3" json:"db,omitempty"`
 	Redis         *Redis                 `protobuf:"bytes,4,opt,name=redis,proto3" json:"redis,omitempty"`
 	Log           *Log                   `protobuf:"bytes,5,opt,name=log,proto3" json:"log,omitempty"`
+	Job           *Job                   `protobuf:"bytes,6,opt,name=job,proto3" json:"job,omitempty"`
+	Trace         *Trace                 `protobuf:"bytes,7,opt,name=trace,proto3" json:"trace,omitempty"`
+	Features      map[string]bool        `protobuf:"bytes,8,rep,name=features,proto3" json:"features,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // feature flags, read with feature.Enabled
+	Outbox        *Outbox                `protobuf:"bytes,9,opt,name=outbox,proto3" json:"outbox,omitempty"`
+	Queue         *Queue                 `protobuf:"bytes,10,opt,name=queue,proto3" json:"queue,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -98,11 +300,52 @@ func (x *Config) GetLog() *Log {
 	return nil
 }
 
+func (x *Config) GetJob() *Job {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+func (x *Config) GetTrace() *Trace {
+	if x != nil {
+		return x.Trace
+	}
+	return nil
+}
+
+func (x *Config) GetFeatures() map[string]bool {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+func (x *Config) GetOutbox() *Outbox {
+	if x != nil {
+		return x.Outbox
+	}
+	return nil
+}
+
+func (x *Config) GetQueue() *Queue {
+	if x != nil {
+		return x.Queue
+	}
+	return nil
+}
+
 type Server struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Http          *Server_HTTP           `protobuf:"bytes,1,opt,name=http,proto3" json:"http,omitempty"`
 	Grpc          *Server_GRPC           `protobuf:"bytes,2,opt,name=grpc,proto3" json:"grpc,omitempty"`
 	UnLoggingOp   []string               `protobuf:"bytes,3,rep,name=un_logging_op,json=unLoggingOp,proto3" json:"un_logging_op,omitempty"` // 不需要记录日志的操作
+	Auth          *Server_Auth           `protobuf:"bytes,4,opt,name=auth,proto3" json:"auth,omitempty"`
+	RateLimit     *Server_RateLimit      `protobuf:"bytes,5,opt,name=rate_limit,json=rateLimit,proto3" json:"rate_limit,omitempty"`
+	Idempotency   *Server_Idempotency    `protobuf:"bytes,6,opt,name=idempotency,proto3" json:"idempotency,omitempty"`
+	Metrics       *Server_Metrics        `protobuf:"bytes,7,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	Health        *Server_Health         `protobuf:"bytes,8,opt,name=health,proto3" json:"health,omitempty"`
+	Admin         *Server_Admin          `protobuf:"bytes,9,opt,name=admin,proto3" json:"admin,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -158,6 +401,48 @@ func (x *Server) GetUnLoggingOp() []string {
 	return nil
 }
 
+func (x *Server) GetAuth() *Server_Auth {
+	if x != nil {
+		return x.Auth
+	}
+	return nil
+}
+
+func (x *Server) GetRateLimit() *Server_RateLimit {
+	if x != nil {
+		return x.RateLimit
+	}
+	return nil
+}
+
+func (x *Server) GetIdempotency() *Server_Idempotency {
+	if x != nil {
+		return x.Idempotency
+	}
+	return nil
+}
+
+func (x *Server) GetMetrics() *Server_Metrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *Server) GetHealth() *Server_Health {
+	if x != nil {
+		return x.Health
+	}
+	return nil
+}
+
+func (x *Server) GetAdmin() *Server_Admin {
+	if x != nil {
+		return x.Admin
+	}
+	return nil
+}
+
 type Redis struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Addrs         []string               `protobuf:"bytes,1,rep,name=addrs,proto3" json:"addrs,omitempty"`
@@ -246,6 +531,7 @@ type DB struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Master        *DbConfig              `protobuf:"bytes,1,opt,name=master,proto3" json:"master,omitempty"`
 	Slave         *DbConfig              `protobuf:"bytes,2,opt,name=slave,proto3" json:"slave,omitempty"`
+	AutoMigrate   bool                   `protobuf:"varint,3,opt,name=auto_migrate,json=autoMigrate,proto3" json:"auto_migrate,omitempty"` // apply pending migrations on the master at startup, one replica at a time
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -294,12 +580,19 @@ func (x *DB) GetSlave() *DbConfig {
 	return nil
 }
 
+func (x *DB) GetAutoMigrate() bool {
+	if x != nil {
+		return x.AutoMigrate
+	}
+	return false
+}
+
 type DbConfig struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	Dsn             string                 `protobuf:"bytes,1,opt,name=dsn,proto3" json:"dsn,omitempty"`
 	MaxOpenConn     int32                  `protobuf:"varint,2,opt,name=max_open_conn,json=maxOpenConn,proto3" json:"max_open_conn,omitempty"`
 	MaxIdleConn     int32                  `protobuf:"varint,3,opt,name=max_idle_conn,json=maxIdleConn,proto3" json:"max_idle_conn,omitempty"`
-	MaxLifetimeConn int32                  `protobuf:"varint,4,opt,name=max_lifetime_conn,json=maxLifetimeConn,proto3" json:"max_lifetime_conn,omitempty"`
+	MaxLifetimeConn int32                  `protobuf:"varint,4,opt,name=max_lifetime_conn,json=maxLifetimeConn,proto3" json:"max_lifetime_conn,omitempty"` // seconds, 0 keeps connections forever
 	Driver          string                 `protobuf:"bytes,5,opt,name=driver,proto3" json:"driver,omitempty"`
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
@@ -370,29 +663,33 @@ func (x *DbConfig) GetDriver() string {
 	return ""
 }
 
-type Log struct {
+// Outbox relays the events stored by biz.EventPublisher to redis streams, one replica at a time
+type Outbox struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AppName       string                 `protobuf:"bytes,1,opt,name=app_name,json=appName,proto3" json:"app_name,omitempty"`
-	IsWriteFile   bool                   `protobuf:"varint,2,opt,name=is_write_file,json=isWriteFile,proto3" json:"is_write_file,omitempty"`
-	LogFile       *LogFile               `protobuf:"bytes,3,opt,name=log_file,json=logFile,proto3" json:"log_file,omitempty"`
+	Enable        bool                   `protobuf:"varint,1,opt,name=enable,proto3" json:"enable,omitempty"`                                   // record user events and relay them, disabled drops them
+	PollInterval  *durationpb.Duration   `protobuf:"bytes,2,opt,name=poll_interval,json=pollInterval,proto3" json:"poll_interval,omitempty"`    // defaults to 1s
+	BatchSize     int32                  `protobuf:"varint,3,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`            // events per poll, defaults to 100
+	StreamPrefix  string                 `protobuf:"bytes,4,opt,name=stream_prefix,json=streamPrefix,proto3" json:"stream_prefix,omitempty"`    // events go to the stream prefix+topic, defaults to events:
+	StreamMaxLen  int64                  `protobuf:"varint,5,opt,name=stream_max_len,json=streamMaxLen,proto3" json:"stream_max_len,omitempty"` // approximate entries kept per stream, 0 keeps all of them
+	Retention     *durationpb.Duration   `protobuf:"bytes,6,opt,name=retention,proto3" json:"retention,omitempty"`                              // delivered events older than this are deleted, unset keeps them
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Log) Reset() {
-	*x = Log{}
+func (x *Outbox) Reset() {
+	*x = Outbox{}
 	mi := &file_conf_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Log) String() string {
+func (x *Outbox) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Log) ProtoMessage() {}
+func (*Outbox) ProtoMessage() {}
 
-func (x *Log) ProtoReflect() protoreflect.Message {
+func (x *Outbox) ProtoReflect() protoreflect.Message {
 	mi := &file_conf_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -404,55 +701,81 @@ func (x *Log) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Log.ProtoReflect.Descriptor instead.
-func (*Log) Descriptor() ([]byte, []int) {
+// Deprecated: Use Outbox.ProtoReflect.Descriptor instead.
+func (*Outbox) Descriptor() ([]byte, []int) {
 	return file_conf_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *Log) GetAppName() string {
+func (x *Outbox) GetEnable() bool {
 	if x != nil {
-		return x.AppName
+		return x.Enable
+	}
+	return false
+}
+
+func (x *Outbox) GetPollInterval() *durationpb.Duration {
+	if x != nil {
+		return x.PollInterval
+	}
+	return nil
+}
+
+func (x *Outbox) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *Outbox) GetStreamPrefix() string {
+	if x != nil {
+		return x.StreamPrefix
 	}
 	return ""
 }
 
-func (x *Log) GetIsWriteFile() bool {
+func (x *Outbox) GetStreamMaxLen() int64 {
 	if x != nil {
-		return x.IsWriteFile
+		return x.StreamMaxLen
 	}
-	return false
+	return 0
 }
 
-func (x *Log) GetLogFile() *LogFile {
+func (x *Outbox) GetRetention() *durationpb.Duration {
 	if x != nil {
-		return x.LogFile
+		return x.Retention
 	}
 	return nil
 }
 
-type LogFile struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	MaxSize       int32                  `protobuf:"varint,2,opt,name=max_size,json=maxSize,proto3" json:"max_size,omitempty"` // MB
-	MaxAge        int32                  `protobuf:"varint,3,opt,name=max_age,json=maxAge,proto3" json:"max_age,omitempty"`    // day
+type Queue struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Enable       bool                   `protobuf:"varint,1,opt,name=enable,proto3" json:"enable,omitempty"`                                // run the workers on this replica, jobs are enqueued either way
+	StreamPrefix string                 `protobuf:"bytes,2,opt,name=stream_prefix,json=streamPrefix,proto3" json:"stream_prefix,omitempty"` // jobs go to the stream prefix+{job}, defaults to queue:
+	Group        string                 `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`                                   // consumer group of the workers, defaults to workers
+	Concurrency  int32                  `protobuf:"varint,4,opt,name=concurrency,proto3" json:"concurrency,omitempty"`                      // jobs handled at the same time, defaults to 10
+	// jobs pending longer are reclaimed from crashed workers, defaults to 10m, keep it above the longest job with its retries
+	ClaimIdle     *durationpb.Duration `protobuf:"bytes,5,opt,name=claim_idle,json=claimIdle,proto3" json:"claim_idle,omitempty"`
+	MaxDeliveries int64                `protobuf:"varint,6,opt,name=max_deliveries,json=maxDeliveries,proto3" json:"max_deliveries,omitempty"` // reclaimed jobs delivered more often go to the dead letter stream, defaults to 5
+	DeadMaxLen    int64                `protobuf:"varint,7,opt,name=dead_max_len,json=deadMaxLen,proto3" json:"dead_max_len,omitempty"`        // approximate entries kept per dead letter stream, 0 keeps all of them
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *LogFile) Reset() {
-	*x = LogFile{}
+func (x *Queue) Reset() {
+	*x = Queue{}
 	mi := &file_conf_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *LogFile) String() string {
+func (x *Queue) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*LogFile) ProtoMessage() {}
+func (*Queue) ProtoMessage() {}
 
-func (x *LogFile) ProtoReflect() protoreflect.Message {
+func (x *Queue) ProtoReflect() protoreflect.Message {
 	mi := &file_conf_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -464,55 +787,86 @@ func (x *LogFile) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use LogFile.ProtoReflect.Descriptor instead.
-func (*LogFile) Descriptor() ([]byte, []int) {
+// Deprecated: Use Queue.ProtoReflect.Descriptor instead.
+func (*Queue) Descriptor() ([]byte, []int) {
 	return file_conf_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *LogFile) GetName() string {
+func (x *Queue) GetEnable() bool {
 	if x != nil {
-		return x.Name
+		return x.Enable
+	}
+	return false
+}
+
+func (x *Queue) GetStreamPrefix() string {
+	if x != nil {
+		return x.StreamPrefix
 	}
 	return ""
 }
 
-func (x *LogFile) GetMaxSize() int32 {
+func (x *Queue) GetGroup() string {
 	if x != nil {
-		return x.MaxSize
+		return x.Group
+	}
+	return ""
+}
+
+func (x *Queue) GetConcurrency() int32 {
+	if x != nil {
+		return x.Concurrency
 	}
 	return 0
 }
 
-func (x *LogFile) GetMaxAge() int32 {
+func (x *Queue) GetClaimIdle() *durationpb.Duration {
 	if x != nil {
-		return x.MaxAge
+		return x.ClaimIdle
+	}
+	return nil
+}
+
+func (x *Queue) GetMaxDeliveries() int64 {
+	if x != nil {
+		return x.MaxDeliveries
 	}
 	return 0
 }
 
-type Server_HTTP struct {
+func (x *Queue) GetDeadMaxLen() int64 {
+	if x != nil {
+		return x.DeadMaxLen
+	}
+	return 0
+}
+
+type Trace struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Network       string                 `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
-	Addr          string                 `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
-	Timeout       *durationpb.Duration   `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Exporter      Trace_Exporter         `protobuf:"varint,1,opt,name=exporter,proto3,enum=kratos.api.Trace_Exporter" json:"exporter,omitempty"`
+	Endpoint      string                 `protobuf:"bytes,2,opt,name=endpoint,proto3" json:"endpoint,omitempty"`                                                                         // otlp collector, eg: localhost:4317
+	Insecure      bool                   `protobuf:"varint,3,opt,name=insecure,proto3" json:"insecure,omitempty"`                                                                        // otlp without tls
+	Headers       map[string]string      `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // otlp headers, eg: authentication
+	File          string                 `protobuf:"bytes,5,opt,name=file,proto3" json:"file,omitempty"`                                                                                 // path of the FILE exporter
+	SampleRatio   *float64               `protobuf:"fixed64,6,opt,name=sample_ratio,json=sampleRatio,proto3,oneof" json:"sample_ratio,omitempty"`                                        // ratio of new traces to sample, defaults to 1, upstream decisions are kept
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Server_HTTP) Reset() {
-	*x = Server_HTTP{}
+func (x *Trace) Reset() {
+	*x = Trace{}
 	mi := &file_conf_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Server_HTTP) String() string {
+func (x *Trace) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Server_HTTP) ProtoMessage() {}
+func (*Trace) ProtoMessage() {}
 
-func (x *Server_HTTP) ProtoReflect() protoreflect.Message {
+func (x *Trace) ProtoReflect() protoreflect.Message {
 	mi := &file_conf_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -524,56 +878,697 @@ func (x *Server_HTTP) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Server_HTTP.ProtoReflect.Descriptor instead.
-func (*Server_HTTP) Descriptor() ([]byte, []int) {
-	return file_conf_proto_rawDescGZIP(), []int{1, 0}
+// Deprecated: Use Trace.ProtoReflect.Descriptor instead.
+func (*Trace) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *Server_HTTP) GetNetwork() string {
+func (x *Trace) GetExporter() Trace_Exporter {
 	if x != nil {
-		return x.Network
+		return x.Exporter
 	}
-	return ""
+	return Trace_NONE
 }
 
-func (x *Server_HTTP) GetAddr() string {
+func (x *Trace) GetEndpoint() string {
 	if x != nil {
-		return x.Addr
+		return x.Endpoint
 	}
 	return ""
 }
 
-func (x *Server_HTTP) GetTimeout() *durationpb.Duration {
+func (x *Trace) GetInsecure() bool {
 	if x != nil {
-		return x.Timeout
+		return x.Insecure
+	}
+	return false
+}
+
+func (x *Trace) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
 	}
 	return nil
 }
 
-type Server_GRPC struct {
+func (x *Trace) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *Trace) GetSampleRatio() float64 {
+	if x != nil && x.SampleRatio != nil {
+		return *x.SampleRatio
+	}
+	return 0
+}
+
+type Log struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Network       string                 `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
-	Addr          string                 `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
-	Timeout       *durationpb.Duration   `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	AppName       string                 `protobuf:"bytes,1,opt,name=app_name,json=appName,proto3" json:"app_name,omitempty"`
+	IsWriteFile   bool                   `protobuf:"varint,2,opt,name=is_write_file,json=isWriteFile,proto3" json:"is_write_file,omitempty"`
+	LogFile       *LogFile               `protobuf:"bytes,3,opt,name=log_file,json=logFile,proto3" json:"log_file,omitempty"`
+	Level         string                 `protobuf:"bytes,4,opt,name=level,proto3" json:"level,omitempty"`                                                                               // debug, info, warn or error, defaults to debug in dev and test, info otherwise
+	Encoding      string                 `protobuf:"bytes,5,opt,name=encoding,proto3" json:"encoding,omitempty"`                                                                         // json, console or logfmt, defaults to console in dev and test, json otherwise
+	Modules       map[string]string      `protobuf:"bytes,6,rep,name=modules,proto3" json:"modules,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // level per module field, eg: data: warn
+	Sampling      *Log_Sampling          `protobuf:"bytes,7,opt,name=sampling,proto3" json:"sampling,omitempty"`                                                                         // unset logs every entry
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Server_GRPC) Reset() {
-	*x = Server_GRPC{}
+func (x *Log) Reset() {
+	*x = Log{}
+	mi := &file_conf_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Log) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Log) ProtoMessage() {}
+
+func (x *Log) ProtoReflect() protoreflect.Message {
 	mi := &file_conf_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Log.ProtoReflect.Descriptor instead.
+func (*Log) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Log) GetAppName() string {
+	if x != nil {
+		return x.AppName
+	}
+	return ""
+}
+
+func (x *Log) GetIsWriteFile() bool {
+	if x != nil {
+		return x.IsWriteFile
+	}
+	return false
+}
+
+func (x *Log) GetLogFile() *LogFile {
+	if x != nil {
+		return x.LogFile
+	}
+	return nil
+}
+
+func (x *Log) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *Log) GetEncoding() string {
+	if x != nil {
+		return x.Encoding
+	}
+	return ""
+}
+
+func (x *Log) GetModules() map[string]string {
+	if x != nil {
+		return x.Modules
+	}
+	return nil
+}
+
+func (x *Log) GetSampling() *Log_Sampling {
+	if x != nil {
+		return x.Sampling
+	}
+	return nil
+}
+
+type LogFile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	MaxSize       int32                  `protobuf:"varint,2,opt,name=max_size,json=maxSize,proto3" json:"max_size,omitempty"`          // MB
+	MaxAge        int32                  `protobuf:"varint,3,opt,name=max_age,json=maxAge,proto3" json:"max_age,omitempty"`             // day
+	MaxBackups    int32                  `protobuf:"varint,4,opt,name=max_backups,json=maxBackups,proto3" json:"max_backups,omitempty"` // rotated files kept, 0 keeps all of them
+	Compress      bool                   `protobuf:"varint,5,opt,name=compress,proto3" json:"compress,omitempty"`                       // gzip rotated files
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogFile) Reset() {
+	*x = LogFile{}
+	mi := &file_conf_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogFile) ProtoMessage() {}
+
+func (x *LogFile) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogFile.ProtoReflect.Descriptor instead.
+func (*LogFile) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LogFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LogFile) GetMaxSize() int32 {
+	if x != nil {
+		return x.MaxSize
+	}
+	return 0
+}
+
+func (x *LogFile) GetMaxAge() int32 {
+	if x != nil {
+		return x.MaxAge
+	}
+	return 0
+}
+
+func (x *LogFile) GetMaxBackups() int32 {
+	if x != nil {
+		return x.MaxBackups
+	}
+	return 0
+}
+
+func (x *LogFile) GetCompress() bool {
+	if x != nil {
+		return x.Compress
+	}
+	return false
+}
+
+type Job struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	PurgeDeletedUser *Job_PurgeDeletedUser  `protobuf:"bytes,1,opt,name=purge_deleted_user,json=purgeDeletedUser,proto3" json:"purge_deleted_user,omitempty"`
+	HistorySize      int64                  `protobuf:"varint,2,opt,name=history_size,json=historySize,proto3" json:"history_size,omitempty"` // runs kept per job for the admin endpoint /cron, defaults to 20
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_conf_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Job) GetPurgeDeletedUser() *Job_PurgeDeletedUser {
+	if x != nil {
+		return x.PurgeDeletedUser
+	}
+	return nil
+}
+
+func (x *Job) GetHistorySize() int64 {
+	if x != nil {
+		return x.HistorySize
+	}
+	return 0
+}
+
+type Server_HTTP struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Network       string                 `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
+	Addr          string                 `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	Timeout       *durationpb.Duration   `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	ErrorMode     Server_HTTP_ErrorMode  `protobuf:"varint,4,opt,name=error_mode,json=errorMode,proto3,enum=kratos.api.Server_HTTP_ErrorMode" json:"error_mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_HTTP) Reset() {
+	*x = Server_HTTP{}
+	mi := &file_conf_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_HTTP) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_HTTP) ProtoMessage() {}
+
+func (x *Server_HTTP) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_HTTP.ProtoReflect.Descriptor instead.
+func (*Server_HTTP) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 0}
+}
+
+func (x *Server_HTTP) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *Server_HTTP) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *Server_HTTP) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Server_HTTP) GetErrorMode() Server_HTTP_ErrorMode {
+	if x != nil {
+		return x.ErrorMode
+	}
+	return Server_HTTP_ENVELOPE
+}
+
+type Server_GRPC struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Network       string                 `protobuf:"bytes,1,opt,name=network,proto3" json:"network,omitempty"`
+	Addr          string                 `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	Timeout       *durationpb.Duration   `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_GRPC) Reset() {
+	*x = Server_GRPC{}
+	mi := &file_conf_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_GRPC) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_GRPC) ProtoMessage() {}
+
+func (x *Server_GRPC) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_GRPC.ProtoReflect.Descriptor instead.
+func (*Server_GRPC) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 1}
+}
+
+func (x *Server_GRPC) GetNetwork() string {
+	if x != nil {
+		return x.Network
+	}
+	return ""
+}
+
+func (x *Server_GRPC) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *Server_GRPC) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+type Server_Auth struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Enable              bool                   `protobuf:"varint,1,opt,name=enable,proto3" json:"enable,omitempty"`
+	PublicOp            []string               `protobuf:"bytes,2,rep,name=public_op,json=publicOp,proto3" json:"public_op,omitempty"`       // operations that don't need a token, eg: /api.server.Server/ping
+	HmacSecret          string                 `protobuf:"bytes,3,opt,name=hmac_secret,json=hmacSecret,proto3" json:"hmac_secret,omitempty"` // HS256
+	JwksFile            string                 `protobuf:"bytes,4,opt,name=jwks_file,json=jwksFile,proto3" json:"jwks_file,omitempty"`       // RS256/ES256 keys from a local JWKS file
+	JwksUrl             string                 `protobuf:"bytes,5,opt,name=jwks_url,json=jwksUrl,proto3" json:"jwks_url,omitempty"`          // RS256/ES256 keys from a JWKS url
+	JwksRefreshInterval *durationpb.Duration   `protobuf:"bytes,6,opt,name=jwks_refresh_interval,json=jwksRefreshInterval,proto3" json:"jwks_refresh_interval,omitempty"`
+	Issuer              string                 `protobuf:"bytes,7,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	Audience            []string               `protobuf:"bytes,8,rep,name=audience,proto3" json:"audience,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Server_Auth) Reset() {
+	*x = Server_Auth{}
+	mi := &file_conf_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_Auth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_Auth) ProtoMessage() {}
+
+func (x *Server_Auth) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_Auth.ProtoReflect.Descriptor instead.
+func (*Server_Auth) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 2}
+}
+
+func (x *Server_Auth) GetEnable() bool {
+	if x != nil {
+		return x.Enable
+	}
+	return false
+}
+
+func (x *Server_Auth) GetPublicOp() []string {
+	if x != nil {
+		return x.PublicOp
+	}
+	return nil
+}
+
+func (x *Server_Auth) GetHmacSecret() string {
+	if x != nil {
+		return x.HmacSecret
+	}
+	return ""
+}
+
+func (x *Server_Auth) GetJwksFile() string {
+	if x != nil {
+		return x.JwksFile
+	}
+	return ""
+}
+
+func (x *Server_Auth) GetJwksUrl() string {
+	if x != nil {
+		return x.JwksUrl
+	}
+	return ""
+}
+
+func (x *Server_Auth) GetJwksRefreshInterval() *durationpb.Duration {
+	if x != nil {
+		return x.JwksRefreshInterval
+	}
+	return nil
+}
+
+func (x *Server_Auth) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *Server_Auth) GetAudience() []string {
+	if x != nil {
+		return x.Audience
+	}
+	return nil
+}
+
+type Server_RateLimit struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Enable        bool                     `protobuf:"varint,1,opt,name=enable,proto3" json:"enable,omitempty"`
+	ApiKeyHeader  string                   `protobuf:"bytes,2,opt,name=api_key_header,json=apiKeyHeader,proto3" json:"api_key_header,omitempty"` // defaults to X-API-Key
+	TrustProxy    bool                     `protobuf:"varint,3,opt,name=trust_proxy,json=trustProxy,proto3" json:"trust_proxy,omitempty"`        // take the client ip from the last X-Forwarded-For entry or X-Real-IP
+	Rules         []*Server_RateLimit_Rule `protobuf:"bytes,4,rep,name=rules,proto3" json:"rules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_RateLimit) Reset() {
+	*x = Server_RateLimit{}
+	mi := &file_conf_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_RateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_RateLimit) ProtoMessage() {}
+
+func (x *Server_RateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_RateLimit.ProtoReflect.Descriptor instead.
+func (*Server_RateLimit) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 3}
+}
+
+func (x *Server_RateLimit) GetEnable() bool {
+	if x != nil {
+		return x.Enable
+	}
+	return false
+}
+
+func (x *Server_RateLimit) GetApiKeyHeader() string {
+	if x != nil {
+		return x.ApiKeyHeader
+	}
+	return ""
+}
+
+func (x *Server_RateLimit) GetTrustProxy() bool {
+	if x != nil {
+		return x.TrustProxy
+	}
+	return false
+}
+
+func (x *Server_RateLimit) GetRules() []*Server_RateLimit_Rule {
+	if x != nil {
+		return x.Rules
+	}
+	return nil
+}
+
+type Server_Idempotency struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enable        bool                   `protobuf:"varint,1,opt,name=enable,proto3" json:"enable,omitempty"`
+	Ttl           *durationpb.Duration   `protobuf:"bytes,2,opt,name=ttl,proto3" json:"ttl,omitempty"` // how long replies are kept for replays, defaults to 24h
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_Idempotency) Reset() {
+	*x = Server_Idempotency{}
+	mi := &file_conf_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_Idempotency) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_Idempotency) ProtoMessage() {}
+
+func (x *Server_Idempotency) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_Idempotency.ProtoReflect.Descriptor instead.
+func (*Server_Idempotency) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 4}
+}
+
+func (x *Server_Idempotency) GetEnable() bool {
+	if x != nil {
+		return x.Enable
+	}
+	return false
+}
+
+func (x *Server_Idempotency) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+type Server_Metrics struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enable        bool                   `protobuf:"varint,1,opt,name=enable,proto3" json:"enable,omitempty"`
+	Path          string                 `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"` // prometheus endpoint on the http server, defaults to /metrics
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_Metrics) Reset() {
+	*x = Server_Metrics{}
+	mi := &file_conf_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_Metrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_Metrics) ProtoMessage() {}
+
+func (x *Server_Metrics) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_Metrics.ProtoReflect.Descriptor instead.
+func (*Server_Metrics) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 5}
+}
+
+func (x *Server_Metrics) GetEnable() bool {
+	if x != nil {
+		return x.Enable
+	}
+	return false
+}
+
+func (x *Server_Metrics) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type Server_Health struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Timeout       *durationpb.Duration   `protobuf:"bytes,1,opt,name=timeout,proto3" json:"timeout,omitempty"`                                  // per dependency check, defaults to 1s
+	CacheTtl      *durationpb.Duration   `protobuf:"bytes,2,opt,name=cache_ttl,json=cacheTtl,proto3" json:"cache_ttl,omitempty"`                // reuse the last readiness report, defaults to 2s
+	ShutdownDelay *durationpb.Duration   `protobuf:"bytes,3,opt,name=shutdown_delay,json=shutdownDelay,proto3" json:"shutdown_delay,omitempty"` // report not ready this long before the listeners stop
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_Health) Reset() {
+	*x = Server_Health{}
+	mi := &file_conf_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Server_GRPC) String() string {
+func (x *Server_Health) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Server_GRPC) ProtoMessage() {}
+func (*Server_Health) ProtoMessage() {}
 
-func (x *Server_GRPC) ProtoReflect() protoreflect.Message {
-	mi := &file_conf_proto_msgTypes[8]
+func (x *Server_Health) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -584,82 +1579,540 @@ func (x *Server_GRPC) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Server_GRPC.ProtoReflect.Descriptor instead.
-func (*Server_GRPC) Descriptor() ([]byte, []int) {
-	return file_conf_proto_rawDescGZIP(), []int{1, 1}
+// Deprecated: Use Server_Health.ProtoReflect.Descriptor instead.
+func (*Server_Health) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 6}
 }
 
-func (x *Server_GRPC) GetNetwork() string {
+func (x *Server_Health) GetTimeout() *durationpb.Duration {
 	if x != nil {
-		return x.Network
+		return x.Timeout
 	}
-	return ""
+	return nil
 }
 
-func (x *Server_GRPC) GetAddr() string {
+func (x *Server_Health) GetCacheTtl() *durationpb.Duration {
+	if x != nil {
+		return x.CacheTtl
+	}
+	return nil
+}
+
+func (x *Server_Health) GetShutdownDelay() *durationpb.Duration {
+	if x != nil {
+		return x.ShutdownDelay
+	}
+	return nil
+}
+
+type Server_Admin struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Addr          string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`   // separate listener for operational endpoints, unset disables it, eg: 127.0.0.1:8001
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"` // bearer token of every admin request
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_Admin) Reset() {
+	*x = Server_Admin{}
+	mi := &file_conf_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_Admin) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_Admin) ProtoMessage() {}
+
+func (x *Server_Admin) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_Admin.ProtoReflect.Descriptor instead.
+func (*Server_Admin) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 7}
+}
+
+func (x *Server_Admin) GetAddr() string {
 	if x != nil {
 		return x.Addr
 	}
 	return ""
 }
 
-func (x *Server_GRPC) GetTimeout() *durationpb.Duration {
+func (x *Server_Admin) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type Server_RateLimit_Rule struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Operation     string                    `protobuf:"bytes,1,opt,name=operation,proto3" json:"operation,omitempty"` // eg: /api.server.Server/CreateUser, "*" matches every operation
+	Identity      Server_RateLimit_Identity `protobuf:"varint,2,opt,name=identity,proto3,enum=kratos.api.Server_RateLimit_Identity" json:"identity,omitempty"`
+	Limit         int32                     `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"` // requests per period
+	Period        *durationpb.Duration      `protobuf:"bytes,4,opt,name=period,proto3" json:"period,omitempty"`
+	Burst         int32                     `protobuf:"varint,5,opt,name=burst,proto3" json:"burst,omitempty"` // defaults to limit
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Server_RateLimit_Rule) Reset() {
+	*x = Server_RateLimit_Rule{}
+	mi := &file_conf_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Server_RateLimit_Rule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Server_RateLimit_Rule) ProtoMessage() {}
+
+func (x *Server_RateLimit_Rule) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Server_RateLimit_Rule.ProtoReflect.Descriptor instead.
+func (*Server_RateLimit_Rule) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{1, 3, 0}
+}
+
+func (x *Server_RateLimit_Rule) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *Server_RateLimit_Rule) GetIdentity() Server_RateLimit_Identity {
+	if x != nil {
+		return x.Identity
+	}
+	return Server_RateLimit_IP
+}
+
+func (x *Server_RateLimit_Rule) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *Server_RateLimit_Rule) GetPeriod() *durationpb.Duration {
+	if x != nil {
+		return x.Period
+	}
+	return nil
+}
+
+func (x *Server_RateLimit_Rule) GetBurst() int32 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
+type Log_Sampling struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Initial       int32                  `protobuf:"varint,1,opt,name=initial,proto3" json:"initial,omitempty"`       // entries with the same level and message logged per tick before sampling starts
+	Thereafter    int32                  `protobuf:"varint,2,opt,name=thereafter,proto3" json:"thereafter,omitempty"` // then only every thereafter-th entry is logged, 0 drops the rest
+	Tick          *durationpb.Duration   `protobuf:"bytes,3,opt,name=tick,proto3" json:"tick,omitempty"`              // defaults to 1s
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Log_Sampling) Reset() {
+	*x = Log_Sampling{}
+	mi := &file_conf_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Log_Sampling) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Log_Sampling) ProtoMessage() {}
+
+func (x *Log_Sampling) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Log_Sampling.ProtoReflect.Descriptor instead.
+func (*Log_Sampling) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{8, 0}
+}
+
+func (x *Log_Sampling) GetInitial() int32 {
+	if x != nil {
+		return x.Initial
+	}
+	return 0
+}
+
+func (x *Log_Sampling) GetThereafter() int32 {
+	if x != nil {
+		return x.Thereafter
+	}
+	return 0
+}
+
+func (x *Log_Sampling) GetTick() *durationpb.Duration {
+	if x != nil {
+		return x.Tick
+	}
+	return nil
+}
+
+// Schedule of a periodic job, it runs on one replica per tick
+type Job_Schedule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cron          string                 `protobuf:"bytes,1,opt,name=cron,proto3" json:"cron,omitempty"` // cron expression with optional seconds, or a descriptor, eg: 0 3 * * *, @hourly, @every 1h
+	Misfire       Job_Misfire            `protobuf:"varint,2,opt,name=misfire,proto3,enum=kratos.api.Job_Misfire" json:"misfire,omitempty"`
+	Timeout       *durationpb.Duration   `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"` // unset runs without timeout
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job_Schedule) Reset() {
+	*x = Job_Schedule{}
+	mi := &file_conf_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job_Schedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job_Schedule) ProtoMessage() {}
+
+func (x *Job_Schedule) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job_Schedule.ProtoReflect.Descriptor instead.
+func (*Job_Schedule) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{10, 0}
+}
+
+func (x *Job_Schedule) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
+}
+
+func (x *Job_Schedule) GetMisfire() Job_Misfire {
+	if x != nil {
+		return x.Misfire
+	}
+	return Job_SKIP
+}
+
+func (x *Job_Schedule) GetTimeout() *durationpb.Duration {
 	if x != nil {
 		return x.Timeout
 	}
 	return nil
 }
 
+type Job_PurgeDeletedUser struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Interval      *durationpb.Duration   `protobuf:"bytes,1,opt,name=interval,proto3" json:"interval,omitempty"`   // runs every interval when schedule.cron is unset, defaults to 1h
+	Retention     *durationpb.Duration   `protobuf:"bytes,2,opt,name=retention,proto3" json:"retention,omitempty"` // soft deleted users older than this are hard deleted
+	BatchSize     int32                  `protobuf:"varint,3,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	Schedule      *Job_Schedule          `protobuf:"bytes,4,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job_PurgeDeletedUser) Reset() {
+	*x = Job_PurgeDeletedUser{}
+	mi := &file_conf_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job_PurgeDeletedUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job_PurgeDeletedUser) ProtoMessage() {}
+
+func (x *Job_PurgeDeletedUser) ProtoReflect() protoreflect.Message {
+	mi := &file_conf_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job_PurgeDeletedUser.ProtoReflect.Descriptor instead.
+func (*Job_PurgeDeletedUser) Descriptor() ([]byte, []int) {
+	return file_conf_proto_rawDescGZIP(), []int{10, 1}
+}
+
+func (x *Job_PurgeDeletedUser) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+func (x *Job_PurgeDeletedUser) GetRetention() *durationpb.Duration {
+	if x != nil {
+		return x.Retention
+	}
+	return nil
+}
+
+func (x *Job_PurgeDeletedUser) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *Job_PurgeDeletedUser) GetSchedule() *Job_Schedule {
+	if x != nil {
+		return x.Schedule
+	}
+	return nil
+}
+
 var File_conf_proto protoreflect.FileDescriptor
 
 const file_conf_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
 	"conf.proto\x12\n" +
-	"kratos.api\x1a\x1egoogle/protobuf/duration.proto\"\xb2\x01\n" +
-	"\x06Config\x12\x10\n" +
-	"\x03env\x18\x01 \x01(\tR\x03env\x12*\n" +
-	"\x06server\x18\x02 \x01(\v2\x12.kratos.api.ServerR\x06server\x12\x1e\n" +
-	"\x02db\x18\x03 \x01(\v2\x0e.kratos.api.DBR\x02db\x12'\n" +
-	"\x05redis\x18\x04 \x01(\v2\x11.kratos.api.RedisR\x05redis\x12!\n" +
-	"\x03log\x18\x05 \x01(\v2\x0f.kratos.api.LogR\x03log\"\xdc\x02\n" +
-	"\x06Server\x12+\n" +
-	"\x04http\x18\x01 \x01(\v2\x17.kratos.api.Server.HTTPR\x04http\x12+\n" +
-	"\x04grpc\x18\x02 \x01(\v2\x17.kratos.api.Server.GRPCR\x04grpc\x12\"\n" +
-	"\run_logging_op\x18\x03 \x03(\tR\vunLoggingOp\x1ai\n" +
-	"\x04HTTP\x12\x18\n" +
-	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x12\n" +
-	"\x04addr\x18\x02 \x01(\tR\x04addr\x123\n" +
-	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\x1ai\n" +
-	"\x04GRPC\x12\x18\n" +
-	"\anetwork\x18\x01 \x01(\tR\anetwork\x12\x12\n" +
-	"\x04addr\x18\x02 \x01(\tR\x04addr\x123\n" +
-	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\"\xa6\x01\n" +
-	"\x05Redis\x12\x14\n" +
-	"\x05addrs\x18\x01 \x03(\tR\x05addrs\x12\x1a\n" +
+	"kratos.api\x1a\x1bbuf/validate/validate.proto\x1a\x1egoogle/protobuf/duration.proto\"\x80\x04\n" +
+	"\x06Config\x12*\n" +
+	"\x03env\x18\x01 \x01(\tB\x18\xbaH\x15r\x13R\x00R\x03devR\x04testR\x04prodR\x03env\x122\n" +
+	"\x06server\x18\x02 \x01(\v2\x12.kratos.api.ServerB\x06\xbaH\x03\xc8\x01\x01R\x06server\x12&\n" +
+	"\x02db\x18\x03 \x01(\v2\x0e.kratos.api.DBB\x06\xbaH\x03\xc8\x01\x01R\x02db\x12/\n" +
+	"\x05redis\x18\x04 \x01(\v2\x11.kratos.api.RedisB\x06\xbaH\x03\xc8\x01\x01R\x05redis\x12!\n" +
+	"\x03log\x18\x05 \x01(\v2\x0f.kratos.api.LogR\x03log\x12!\n" +
+	"\x03job\x18\x06 \x01(\v2\x0f.kratos.api.JobR\x03job\x12'\n" +
+	"\x05trace\x18\a \x01(\v2\x11.kratos.api.TraceR\x05trace\x12<\n" +
+	"\bfeatures\x18\b \x03(\v2 .kratos.api.Config.FeaturesEntryR\bfeatures\x12*\n" +
+	"\x06outbox\x18\t \x01(\v2\x12.kratos.api.OutboxR\x06outbox\x12'\n" +
+	"\x05queue\x18\n" +
+	" \x01(\v2\x11.kratos.api.QueueR\x05queue\x1a;\n" +
+	"\rFeaturesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\bR\x05value:\x028\x01\"\xd7\x16\n" +
+	"\x06Server\x123\n" +
+	"\x04http\x18\x01 \x01(\v2\x17.kratos.api.Server.HTTPB\x06\xbaH\x03\xc8\x01\x01R\x04http\x123\n" +
+	"\x04grpc\x18\x02 \x01(\v2\x17.kratos.api.Server.GRPCB\x06\xbaH\x03\xc8\x01\x01R\x04grpc\x12\"\n" +
+	"\run_logging_op\x18\x03 \x03(\tR\vunLoggingOp\x12+\n" +
+	"\x04auth\x18\x04 \x01(\v2\x17.kratos.api.Server.AuthR\x04auth\x12;\n" +
+	"\n" +
+	"rate_limit\x18\x05 \x01(\v2\x1c.kratos.api.Server.RateLimitR\trateLimit\x12@\n" +
+	"\vidempotency\x18\x06 \x01(\v2\x1e.kratos.api.Server.IdempotencyR\vidempotency\x124\n" +
+	"\ametrics\x18\a \x01(\v2\x1a.kratos.api.Server.MetricsR\ametrics\x121\n" +
+	"\x06health\x18\b \x01(\v2\x19.kratos.api.Server.HealthR\x06health\x12.\n" +
+	"\x05admin\x18\t \x01(\v2\x18.kratos.api.Server.AdminR\x05admin\x1a\xd1\x03\n" +
+	"\x04HTTP\x128\n" +
+	"\anetwork\x18\x01 \x01(\tB\x1e\xbaH\x1br\x19R\x00R\x03tcpR\x04tcp4R\x04tcp6R\x04unixR\anetwork\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\x12=\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationB\b\xbaH\x05\xaa\x01\x022\x00R\atimeout\x12J\n" +
+	"\n" +
+	"error_mode\x18\x04 \x01(\x0e2!.kratos.api.Server.HTTP.ErrorModeB\b\xbaH\x05\x82\x01\x02\x10\x01R\terrorMode\"*\n" +
+	"\tErrorMode\x12\f\n" +
+	"\bENVELOPE\x10\x00\x12\x0f\n" +
+	"\vSTATUS_CODE\x10\x01:\xc3\x01\xbaH\xbf\x01\x1a\xbc\x01\n" +
+	"\thttp.addr\x12Faddr must be host:port or :port, or a socket path when network is unix\x1agthis.network == 'unix' || this.addr == '' || this.addr.startsWith(':') || this.addr.isHostAndPort(true)\x1a\xd9\x02\n" +
+	"\x04GRPC\x128\n" +
+	"\anetwork\x18\x01 \x01(\tB\x1e\xbaH\x1br\x19R\x00R\x03tcpR\x04tcp4R\x04tcp6R\x04unixR\anetwork\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\x12=\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationB\b\xbaH\x05\xaa\x01\x022\x00R\atimeout:\xc3\x01\xbaH\xbf\x01\x1a\xbc\x01\n" +
+	"\tgrpc.addr\x12Faddr must be host:port or :port, or a socket path when network is unix\x1agthis.network == 'unix' || this.addr == '' || this.addr.startsWith(':') || this.addr.isHostAndPort(true)\x1a\xd4\x03\n" +
+	"\x04Auth\x12\x16\n" +
+	"\x06enable\x18\x01 \x01(\bR\x06enable\x12\x1b\n" +
+	"\tpublic_op\x18\x02 \x03(\tR\bpublicOp\x12\x1f\n" +
+	"\vhmac_secret\x18\x03 \x01(\tR\n" +
+	"hmacSecret\x12\x1b\n" +
+	"\tjwks_file\x18\x04 \x01(\tR\bjwksFile\x12&\n" +
+	"\bjwks_url\x18\x05 \x01(\tB\v\xbaH\b\xd8\x01\x01r\x03\x88\x01\x01R\ajwksUrl\x12M\n" +
+	"\x15jwks_refresh_interval\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\x13jwksRefreshInterval\x12\x16\n" +
+	"\x06issuer\x18\a \x01(\tR\x06issuer\x12\x1a\n" +
+	"\baudience\x18\b \x03(\tR\baudience:\xad\x01\xbaH\xa9\x01\x1a\xa6\x01\n" +
+	"\bauth.key\x12Chmac_secret, jwks_file or jwks_url is required when auth is enabled\x1aU!this.enable || this.hmac_secret != '' || this.jwks_file != '' || this.jwks_url != ''\x1a\xc9\x03\n" +
+	"\tRateLimit\x12\x16\n" +
+	"\x06enable\x18\x01 \x01(\bR\x06enable\x12$\n" +
+	"\x0eapi_key_header\x18\x02 \x01(\tR\fapiKeyHeader\x12\x1f\n" +
+	"\vtrust_proxy\x18\x03 \x01(\bR\n" +
+	"trustProxy\x127\n" +
+	"\x05rules\x18\x04 \x03(\v2!.kratos.api.Server.RateLimit.RuleR\x05rules\x1a\xf8\x01\n" +
+	"\x04Rule\x12%\n" +
+	"\toperation\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\toperation\x12K\n" +
+	"\bidentity\x18\x02 \x01(\x0e2%.kratos.api.Server.RateLimit.IdentityB\b\xbaH\x05\x82\x01\x02\x10\x01R\bidentity\x12\x1d\n" +
+	"\x05limit\x18\x03 \x01(\x05B\a\xbaH\x04\x1a\x02 \x00R\x05limit\x12>\n" +
+	"\x06period\x18\x04 \x01(\v2\x19.google.protobuf.DurationB\v\xbaH\b\xc8\x01\x01\xaa\x01\x02*\x00R\x06period\x12\x1d\n" +
+	"\x05burst\x18\x05 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\x05burst\")\n" +
+	"\bIdentity\x12\x06\n" +
+	"\x02IP\x10\x00\x12\b\n" +
+	"\x04USER\x10\x01\x12\v\n" +
+	"\aAPI_KEY\x10\x02\x1a\\\n" +
+	"\vIdempotency\x12\x16\n" +
+	"\x06enable\x18\x01 \x01(\bR\x06enable\x125\n" +
+	"\x03ttl\x18\x02 \x01(\v2\x19.google.protobuf.DurationB\b\xbaH\x05\xaa\x01\x02*\x00R\x03ttl\x1aB\n" +
+	"\aMetrics\x12\x16\n" +
+	"\x06enable\x18\x01 \x01(\bR\x06enable\x12\x1f\n" +
+	"\x04path\x18\x02 \x01(\tB\v\xbaH\b\xd8\x01\x01r\x03:\x01/R\x04path\x1a\xd5\x01\n" +
+	"\x06Health\x12=\n" +
+	"\atimeout\x18\x01 \x01(\v2\x19.google.protobuf.DurationB\b\xbaH\x05\xaa\x01\x02*\x00R\atimeout\x12@\n" +
+	"\tcache_ttl\x18\x02 \x01(\v2\x19.google.protobuf.DurationB\b\xbaH\x05\xaa\x01\x022\x00R\bcacheTtl\x12J\n" +
+	"\x0eshutdown_delay\x18\x03 \x01(\v2\x19.google.protobuf.DurationB\b\xbaH\x05\xaa\x01\x022\x00R\rshutdownDelay\x1a\xac\x02\n" +
+	"\x05Admin\x12\x12\n" +
+	"\x04addr\x18\x01 \x01(\tR\x04addr\x12 \n" +
+	"\x05token\x18\x02 \x01(\tB\n" +
+	"\xbaH\a\xd8\x01\x01r\x02\x10 R\x05token:\xec\x01\xbaH\xe8\x01\x1ah\n" +
+	"\vadmin.token\x124token is required when the admin listener is enabled\x1a#this.addr == '' || this.token != ''\x1a|\n" +
+	"\n" +
+	"admin.addr\x12\x1faddr must be host:port or :port\x1aMthis.addr == '' || this.addr.startsWith(':') || this.addr.isHostAndPort(true)\"\xc2\x01\n" +
+	"\x05Redis\x12%\n" +
+	"\x05addrs\x18\x01 \x03(\tB\x0f\xbaH\f\x92\x01\t\b\x01\"\x05r\x03\x80\x02\x01R\x05addrs\x12\x1a\n" +
 	"\busername\x18\x02 \x01(\tR\busername\x12\x16\n" +
 	"\x06passwd\x18\x03 \x01(\tR\x06passwd\x12\x1f\n" +
 	"\vmaster_name\x18\x04 \x01(\tR\n" +
-	"masterName\x12\x0e\n" +
-	"\x02db\x18\x05 \x01(\x05R\x02db\x12\"\n" +
-	"\ris_enable_tls\x18\x06 \x01(\bR\visEnableTls\"^\n" +
-	"\x02DB\x12,\n" +
-	"\x06master\x18\x01 \x01(\v2\x14.kratos.api.DbConfigR\x06master\x12*\n" +
-	"\x05slave\x18\x02 \x01(\v2\x14.kratos.api.DbConfigR\x05slave\"\xa8\x01\n" +
-	"\bDbConfig\x12\x10\n" +
-	"\x03dsn\x18\x01 \x01(\tR\x03dsn\x12\"\n" +
-	"\rmax_open_conn\x18\x02 \x01(\x05R\vmaxOpenConn\x12\"\n" +
-	"\rmax_idle_conn\x18\x03 \x01(\x05R\vmaxIdleConn\x12*\n" +
-	"\x11max_lifetime_conn\x18\x04 \x01(\x05R\x0fmaxLifetimeConn\x12\x16\n" +
-	"\x06driver\x18\x05 \x01(\tR\x06driver\"t\n" +
+	"masterName\x12\x19\n" +
+	"\x02db\x18\x05 \x01(\x05B\t\xbaH\x06\x1a\x04\x18\x0f(\x00R\x02db\x12\"\n" +
+	"\ris_enable_tls\x18\x06 \x01(\bR\visEnableTls\"\x91\x01\n" +
+	"\x02DB\x124\n" +
+	"\x06master\x18\x01 \x01(\v2\x14.kratos.api.DbConfigB\x06\xbaH\x03\xc8\x01\x01R\x06master\x122\n" +
+	"\x05slave\x18\x02 \x01(\v2\x14.kratos.api.DbConfigB\x06\xbaH\x03\xc8\x01\x01R\x05slave\x12!\n" +
+	"\fauto_migrate\x18\x03 \x01(\bR\vautoMigrate\"\xd3\x02\n" +
+	"\bDbConfig\x12\x19\n" +
+	"\x03dsn\x18\x01 \x01(\tB\a\xbaH\x04r\x02\x10\x01R\x03dsn\x12+\n" +
+	"\rmax_open_conn\x18\x02 \x01(\x05B\a\xbaH\x04\x1a\x02 \x00R\vmaxOpenConn\x12+\n" +
+	"\rmax_idle_conn\x18\x03 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\vmaxIdleConn\x123\n" +
+	"\x11max_lifetime_conn\x18\x04 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\x0fmaxLifetimeConn\x12$\n" +
+	"\x06driver\x18\x05 \x01(\tB\f\xbaH\tr\aR\x05mysqlR\x06driver:w\xbaHt\x1ar\n" +
+	"\x10db.max_idle_conn\x124max_idle_conn must not be greater than max_open_conn\x1a(this.max_idle_conn <= this.max_open_conn\"\xa9\x02\n" +
+	"\x06Outbox\x12\x16\n" +
+	"\x06enable\x18\x01 \x01(\bR\x06enable\x12H\n" +
+	"\rpoll_interval\x18\x02 \x01(\v2\x19.google.protobuf.DurationB\b\xbaH\x05\xaa\x01\x02*\x00R\fpollInterval\x12&\n" +
+	"\n" +
+	"batch_size\x18\x03 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\tbatchSize\x12#\n" +
+	"\rstream_prefix\x18\x04 \x01(\tR\fstreamPrefix\x12-\n" +
+	"\x0estream_max_len\x18\x05 \x01(\x03B\a\xbaH\x04\"\x02(\x00R\fstreamMaxLen\x12A\n" +
+	"\tretention\x18\x06 \x01(\v2\x19.google.protobuf.DurationB\b\xbaH\x05\xaa\x01\x02*\x00R\tretention\"\xa4\x02\n" +
+	"\x05Queue\x12\x16\n" +
+	"\x06enable\x18\x01 \x01(\bR\x06enable\x12#\n" +
+	"\rstream_prefix\x18\x02 \x01(\tR\fstreamPrefix\x12\x14\n" +
+	"\x05group\x18\x03 \x01(\tR\x05group\x12)\n" +
+	"\vconcurrency\x18\x04 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\vconcurrency\x12B\n" +
+	"\n" +
+	"claim_idle\x18\x05 \x01(\v2\x19.google.protobuf.DurationB\b\xbaH\x05\xaa\x01\x02*\x00R\tclaimIdle\x12.\n" +
+	"\x0emax_deliveries\x18\x06 \x01(\x03B\a\xbaH\x04\"\x02(\x00R\rmaxDeliveries\x12)\n" +
+	"\fdead_max_len\x18\a \x01(\x03B\a\xbaH\x04\"\x02(\x00R\n" +
+	"deadMaxLen\"\xfb\x04\n" +
+	"\x05Trace\x12@\n" +
+	"\bexporter\x18\x01 \x01(\x0e2\x1a.kratos.api.Trace.ExporterB\b\xbaH\x05\x82\x01\x02\x10\x01R\bexporter\x12\x1a\n" +
+	"\bendpoint\x18\x02 \x01(\tR\bendpoint\x12\x1a\n" +
+	"\binsecure\x18\x03 \x01(\bR\binsecure\x128\n" +
+	"\aheaders\x18\x04 \x03(\v2\x1e.kratos.api.Trace.HeadersEntryR\aheaders\x12\x12\n" +
+	"\x04file\x18\x05 \x01(\tR\x04file\x12?\n" +
+	"\fsample_ratio\x18\x06 \x01(\x01B\x17\xbaH\x14\x12\x12\x19\x00\x00\x00\x00\x00\x00\xf0?)\x00\x00\x00\x00\x00\x00\x00\x00H\x00R\vsampleRatio\x88\x01\x01\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"H\n" +
+	"\bExporter\x12\b\n" +
+	"\x04NONE\x10\x00\x12\r\n" +
+	"\tOTLP_GRPC\x10\x01\x12\r\n" +
+	"\tOTLP_HTTP\x10\x02\x12\n" +
+	"\n" +
+	"\x06STDOUT\x10\x03\x12\b\n" +
+	"\x04FILE\x10\x04:\xd1\x01\xbaH\xcd\x01\x1ao\n" +
+	"\x0etrace.endpoint\x12*endpoint is required by the otlp exporters\x1a1!(this.exporter in [1, 2]) || this.endpoint != ''\x1aZ\n" +
+	"\n" +
+	"trace.file\x12%file is required by the file exporter\x1a%this.exporter != 4 || this.file != ''B\x0f\n" +
+	"\r_sample_ratio\"\xe1\x05\n" +
 	"\x03Log\x12\x19\n" +
 	"\bapp_name\x18\x01 \x01(\tR\aappName\x12\"\n" +
 	"\ris_write_file\x18\x02 \x01(\bR\visWriteFile\x12.\n" +
-	"\blog_file\x18\x03 \x01(\v2\x13.kratos.api.LogFileR\alogFile\"Q\n" +
+	"\blog_file\x18\x03 \x01(\v2\x13.kratos.api.LogFileR\alogFile\x127\n" +
+	"\x05level\x18\x04 \x01(\tB!\xbaH\x1er\x1cR\x00R\x05debugR\x04infoR\x04warnR\x05errorR\x05level\x12:\n" +
+	"\bencoding\x18\x05 \x01(\tB\x1e\xbaH\x1br\x19R\x00R\x04jsonR\aconsoleR\x06logfmtR\bencoding\x12\\\n" +
+	"\amodules\x18\x06 \x03(\v2\x1c.kratos.api.Log.ModulesEntryB$\xbaH!\x9a\x01\x1e*\x1cr\x1aR\x05debugR\x04infoR\x04warnR\x05errorR\amodules\x124\n" +
+	"\bsampling\x18\a \x01(\v2\x18.kratos.api.Log.SamplingR\bsampling\x1a\x8f\x01\n" +
+	"\bSampling\x12!\n" +
+	"\ainitial\x18\x01 \x01(\x05B\a\xbaH\x04\x1a\x02 \x00R\ainitial\x12'\n" +
+	"\n" +
+	"thereafter\x18\x02 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\n" +
+	"thereafter\x127\n" +
+	"\x04tick\x18\x03 \x01(\v2\x19.google.protobuf.DurationB\b\xbaH\x05\xaa\x01\x02*\x00R\x04tick\x1a:\n" +
+	"\fModulesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01:\x93\x01\xbaH\x8f\x01\x1a\x8c\x01\n" +
+	"\flog.log_file\x123log_file.name is required when is_write_file is set\x1aG!this.is_write_file || (has(this.log_file) && this.log_file.name != '')\"\xa9\x01\n" +
 	"\aLogFile\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12\x19\n" +
-	"\bmax_size\x18\x02 \x01(\x05R\amaxSize\x12\x17\n" +
-	"\amax_age\x18\x03 \x01(\x05R\x06maxAgeB$Z\"server-template/internal/conf;confb\x06proto3"
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\"\n" +
+	"\bmax_size\x18\x02 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\amaxSize\x12 \n" +
+	"\amax_age\x18\x03 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\x06maxAge\x12(\n" +
+	"\vmax_backups\x18\x04 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\n" +
+	"maxBackups\x12\x1a\n" +
+	"\bcompress\x18\x05 \x01(\bR\bcompress\"\xb8\x04\n" +
+	"\x03Job\x12N\n" +
+	"\x12purge_deleted_user\x18\x01 \x01(\v2 .kratos.api.Job.PurgeDeletedUserR\x10purgeDeletedUser\x12*\n" +
+	"\fhistory_size\x18\x02 \x01(\x03B\a\xbaH\x04\"\x02(\x00R\vhistorySize\x1a\x9a\x01\n" +
+	"\bSchedule\x12\x12\n" +
+	"\x04cron\x18\x01 \x01(\tR\x04cron\x12;\n" +
+	"\amisfire\x18\x02 \x01(\x0e2\x17.kratos.api.Job.MisfireB\b\xbaH\x05\x82\x01\x02\x10\x01R\amisfire\x12=\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationB\b\xbaH\x05\xaa\x01\x02*\x00R\atimeout\x1a\xf4\x01\n" +
+	"\x10PurgeDeletedUser\x12?\n" +
+	"\binterval\x18\x01 \x01(\v2\x19.google.protobuf.DurationB\b\xbaH\x05\xaa\x01\x02*\x00R\binterval\x12A\n" +
+	"\tretention\x18\x02 \x01(\v2\x19.google.protobuf.DurationB\b\xbaH\x05\xaa\x01\x02*\x00R\tretention\x12&\n" +
+	"\n" +
+	"batch_size\x18\x03 \x01(\x05B\a\xbaH\x04\x1a\x02(\x00R\tbatchSize\x124\n" +
+	"\bschedule\x18\x04 \x01(\v2\x18.kratos.api.Job.ScheduleR\bschedule\"!\n" +
+	"\aMisfire\x12\b\n" +
+	"\x04SKIP\x10\x00\x12\f\n" +
+	"\bCATCH_UP\x10\x01B$Z\"server-template/internal/conf;confb\x06proto3"
 
 var (
 	file_conf_proto_rawDescOnce sync.Once
@@ -673,36 +2126,92 @@ func file_conf_proto_rawDescGZIP() []byte {
 	return file_conf_proto_rawDescData
 }
 
-var file_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_conf_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_conf_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
 var file_conf_proto_goTypes = []any{
-	(*Config)(nil),              // 0: kratos.api.Config
-	(*Server)(nil),              // 1: kratos.api.Server
-	(*Redis)(nil),               // 2: kratos.api.Redis
-	(*DB)(nil),                  // 3: kratos.api.DB
-	(*DbConfig)(nil),            // 4: kratos.api.DbConfig
-	(*Log)(nil),                 // 5: kratos.api.Log
-	(*LogFile)(nil),             // 6: kratos.api.LogFile
-	(*Server_HTTP)(nil),         // 7: kratos.api.Server.HTTP
-	(*Server_GRPC)(nil),         // 8: kratos.api.Server.GRPC
-	(*durationpb.Duration)(nil), // 9: google.protobuf.Duration
+	(Server_HTTP_ErrorMode)(0),     // 0: kratos.api.Server.HTTP.ErrorMode
+	(Server_RateLimit_Identity)(0), // 1: kratos.api.Server.RateLimit.Identity
+	(Trace_Exporter)(0),            // 2: kratos.api.Trace.Exporter
+	(Job_Misfire)(0),               // 3: kratos.api.Job.Misfire
+	(*Config)(nil),                 // 4: kratos.api.Config
+	(*Server)(nil),                 // 5: kratos.api.Server
+	(*Redis)(nil),                  // 6: kratos.api.Redis
+	(*DB)(nil),                     // 7: kratos.api.DB
+	(*DbConfig)(nil),               // 8: kratos.api.DbConfig
+	(*Outbox)(nil),                 // 9: kratos.api.Outbox
+	(*Queue)(nil),                  // 10: kratos.api.Queue
+	(*Trace)(nil),                  // 11: kratos.api.Trace
+	(*Log)(nil),                    // 12: kratos.api.Log
+	(*LogFile)(nil),                // 13: kratos.api.LogFile
+	(*Job)(nil),                    // 14: kratos.api.Job
+	nil,                            // 15: kratos.api.Config.FeaturesEntry
+	(*Server_HTTP)(nil),            // 16: kratos.api.Server.HTTP
+	(*Server_GRPC)(nil),            // 17: kratos.api.Server.GRPC
+	(*Server_Auth)(nil),            // 18: kratos.api.Server.Auth
+	(*Server_RateLimit)(nil),       // 19: kratos.api.Server.RateLimit
+	(*Server_Idempotency)(nil),     // 20: kratos.api.Server.Idempotency
+	(*Server_Metrics)(nil),         // 21: kratos.api.Server.Metrics
+	(*Server_Health)(nil),          // 22: kratos.api.Server.Health
+	(*Server_Admin)(nil),           // 23: kratos.api.Server.Admin
+	(*Server_RateLimit_Rule)(nil),  // 24: kratos.api.Server.RateLimit.Rule
+	nil,                            // 25: kratos.api.Trace.HeadersEntry
+	(*Log_Sampling)(nil),           // 26: kratos.api.Log.Sampling
+	nil,                            // 27: kratos.api.Log.ModulesEntry
+	(*Job_Schedule)(nil),           // 28: kratos.api.Job.Schedule
+	(*Job_PurgeDeletedUser)(nil),   // 29: kratos.api.Job.PurgeDeletedUser
+	(*durationpb.Duration)(nil),    // 30: google.protobuf.Duration
 }
 var file_conf_proto_depIdxs = []int32{
-	1,  // 0: kratos.api.Config.server:type_name -> kratos.api.Server
-	3,  // 1: kratos.api.Config.db:type_name -> kratos.api.DB
-	2,  // 2: kratos.api.Config.redis:type_name -> kratos.api.Redis
-	5,  // 3: kratos.api.Config.log:type_name -> kratos.api.Log
-	7,  // 4: kratos.api.Server.http:type_name -> kratos.api.Server.HTTP
-	8,  // 5: kratos.api.Server.grpc:type_name -> kratos.api.Server.GRPC
-	4,  // 6: kratos.api.DB.master:type_name -> kratos.api.DbConfig
-	4,  // 7: kratos.api.DB.slave:type_name -> kratos.api.DbConfig
-	6,  // 8: kratos.api.Log.log_file:type_name -> kratos.api.LogFile
-	9,  // 9: kratos.api.Server.HTTP.timeout:type_name -> google.protobuf.Duration
-	9,  // 10: kratos.api.Server.GRPC.timeout:type_name -> google.protobuf.Duration
-	11, // [11:11] is the sub-list for method output_type
-	11, // [11:11] is the sub-list for method input_type
-	11, // [11:11] is the sub-list for extension type_name
-	11, // [11:11] is the sub-list for extension extendee
-	0,  // [0:11] is the sub-list for field type_name
+	5,  // 0: kratos.api.Config.server:type_name -> kratos.api.Server
+	7,  // 1: kratos.api.Config.db:type_name -> kratos.api.DB
+	6,  // 2: kratos.api.Config.redis:type_name -> kratos.api.Redis
+	12, // 3: kratos.api.Config.log:type_name -> kratos.api.Log
+	14, // 4: kratos.api.Config.job:type_name -> kratos.api.Job
+	11, // 5: kratos.api.Config.trace:type_name -> kratos.api.Trace
+	15, // 6: kratos.api.Config.features:type_name -> kratos.api.Config.FeaturesEntry
+	9,  // 7: kratos.api.Config.outbox:type_name -> kratos.api.Outbox
+	10, // 8: kratos.api.Config.queue:type_name -> kratos.api.Queue
+	16, // 9: kratos.api.Server.http:type_name -> kratos.api.Server.HTTP
+	17, // 10: kratos.api.Server.grpc:type_name -> kratos.api.Server.GRPC
+	18, // 11: kratos.api.Server.auth:type_name -> kratos.api.Server.Auth
+	19, // 12: kratos.api.Server.rate_limit:type_name -> kratos.api.Server.RateLimit
+	20, // 13: kratos.api.Server.idempotency:type_name -> kratos.api.Server.Idempotency
+	21, // 14: kratos.api.Server.metrics:type_name -> kratos.api.Server.Metrics
+	22, // 15: kratos.api.Server.health:type_name -> kratos.api.Server.Health
+	23, // 16: kratos.api.Server.admin:type_name -> kratos.api.Server.Admin
+	8,  // 17: kratos.api.DB.master:type_name -> kratos.api.DbConfig
+	8,  // 18: kratos.api.DB.slave:type_name -> kratos.api.DbConfig
+	30, // 19: kratos.api.Outbox.poll_interval:type_name -> google.protobuf.Duration
+	30, // 20: kratos.api.Outbox.retention:type_name -> google.protobuf.Duration
+	30, // 21: kratos.api.Queue.claim_idle:type_name -> google.protobuf.Duration
+	2,  // 22: kratos.api.Trace.exporter:type_name -> kratos.api.Trace.Exporter
+	25, // 23: kratos.api.Trace.headers:type_name -> kratos.api.Trace.HeadersEntry
+	13, // 24: kratos.api.Log.log_file:type_name -> kratos.api.LogFile
+	27, // 25: kratos.api.Log.modules:type_name -> kratos.api.Log.ModulesEntry
+	26, // 26: kratos.api.Log.sampling:type_name -> kratos.api.Log.Sampling
+	29, // 27: kratos.api.Job.purge_deleted_user:type_name -> kratos.api.Job.PurgeDeletedUser
+	30, // 28: kratos.api.Server.HTTP.timeout:type_name -> google.protobuf.Duration
+	0,  // 29: kratos.api.Server.HTTP.error_mode:type_name -> kratos.api.Server.HTTP.ErrorMode
+	30, // 30: kratos.api.Server.GRPC.timeout:type_name -> google.protobuf.Duration
+	30, // 31: kratos.api.Server.Auth.jwks_refresh_interval:type_name -> google.protobuf.Duration
+	24, // 32: kratos.api.Server.RateLimit.rules:type_name -> kratos.api.Server.RateLimit.Rule
+	30, // 33: kratos.api.Server.Idempotency.ttl:type_name -> google.protobuf.Duration
+	30, // 34: kratos.api.Server.Health.timeout:type_name -> google.protobuf.Duration
+	30, // 35: kratos.api.Server.Health.cache_ttl:type_name -> google.protobuf.Duration
+	30, // 36: kratos.api.Server.Health.shutdown_delay:type_name -> google.protobuf.Duration
+	1,  // 37: kratos.api.Server.RateLimit.Rule.identity:type_name -> kratos.api.Server.RateLimit.Identity
+	30, // 38: kratos.api.Server.RateLimit.Rule.period:type_name -> google.protobuf.Duration
+	30, // 39: kratos.api.Log.Sampling.tick:type_name -> google.protobuf.Duration
+	3,  // 40: kratos.api.Job.Schedule.misfire:type_name -> kratos.api.Job.Misfire
+	30, // 41: kratos.api.Job.Schedule.timeout:type_name -> google.protobuf.Duration
+	30, // 42: kratos.api.Job.PurgeDeletedUser.interval:type_name -> google.protobuf.Duration
+	30, // 43: kratos.api.Job.PurgeDeletedUser.retention:type_name -> google.protobuf.Duration
+	28, // 44: kratos.api.Job.PurgeDeletedUser.schedule:type_name -> kratos.api.Job.Schedule
+	45, // [45:45] is the sub-list for method output_type
+	45, // [45:45] is the sub-list for method input_type
+	45, // [45:45] is the sub-list for extension type_name
+	45, // [45:45] is the sub-list for extension extendee
+	0,  // [0:45] is the sub-list for field type_name
 }
 
 func init() { file_conf_proto_init() }
@@ -710,18 +2219,20 @@ func file_conf_proto_init() {
 	if File_conf_proto != nil {
 		return
 	}
+	file_conf_proto_msgTypes[7].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_conf_proto_rawDesc), len(file_conf_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   9,
+			NumEnums:      4,
+			NumMessages:   26,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_conf_proto_goTypes,
 		DependencyIndexes: file_conf_proto_depIdxs,
+		EnumInfos:         file_conf_proto_enumTypes,
 		MessageInfos:      file_conf_proto_msgTypes,
 	}.Build()
 	File_conf_proto = out.File
@@ -0,0 +1,58 @@
+package conf
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const redacted = "***"
+
+// secretNames match credentials by field name, trace.headers carries collector auth tokens
+var secretNames = []string{"secret", "passwd", "password", "dsn", "token", "headers"}
+
+// IsSecret reports whether the field holds a credential that must not be logged or dumped
+func IsSecret(fd protoreflect.FieldDescriptor) bool {
+	name := strings.ToLower(string(fd.Name()))
+	for _, s := range secretNames {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns a copy of c with every set secret replaced by ***, log or dump this copy only
+func Redact(c *Config) *Config {
+	r := proto.Clone(c).(*Config)
+	redact(r.ProtoReflect())
+	return r
+}
+
+func redact(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case IsSecret(fd) && fd.Kind() == protoreflect.StringKind && fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				list.Set(i, protoreflect.ValueOfString(redacted))
+			}
+		case IsSecret(fd) && fd.Kind() == protoreflect.StringKind && !fd.IsMap():
+			m.Set(fd, protoreflect.ValueOfString(redacted))
+		case IsSecret(fd) && fd.IsMap() && fd.MapValue().Kind() == protoreflect.StringKind:
+			v.Map().Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+				v.Map().Set(k, protoreflect.ValueOfString(redacted))
+				return true
+			})
+		case fd.Kind() == protoreflect.MessageKind && fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				redact(list.Get(i).Message())
+			}
+		case fd.Kind() == protoreflect.MessageKind && !fd.IsMap():
+			redact(v.Message())
+		}
+		return true
+	})
+}
@@ -0,0 +1,27 @@
+package conf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	c := &Config{
+		Server: &Server{Auth: &Server_Auth{HmacSecret: "hs256-key", Issuer: "me"}},
+		Db:     &DB{Master: &DbConfig{Dsn: "root:root123@tcp(localhost:33306)/dev", MaxOpenConn: 100}},
+		Redis:  &Redis{Passwd: "redis-pass", Addrs: []string{"localhost:6379"}},
+		Trace:  &Trace{Headers: map[string]string{"authorization": "Bearer abc"}},
+	}
+
+	r := Redact(c)
+	require.Equal(t, redacted, r.Server.Auth.HmacSecret)
+	require.Equal(t, "me", r.Server.Auth.Issuer)
+	require.Equal(t, redacted, r.Db.Master.Dsn)
+	require.Equal(t, int32(100), r.Db.Master.MaxOpenConn)
+	require.Equal(t, redacted, r.Redis.Passwd)
+	require.Equal(t, []string{"localhost:6379"}, r.Redis.Addrs)
+	require.Equal(t, redacted, r.Trace.Headers["authorization"])
+	// the original is untouched
+	require.Equal(t, "redis-pass", c.Redis.Passwd)
+}
@@ -0,0 +1,109 @@
+package conf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var durationName = (&durationpb.Duration{}).ProtoReflect().Descriptor().FullName()
+
+// Reloadable is a component that applies its part of a changed config at runtime.
+// Validate runs for every component before any Apply, so an invalid change is rejected as a whole.
+type Reloadable interface {
+	Validate(c *Config) error
+	Apply(old, new *Config)
+}
+
+// Change is a field that differs between two configs, Path is the yaml path, eg: server.rate_limit.rules
+type Change struct {
+	Path string
+	Old  string
+	New  string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Path, c.Old, c.New)
+}
+
+// Diff lists the changed leaf fields, lists and maps are compared as a whole
+func Diff(old, new *Config) []Change {
+	var changes []Change
+	diffMessage("", old.ProtoReflect(), new.ProtoReflect(), &changes)
+	return changes
+}
+
+func diffMessage(prefix string, a, b protoreflect.Message, changes *[]Change) {
+	fields := a.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		path := string(fd.Name())
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		if isNested(fd) {
+			diffMessage(path, a.Get(fd).Message(), b.Get(fd).Message(), changes)
+			continue
+		}
+		va, vb := a.Get(fd), b.Get(fd)
+		if va.Equal(vb) {
+			continue
+		}
+		*changes = append(*changes, Change{Path: path, Old: format(fd, va), New: format(fd, vb)})
+	}
+}
+
+// isNested is a singular config section, a duration is a leaf
+func isNested(fd protoreflect.FieldDescriptor) bool {
+	return fd.Kind() == protoreflect.MessageKind && fd.Cardinality() != protoreflect.Repeated &&
+		fd.Message().FullName() != durationName
+}
+
+// format prints a value for the diff log, secrets only show whether they changed
+func format(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	if IsSecret(fd) {
+		return redacted
+	}
+	switch {
+	case fd.IsList():
+		list := v.List()
+		items := make([]string, 0, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			items = append(items, formatScalar(fd, list.Get(i)))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case fd.IsMap():
+		var items []string
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			items = append(items, fmt.Sprintf("%s: %s", k.String(), formatScalar(fd.MapValue(), v)))
+			return true
+		})
+		sort.Strings(items)
+		return "{" + strings.Join(items, ", ") + "}"
+	}
+	return formatScalar(fd, v)
+}
+
+func formatScalar(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		if d, ok := v.Message().Interface().(*durationpb.Duration); ok {
+			if !v.Message().IsValid() {
+				return "<unset>"
+			}
+			return d.AsDuration().String()
+		}
+		return "{" + prototext.MarshalOptions{}.Format(v.Message().Interface()) + "}"
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+	case protoreflect.StringKind:
+		return fmt.Sprintf("%q", v.String())
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,66 @@
+package conf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestDiff(t *testing.T) {
+	base := func() *Config {
+		return &Config{
+			Server: &Server{UnLoggingOp: []string{"/api.server.Server/ping"}},
+			Db:     &DB{Master: &DbConfig{Dsn: "root:pass@tcp(db)/dev", MaxOpenConn: 100}},
+			Job:    &Job{PurgeDeletedUser: &Job_PurgeDeletedUser{Interval: durationpb.New(3600e9)}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   []string
+	}{
+		{
+			name:   "no change",
+			change: func(c *Config) {},
+		},
+		{
+			name:   "scalar",
+			change: func(c *Config) { c.Db.Master.MaxOpenConn = 50 },
+			want:   []string{"db.master.max_open_conn: 100 -> 50"},
+		},
+		{
+			name:   "list",
+			change: func(c *Config) { c.Server.UnLoggingOp = nil },
+			want:   []string{`server.un_logging_op: ["/api.server.Server/ping"] -> []`},
+		},
+		{
+			name:   "secret is redacted",
+			change: func(c *Config) { c.Db.Master.Dsn = "root:other@tcp(db)/dev" },
+			want:   []string{"db.master.dsn: *** -> ***"},
+		},
+		{
+			name:   "duration",
+			change: func(c *Config) { c.Job.PurgeDeletedUser.Interval = durationpb.New(60e9) },
+			want:   []string{"job.purge_deleted_user.interval: 1h0m0s -> 1m0s"},
+		},
+		{
+			name:   "new section",
+			change: func(c *Config) { c.Features = map[string]bool{"beta": true} },
+			want:   []string{"features: {} -> {beta: true}"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := base()
+			tt.change(next)
+			var got []string
+			for _, c := range Diff(base(), next) {
+				got = append(got, c.String())
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
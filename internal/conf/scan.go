@@ -0,0 +1,86 @@
+package conf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"server-template/pkg/middleware/validate"
+
+	"github.com/bufbuild/protovalidate-go"
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Scan reads src into a Config. Unlike config.Scan it fails on keys conf.proto doesn't know
+// and on values that break its buf.validate rules, every problem names its yaml path.
+func Scan(src config.Config) (*Config, error) {
+	var raw map[string]any
+	if err := src.Scan(&raw); err != nil {
+		return nil, errors.Wrap(err, "read config failed")
+	}
+	c := &Config{}
+	var problems []string
+	unknownKeys("", raw, c.ProtoReflect().Descriptor(), &problems)
+	if len(problems) > 0 {
+		return nil, invalid(problems)
+	}
+
+	if err := src.Scan(c); err != nil {
+		return nil, errors.Wrap(err, "parse config failed")
+	}
+	if err := protovalidate.Validate(c); err != nil {
+		for _, v := range validate.FieldViolations(err) {
+			path := v.Field
+			if path == "" {
+				path = "(" + v.Rule + ")"
+			}
+			problems = append(problems, fmt.Sprintf("%s: %s", path, v.Message))
+		}
+		if len(problems) == 0 {
+			return nil, errors.Wrap(err, "validate config failed")
+		}
+		return nil, invalid(problems)
+	}
+	return c, nil
+}
+
+func invalid(problems []string) error {
+	sort.Strings(problems)
+	return errors.Errorf("invalid config:\n  %s", strings.Join(problems, "\n  "))
+}
+
+// unknownKeys walks raw along desc and reports the keys without a field, by proto or json name
+func unknownKeys(prefix string, raw map[string]any, desc protoreflect.MessageDescriptor, problems *[]string) {
+	fields := desc.Fields()
+	for key, v := range raw {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		fd := fields.ByName(protoreflect.Name(key))
+		if fd == nil {
+			fd = fields.ByJSONName(key)
+		}
+		if fd == nil {
+			*problems = append(*problems, fmt.Sprintf("%s: unknown field", path))
+			continue
+		}
+		if fd.Kind() != protoreflect.MessageKind || fd.IsMap() || fd.Message().FullName() == durationName {
+			continue
+		}
+		if fd.IsList() {
+			items, _ := v.([]any)
+			for i, item := range items {
+				if m, ok := item.(map[string]any); ok {
+					unknownKeys(fmt.Sprintf("%s[%d]", path, i), m, fd.Message(), problems)
+				}
+			}
+			continue
+		}
+		if m, ok := v.(map[string]any); ok {
+			unknownKeys(path, m, fd.Message(), problems)
+		}
+	}
+}
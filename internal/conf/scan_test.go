@@ -0,0 +1,106 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+	"github.com/stretchr/testify/require"
+)
+
+const validYAML = `
+server:
+  http:
+    addr: 0.0.0.0:8000
+  grpc:
+    addr: 0.0.0.0:9000
+db:
+  master:
+    driver: mysql
+    dsn: root@tcp(localhost:3306)/dev
+    max_open_conn: 10
+  slave:
+    driver: mysql
+    dsn: root@tcp(localhost:3306)/dev
+    max_open_conn: 10
+redis:
+  addrs: [localhost:6379]
+`
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra string
+		err   string
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name:  "unknown key",
+			extra: "log:\n  levle: debug\n",
+			err:   "log.levle: unknown field",
+		},
+		{
+			name:  "unknown key in list",
+			extra: "server:\n  rate_limit:\n    rules:\n      - operation: \"*\"\n        limit: 1\n        period: 1s\n        per: ip\n",
+			err:   "server.rate_limit.rules[0].per: unknown field",
+		},
+		{
+			name:  "rule violation",
+			extra: "redis:\n  db: 16\n",
+			err:   "redis.db: value must be greater than or equal to 0 and less than or equal to 15",
+		},
+		{
+			name:  "port only addr",
+			extra: "server:\n  http:\n    addr: :8000\n  admin:\n    addr: :8001\n    token: 0123456789abcdef0123456789abcdef\n",
+		},
+		{
+			name:  "unix socket",
+			extra: "server:\n  grpc:\n    network: unix\n    addr: /run/server/grpc.sock\n",
+		},
+		{
+			name:  "socket path without unix network",
+			extra: "server:\n  grpc:\n    addr: /run/server/grpc.sock\n",
+			err:   "server.grpc: addr must be host:port or :port, or a socket path when network is unix",
+		},
+		{
+			name:  "invalid admin addr",
+			extra: "server:\n  admin:\n    addr: localhost\n    token: 0123456789abcdef0123456789abcdef\n",
+			err:   "server.admin: addr must be host:port or :port",
+		},
+		{
+			name:  "empty sampling",
+			extra: "log:\n  sampling: {}\n",
+			err:   "log.sampling.initial: value must be greater than 0",
+		},
+		{
+			name:  "message rule violation",
+			extra: "trace:\n  exporter: OTLP_GRPC\n",
+			err:   "trace: endpoint is required by the otlp exporters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte(validYAML), 0o644))
+			if tt.extra != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "b.yaml"), []byte(tt.extra), 0o644))
+			}
+			c := config.New(config.WithSource(file.NewSource(dir)))
+			require.NoError(t, c.Load())
+			defer c.Close()
+
+			bc, err := Scan(c)
+			if tt.err == "" {
+				require.NoError(t, err)
+				require.Equal(t, int32(10), bc.Db.Master.MaxOpenConn)
+				return
+			}
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"server-template/internal/biz"
 	"server-template/internal/conf"
 	"server-template/internal/data/queries"
+	"server-template/pkg/health"
+	"server-template/pkg/metrics"
+	"server-template/pkg/tracer"
 
 	"github.com/go-kratos/kratos/v2/log"
 
@@ -18,7 +21,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-var ProviderSet = wire.NewSet(NewTransaction, NewData, NewDB, NewUserRepo, NewRedis)
+var ProviderSet = wire.NewSet(NewTransaction, NewFencer, NewData, NewDB, NewUserRepo, NewEventPublisher, NewOutboxStore, NewJobQueue, NewRedis, NewHealthCheckers)
 
 type contextTxKey struct{}
 
@@ -26,10 +29,9 @@ type contextTxKey struct{}
 type Data struct {
 	masterConn    *sql.DB
 	slaveConn     *sql.DB
-	masterQueries *queries.Queries
-	slaveQueries  *queries.Queries
+	masterQueries queries.Querier
+	slaveQueries  queries.Querier
 	log           *log.Helper
-	*queries.Queries
 }
 
 func (s *Data) WithRead() queries.Querier {
@@ -37,7 +39,7 @@ func (s *Data) WithRead() queries.Querier {
 }
 
 func (s *Data) WithWrite(ctx context.Context) queries.Querier {
-	tx, ok := ctx.Value(contextTxKey{}).(*queries.Queries)
+	tx, ok := ctx.Value(contextTxKey{}).(queries.Querier)
 	if ok {
 		return tx
 	}
@@ -47,7 +49,7 @@ func (s *Data) WithWrite(ctx context.Context) queries.Querier {
 func (s *Data) InTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
 	tx, err := s.masterConn.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return translateError(err)
 	}
 
 	defer func() {
@@ -68,7 +70,7 @@ func (s *Data) InTx(ctx context.Context, fn func(ctx context.Context) error) (er
 		}
 	}()
 
-	q := queries.New(tx)
+	q := newTracedQuerier(queries.New(tx), roleMaster)
 	err = fn(context.WithValue(ctx, contextTxKey{}, q))
 	if err != nil {
 		return err // defer func will rollback
@@ -76,7 +78,7 @@ func (s *Data) InTx(ctx context.Context, fn func(ctx context.Context) error) (er
 
 	if err = tx.Commit(); err != nil {
 		s.log.Warnf("tx commit err: %+v", err)
-		return err // rollback in defer func
+		return translateError(err) // rollback in defer func
 	}
 
 	return nil
@@ -93,10 +95,9 @@ func NewData(db *DB, logger log.Logger) (*Data, func(), error) {
 	log.Info("init store")
 	return &Data{
 		masterConn:    db.master,
-		masterQueries: queries.New(db.master),
+		masterQueries: newTracedQuerier(queries.New(db.master), roleMaster),
 		slaveConn:     db.slave,
-		slaveQueries:  queries.New(db.slave),
-		Queries:       queries.New(db.slave), // default use slave
+		slaveQueries:  newTracedQuerier(queries.New(db.slave), roleSlave),
 		log:           log,
 	}, func() {}, nil
 }
@@ -106,10 +107,15 @@ type DB struct {
 	slave  *sql.DB
 }
 
-func NewDB(cfg *conf.DB, logger log.Logger) (*DB, func(), error) {
+// NewDB opens the master and slave pools, after applying pending migrations when db.auto_migrate is set
+func NewDB(cfg *conf.DB, rdb redis.UniversalClient, logger log.Logger) (*DB, func(), error) {
 	log := log.NewHelper(log.With(logger, "module", "db"))
 	log.Info("init db")
 
+	if err := AutoMigrate(context.Background(), cfg, rdb, logger); err != nil {
+		return nil, nil, err
+	}
+
 	masterCfg := cfg.Master
 	master, err := sql.Open(masterCfg.Driver, masterCfg.Dsn)
 	if err != nil {
@@ -151,6 +157,12 @@ func NewDB(cfg *conf.DB, logger log.Logger) (*DB, func(), error) {
 		time.Duration(slaveCfg.MaxLifetimeConn) * time.Second,
 	)
 
+	for name, db := range map[string]*sql.DB{roleMaster: master, roleSlave: slave} {
+		if err := metrics.RegisterDB(db, name); err != nil {
+			log.Warnf("register %s db metrics failed, err: %+v", name, err)
+		}
+	}
+
 	cleanup := func() {
 		log.Info("closing the db connections")
 		if err := master.Close(); err != nil {
@@ -167,6 +179,35 @@ func NewDB(cfg *conf.DB, logger log.Logger) (*DB, func(), error) {
 	}, cleanup, nil
 }
 
+var _ conf.Reloadable = (*DB)(nil)
+
+func (d *DB) Validate(c *conf.Config) error {
+	for name, cfg := range map[string]*conf.DbConfig{roleMaster: c.GetDb().GetMaster(), roleSlave: c.GetDb().GetSlave()} {
+		if cfg.GetMaxOpenConn() < 0 || cfg.GetMaxIdleConn() < 0 || cfg.GetMaxLifetimeConn() < 0 {
+			return errors.Errorf("%s db pool sizes must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// Apply resizes the connection pools, a changed dsn or driver needs a restart
+func (d *DB) Apply(old, new *conf.Config) {
+	setPool(d.master, old.GetDb().GetMaster(), new.GetDb().GetMaster())
+	setPool(d.slave, old.GetDb().GetSlave(), new.GetDb().GetSlave())
+}
+
+func setPool(db *sql.DB, old, new *conf.DbConfig) {
+	if old.GetMaxOpenConn() != new.GetMaxOpenConn() {
+		db.SetMaxOpenConns(int(new.GetMaxOpenConn()))
+	}
+	if old.GetMaxIdleConn() != new.GetMaxIdleConn() {
+		db.SetMaxIdleConns(int(new.GetMaxIdleConn()))
+	}
+	if old.GetMaxLifetimeConn() != new.GetMaxLifetimeConn() {
+		db.SetConnMaxLifetime(time.Duration(new.GetMaxLifetimeConn()) * time.Second)
+	}
+}
+
 func NewRedis(cfg *conf.Redis) (redis.UniversalClient, func(), error) {
 	// init redis
 	var tlsCfg *tls.Config
@@ -182,6 +223,7 @@ func NewRedis(cfg *conf.Redis) (redis.UniversalClient, func(), error) {
 		TLSConfig:  tlsCfg,
 		DB:         int(cfg.Db),
 	})
+	cli.AddHook(tracer.RedisHook{})
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(10))
 	_, err := cli.Ping(ctx).Result()
@@ -191,6 +233,10 @@ func NewRedis(cfg *conf.Redis) (redis.UniversalClient, func(), error) {
 		return nil, nil, err
 	}
 
+	if err := metrics.RegisterRedis(cli); err != nil {
+		log.Warnf("register redis metrics failed, err: %+v", err)
+	}
+
 	log.Info("redis initialized")
 	cleanup := func() {
 		if err := cli.Close(); err != nil {
@@ -199,3 +245,14 @@ func NewRedis(cfg *conf.Redis) (redis.UniversalClient, func(), error) {
 	}
 	return cli, cleanup, nil
 }
+
+// NewHealthCheckers are the dependencies readiness depends on
+func NewHealthCheckers(db *DB, rdb redis.UniversalClient) []health.Checker {
+	return []health.Checker{
+		health.NewChecker("mysql_"+roleMaster, db.master.PingContext),
+		health.NewChecker("mysql_"+roleSlave, db.slave.PingContext),
+		health.NewChecker("redis", func(ctx context.Context) error {
+			return rdb.Ping(ctx).Err()
+		}),
+	}
+}
@@ -2,6 +2,46 @@ package data
 
 import (
 	"database/sql"
+
+	pb "server-template/api/server"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
 )
 
 var ErrRecordNotFound = sql.ErrNoRows
+
+// mysql server error numbers, see https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+const (
+	mysqlErrLockWaitTimeout = 1205
+	mysqlErrDeadlock        = 1213
+	mysqlErrDupEntry        = 1062
+	mysqlErrRowIsReferenced = 1451
+	mysqlErrNoReferencedRow = 1452
+)
+
+// translateError turns database driver errors into typed api errors, the original error is kept as cause.
+// Errors it doesn't know are returned as is.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, ErrRecordNotFound) {
+		return pb.ErrorRecordNotFound("record not found").WithCause(err)
+	}
+
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return err
+	}
+	switch myErr.Number {
+	case mysqlErrDupEntry:
+		return pb.ErrorAlreadyExists("record already exists").WithCause(err)
+	case mysqlErrRowIsReferenced, mysqlErrNoReferencedRow:
+		return pb.ErrorConflict("foreign key constraint fails").WithCause(err)
+	case mysqlErrDeadlock, mysqlErrLockWaitTimeout:
+		return pb.ErrorRetryable("transaction aborted, please retry").WithCause(err)
+	}
+	return err
+}
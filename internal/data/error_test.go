@@ -0,0 +1,67 @@
+package data
+
+import (
+	"database/sql"
+	"testing"
+
+	pb "server-template/api/server"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-sql-driver/mysql"
+	pkgErrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslateError(t *testing.T) {
+	unknown := &mysql.MySQLError{Number: 1064, Message: "syntax error"}
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   int32
+		wantReason string
+	}{
+		{
+			name:       "no rows",
+			err:        sql.ErrNoRows,
+			wantCode:   404,
+			wantReason: pb.ErrorReason_RECORD_NOT_FOUND.String(),
+		},
+		{
+			name:       "duplicate entry",
+			err:        &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"},
+			wantCode:   409,
+			wantReason: pb.ErrorReason_ALREADY_EXISTS.String(),
+		},
+		{
+			name:       "wrapped foreign key",
+			err:        pkgErrors.Wrap(&mysql.MySQLError{Number: 1452}, "insert"),
+			wantCode:   409,
+			wantReason: pb.ErrorReason_CONFLICT.String(),
+		},
+		{
+			name:       "deadlock",
+			err:        &mysql.MySQLError{Number: 1213},
+			wantCode:   503,
+			wantReason: pb.ErrorReason_RETRYABLE.String(),
+		},
+		{
+			name:       "lock wait timeout",
+			err:        &mysql.MySQLError{Number: 1205},
+			wantCode:   503,
+			wantReason: pb.ErrorReason_RETRYABLE.String(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateError(tt.err)
+			se := errors.FromError(err)
+			require.Equal(t, tt.wantCode, se.Code)
+			require.Equal(t, tt.wantReason, se.Reason)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	require.Nil(t, translateError(nil))
+	require.Equal(t, unknown, translateError(unknown))
+}
@@ -0,0 +1,38 @@
+package data
+
+import (
+	"context"
+
+	"server-template/internal/biz"
+	"server-template/internal/data/queries"
+)
+
+var _ biz.Fencer = (*lockFenceRepo)(nil)
+
+type lockFenceRepo struct {
+	data *Data
+}
+
+// NewFencer keeps the fencing tokens in the lock_fence table, one row per lock
+func NewFencer(data *Data) biz.Fencer {
+	return &lockFenceRepo{data: data}
+}
+
+// Fence locks the row of lock and raises its fence to token, a lower token matches no row.
+// The row stays locked until the transaction of ctx ends, so a stale holder waits for the newer one.
+// Should every token be rejected, eg: redis lost the fence key and its clock was behind, delete the
+// row of the lock, the next holder starts it again.
+func (r *lockFenceRepo) Fence(ctx context.Context, lock string, token int64) error {
+	q := r.data.WithWrite(ctx)
+	if _, err := q.CreateLockFence(ctx, lock); err != nil {
+		return translateError(err)
+	}
+	rows, err := q.AdvanceLockFence(ctx, queries.AdvanceLockFenceParams{Fence: token, Name: lock})
+	if err != nil {
+		return translateError(err)
+	}
+	if rows == 0 {
+		return biz.ErrStaleFence
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"server-template/internal/conf"
+	"server-template/internal/data/migration"
+	lock "server-template/pkg/lock"
+
+	"github.com/go-kratos/kratos/v2/log"
+	redis "github.com/go-redis/redis/v8"
+	"github.com/go-sql-driver/mysql"
+	"github.com/golang-migrate/migrate/v4"
+	migratemysql "github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/pkg/errors"
+)
+
+const (
+	autoMigrateLockKey = "lock:db:migrate"
+	// autoMigrateWait bounds how long a replica waits for another one to finish migrating
+	autoMigrateWait     = time.Minute * 10
+	autoMigrateLockPoll = time.Second
+)
+
+// MigrationStatus is one embedded migration and whether the database has it
+type MigrationStatus struct {
+	Version    uint
+	Identifier string
+	Applied    bool
+}
+
+// Migrator runs the embedded migrations on the master db, versions are kept in schema_migrations
+// like the migrate cli does, so databases migrated by hand keep working
+type Migrator struct {
+	m   *migrate.Migrate
+	src source.Driver
+	db  *sql.DB
+	log *log.Helper
+}
+
+// NewMigrator opens a dedicated connection to the master db with multi statements enabled, close it when done
+func NewMigrator(cfg *conf.DbConfig, logger log.Logger) (*Migrator, error) {
+	dsn, err := mysql.ParseDSN(cfg.Dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse master dsn failed")
+	}
+	dsn.MultiStatements = true
+	db, err := sql.Open(cfg.Driver, dsn.FormatDSN())
+	if err != nil {
+		return nil, errors.Wrapf(err, "open master db failed, driver: %s", cfg.Driver)
+	}
+	driver, err := migratemysql.WithInstance(db, &migratemysql.Config{})
+	if err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "init migration driver failed")
+	}
+	src, err := iofs.New(migration.FS, ".")
+	if err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "read embedded migrations failed")
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "mysql", driver)
+	if err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "init migrate failed")
+	}
+	helper := log.NewHelper(log.With(logger, "module", "data/migrate"))
+	m.Log = migrateLogger{helper}
+	return &Migrator{m: m, src: src, db: db, log: helper}, nil
+}
+
+// Up applies every pending migration
+func (m *Migrator) Up() error {
+	return m.run("up", m.m.Up())
+}
+
+// Down reverts the last n applied migrations
+func (m *Migrator) Down(n int) error {
+	if n <= 0 {
+		return errors.Errorf("down steps must be positive, got %d", n)
+	}
+	return m.run("down", m.m.Steps(-n))
+}
+
+// Force sets the version without running any migration and clears the dirty flag,
+// after a failed migration has been fixed by hand. -1 means no migration is applied.
+func (m *Migrator) Force(version int) error {
+	return m.run("force", m.m.Force(version))
+}
+
+// Version returns the applied version, 0 when none is applied
+func (m *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, errors.Wrap(err, "read migration version failed")
+}
+
+// Status lists the embedded migrations, the ones up to the applied version are applied
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	current, _, err := m.Version()
+	if err != nil {
+		return nil, err
+	}
+	var list []MigrationStatus
+	version, err := m.src.First()
+	for err == nil {
+		r, identifier, rerr := m.src.ReadUp(version)
+		if rerr != nil {
+			return nil, errors.Wrapf(rerr, "read migration %d failed", version)
+		}
+		_ = r.Close()
+		list = append(list, MigrationStatus{Version: version, Identifier: identifier, Applied: version <= current})
+		version, err = m.src.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, errors.Wrap(err, "list migrations failed")
+	}
+	return list, nil
+}
+
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	if srcErr != nil {
+		return srcErr
+	}
+	return dbErr
+}
+
+func (m *Migrator) run(action string, err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		m.log.Infof("migrate %s: no change", action)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "migrate %s failed", action)
+	}
+	version, dirty, err := m.Version()
+	if err != nil {
+		return err
+	}
+	m.log.Infof("migrate %s done, version: %d, dirty: %v", action, version, dirty)
+	return nil
+}
+
+// AutoMigrate applies pending migrations at startup when db.auto_migrate is set. One replica migrates
+// while holding a redis lock, the others wait for it and then find nothing left to apply.
+func AutoMigrate(ctx context.Context, cfg *conf.DB, rdb redis.UniversalClient, logger log.Logger) error {
+	if !cfg.GetAutoMigrate() {
+		return nil
+	}
+	unlock, err := waitMigrateLock(ctx, rdb)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	m, err := NewMigrator(cfg.Master, logger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := m.Close(); err != nil {
+			m.log.Warnf("close migrator failed, err: %+v", err)
+		}
+	}()
+	return m.Up()
+}
+
+func waitMigrateLock(ctx context.Context, rdb redis.UniversalClient) (func() bool, error) {
+	locker := lock.NewLocker(rdb, lock.WithRetry(lock.FixedRetry(autoMigrateLockPoll)), lock.WithMaxWait(autoMigrateWait))
+	lease, err := locker.Acquire(ctx, autoMigrateLockKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "wait for migrate lock failed")
+	}
+	return func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		ok, _ := lease.Release(ctx)
+		return ok
+	}, nil
+}
+
+type migrateLogger struct {
+	log *log.Helper
+}
+
+func (l migrateLogger) Printf(format string, v ...any) {
+	l.log.Info(fmt.Sprintf(format, v...))
+}
+
+func (l migrateLogger) Verbose() bool {
+	return false
+}
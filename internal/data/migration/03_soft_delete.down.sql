@@ -0,0 +1,8 @@
+ALTER TABLE user_detail
+    DROP INDEX idx_deleted_at,
+    DROP INDEX idx_user_id,
+    DROP COLUMN deleted_at;
+
+ALTER TABLE user
+    DROP INDEX idx_deleted_at,
+    DROP COLUMN deleted_at;
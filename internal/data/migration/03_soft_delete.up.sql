@@ -0,0 +1,8 @@
+ALTER TABLE user
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL COMMENT 'soft delete time',
+    ADD INDEX idx_deleted_at (deleted_at);
+
+ALTER TABLE user_detail
+    ADD COLUMN deleted_at TIMESTAMP NULL DEFAULT NULL COMMENT 'soft delete time',
+    ADD INDEX idx_user_id (user_id),
+    ADD INDEX idx_deleted_at (deleted_at);
\ No newline at end of file
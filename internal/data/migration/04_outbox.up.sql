@@ -0,0 +1,11 @@
+CREATE TABLE outbox (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    topic VARCHAR(255) NOT NULL COMMENT 'event type, eg: user.created',
+    aggregate_id VARCHAR(64) NOT NULL DEFAULT '' COMMENT 'id of the entity the event is about',
+    payload JSON NOT NULL COMMENT 'event body',
+    attempts INT NOT NULL DEFAULT 0 COMMENT 'failed deliveries',
+    last_error VARCHAR(1024) NOT NULL DEFAULT '' COMMENT 'error of the last failed delivery',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP NULL DEFAULT NULL COMMENT 'published to the sink',
+    INDEX idx_delivered_at (delivered_at)
+)ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='events waiting to be published';
@@ -0,0 +1,6 @@
+CREATE TABLE lock_fence (
+    name VARCHAR(255) PRIMARY KEY COMMENT 'key of the distributed lock',
+    fence BIGINT NOT NULL DEFAULT 0 COMMENT 'highest fencing token that wrote',
+    writes BIGINT NOT NULL DEFAULT 0 COMMENT 'fenced writes, it always changes so an accepted token counts as an affected row',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+)ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='fencing tokens of the locks guarding writes';
@@ -0,0 +1,4 @@
+ALTER TABLE user_detail
+    DROP INDEX uk_live_email,
+    DROP COLUMN live_email,
+    ADD UNIQUE INDEX email (email);
@@ -0,0 +1,5 @@
+-- only live rows hold their email, so a soft deleted user doesn't block or leak it to new sign ups
+ALTER TABLE user_detail
+    DROP INDEX email,
+    ADD COLUMN live_email VARCHAR(255) AS (IF(deleted_at IS NULL, email, NULL)) STORED COMMENT 'email of a not deleted row',
+    ADD UNIQUE INDEX uk_live_email (live_email);
@@ -0,0 +1,9 @@
+// Package migration embeds the schema migrations, create new ones with: make new_migration name=xxx
+package migration
+
+import "embed"
+
+// FS holds the NN_name.up.sql and NN_name.down.sql files
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,110 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"server-template/internal/biz"
+	"server-template/internal/conf"
+	"server-template/internal/data/queries"
+	"server-template/pkg/outbox"
+
+	"github.com/pkg/errors"
+)
+
+// maxLastErrorLen is the size of outbox.last_error
+const maxLastErrorLen = 1024
+
+var (
+	_ biz.EventPublisher = (*outboxRepo)(nil)
+	_ outbox.Store       = (*outboxRepo)(nil)
+)
+
+type outboxRepo struct {
+	data *Data
+}
+
+// NewEventPublisher writes events to the outbox table, in the transaction of ctx when there is one.
+// Events are dropped when the outbox is disabled, nothing would relay or purge them.
+func NewEventPublisher(c *conf.Outbox, data *Data) biz.EventPublisher {
+	if !c.GetEnable() {
+		return nopPublisher{}
+	}
+	return &outboxRepo{data: data}
+}
+
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(ctx context.Context, events ...biz.Event) error {
+	return nil
+}
+
+// NewOutboxStore is the outbox table read by the relay, it always uses the master
+func NewOutboxStore(data *Data) outbox.Store {
+	return &outboxRepo{data: data}
+}
+
+func (o *outboxRepo) Publish(ctx context.Context, events ...biz.Event) error {
+	q := o.data.WithWrite(ctx)
+	for _, e := range events {
+		payload, err := json.Marshal(e.Payload)
+		if err != nil {
+			return errors.Wrapf(err, "marshal %s event failed", e.Topic)
+		}
+		_, err = q.CreateOutbox(ctx, queries.CreateOutboxParams{
+			Topic:       e.Topic,
+			AggregateID: e.AggregateID,
+			Payload:     payload,
+		})
+		if err != nil {
+			return translateError(err)
+		}
+	}
+	return nil
+}
+
+func (o *outboxRepo) Pending(ctx context.Context, limit int32) ([]*outbox.Message, error) {
+	rows, err := o.data.WithWrite(ctx).ListPendingOutbox(ctx, limit)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	msgs := make([]*outbox.Message, 0, len(rows))
+	for _, row := range rows {
+		msgs = append(msgs, &outbox.Message{
+			ID:          row.ID,
+			Topic:       row.Topic,
+			AggregateID: row.AggregateID,
+			Payload:     row.Payload,
+			Attempts:    row.Attempts,
+			CreatedAt:   row.CreatedAt,
+		})
+	}
+	return msgs, nil
+}
+
+func (o *outboxRepo) Delivered(ctx context.Context, id int64) error {
+	_, err := o.data.WithWrite(ctx).MarkOutboxDelivered(ctx, id)
+	return translateError(err)
+}
+
+func (o *outboxRepo) Failed(ctx context.Context, id int64, cause error) error {
+	msg := cause.Error()
+	if len(msg) > maxLastErrorLen {
+		msg = msg[:maxLastErrorLen]
+	}
+	_, err := o.data.WithWrite(ctx).MarkOutboxFailed(ctx, queries.MarkOutboxFailedParams{
+		LastError: msg,
+		ID:        id,
+	})
+	return translateError(err)
+}
+
+func (o *outboxRepo) Purge(ctx context.Context, before time.Time, limit int32) (int64, error) {
+	rows, err := o.data.WithWrite(ctx).PurgeDeliveredOutbox(ctx, queries.PurgeDeliveredOutboxParams{
+		DeliveredBefore: sql.NullTime{Time: before, Valid: true},
+		Limit:           limit,
+	})
+	return rows, translateError(err)
+}
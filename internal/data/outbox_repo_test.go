@@ -0,0 +1,18 @@
+package data
+
+import (
+	"context"
+	"testing"
+
+	"server-template/internal/biz"
+	"server-template/internal/conf"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEventPublisherDisabled(t *testing.T) {
+	// a nil Data would panic on the first write
+	p := NewEventPublisher(&conf.Outbox{}, nil)
+	require.NoError(t, p.Publish(context.Background(), biz.Event{Topic: biz.TopicUserCreated}))
+	require.IsType(t, &outboxRepo{}, NewEventPublisher(&conf.Outbox{Enable: true}, nil))
+}
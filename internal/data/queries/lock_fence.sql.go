@@ -0,0 +1,40 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: lock_fence.sql
+
+package queries
+
+import (
+	"context"
+)
+
+const advanceLockFence = `-- name: AdvanceLockFence :execrows
+UPDATE lock_fence SET fence = ?, writes = writes + 1
+WHERE name = ? AND fence <= ?
+`
+
+type AdvanceLockFenceParams struct {
+	Fence int64  `json:"fence"`
+	Name  string `json:"name"`
+}
+
+func (q *Queries) AdvanceLockFence(ctx context.Context, arg AdvanceLockFenceParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, advanceLockFence, arg.Fence, arg.Name, arg.Fence)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createLockFence = `-- name: CreateLockFence :execrows
+INSERT IGNORE INTO lock_fence (name) VALUES (?)
+`
+
+func (q *Queries) CreateLockFence(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createLockFence, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -5,9 +5,40 @@
 package queries
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+// fencing tokens of the locks guarding writes
+type LockFence struct {
+	// key of the distributed lock
+	Name string `json:"name"`
+	// highest fencing token that wrote
+	Fence int64 `json:"fence"`
+	// fenced writes, it always changes so an accepted token counts as an affected row
+	Writes    int64     `json:"writes"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// events waiting to be published
+type Outbox struct {
+	ID int64 `json:"id"`
+	// event type, eg: user.created
+	Topic string `json:"topic"`
+	// id of the entity the event is about
+	AggregateID string `json:"aggregate_id"`
+	// event body
+	Payload json.RawMessage `json:"payload"`
+	// failed deliveries
+	Attempts int32 `json:"attempts"`
+	// error of the last failed delivery
+	LastError string    `json:"last_error"`
+	CreatedAt time.Time `json:"created_at"`
+	// published to the sink
+	DeliveredAt sql.NullTime `json:"delivered_at"`
+}
+
 // user
 type User struct {
 	ID int64 `json:"id"`
@@ -17,6 +48,8 @@ type User struct {
 	Country   string    `json:"country"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// soft delete time
+	DeletedAt sql.NullTime `json:"deleted_at"`
 }
 
 // user detail
@@ -27,4 +60,8 @@ type UserDetail struct {
 	Email     string    `json:"email"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// soft delete time
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	// email of a not deleted row
+	LiveEmail sql.NullString `json:"live_email"`
 }
@@ -0,0 +1,124 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.29.0
+// source: outbox.sql
+
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const createOutbox = `-- name: CreateOutbox :execlastid
+INSERT INTO outbox (topic, aggregate_id, payload) VALUES (?, ?, ?)
+`
+
+type CreateOutboxParams struct {
+	Topic       string          `json:"topic"`
+	AggregateID string          `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+func (q *Queries) CreateOutbox(ctx context.Context, arg CreateOutboxParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createOutbox, arg.Topic, arg.AggregateID, arg.Payload)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const listPendingOutbox = `-- name: ListPendingOutbox :many
+SELECT id, topic, aggregate_id, payload, attempts, created_at
+FROM outbox
+WHERE delivered_at IS NULL
+ORDER BY id
+LIMIT ?
+`
+
+type ListPendingOutboxRow struct {
+	ID          int64           `json:"id"`
+	Topic       string          `json:"topic"`
+	AggregateID string          `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempts    int32           `json:"attempts"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+func (q *Queries) ListPendingOutbox(ctx context.Context, limit int32) ([]ListPendingOutboxRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingOutbox, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPendingOutboxRow{}
+	for rows.Next() {
+		var i ListPendingOutboxRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Topic,
+			&i.AggregateID,
+			&i.Payload,
+			&i.Attempts,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markOutboxDelivered = `-- name: MarkOutboxDelivered :execrows
+UPDATE outbox SET delivered_at = CURRENT_TIMESTAMP WHERE id = ?
+`
+
+func (q *Queries) MarkOutboxDelivered(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markOutboxDelivered, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markOutboxFailed = `-- name: MarkOutboxFailed :execrows
+UPDATE outbox SET attempts = attempts + 1, last_error = ? WHERE id = ?
+`
+
+type MarkOutboxFailedParams struct {
+	LastError string `json:"last_error"`
+	ID        int64  `json:"id"`
+}
+
+func (q *Queries) MarkOutboxFailed(ctx context.Context, arg MarkOutboxFailedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markOutboxFailed, arg.LastError, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeDeliveredOutbox = `-- name: PurgeDeliveredOutbox :execrows
+DELETE FROM outbox WHERE delivered_at IS NOT NULL AND delivered_at < ? LIMIT ?
+`
+
+type PurgeDeliveredOutboxParams struct {
+	DeliveredBefore sql.NullTime `json:"delivered_before"`
+	Limit           int32        `json:"limit"`
+}
+
+func (q *Queries) PurgeDeliveredOutbox(ctx context.Context, arg PurgeDeliveredOutboxParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeliveredOutbox, arg.DeliveredBefore, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
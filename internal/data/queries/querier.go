@@ -9,10 +9,27 @@ import (
 )
 
 type Querier interface {
+	AdvanceLockFence(ctx context.Context, arg AdvanceLockFenceParams) (int64, error)
+	CreateLockFence(ctx context.Context, name string) (int64, error)
+	CreateOutbox(ctx context.Context, arg CreateOutboxParams) (int64, error)
 	CreateUser(ctx context.Context, name string) (int64, error)
 	CreateUserDetail(ctx context.Context, arg CreateUserDetailParams) (int64, error)
+	GetUser(ctx context.Context, id int64) (GetUserRow, error)
+	GetUserForUpdate(ctx context.Context, id int64) (GetUserForUpdateRow, error)
+	ListPendingOutbox(ctx context.Context, limit int32) ([]ListPendingOutboxRow, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error)
+	MarkOutboxDelivered(ctx context.Context, id int64) (int64, error)
+	MarkOutboxFailed(ctx context.Context, arg MarkOutboxFailedParams) (int64, error)
+	PurgeDeliveredOutbox(ctx context.Context, arg PurgeDeliveredOutboxParams) (int64, error)
+	PurgeUserDetails(ctx context.Context, arg PurgeUserDetailsParams) (int64, error)
+	PurgeUsers(ctx context.Context, arg PurgeUsersParams) (int64, error)
 	QueryUsers(ctx context.Context) ([]User, error)
+	RestoreUser(ctx context.Context, id int64) (int64, error)
+	RestoreUserDetail(ctx context.Context, userID int64) (int64, error)
+	SoftDeleteUser(ctx context.Context, id int64) (int64, error)
+	SoftDeleteUserDetail(ctx context.Context, userID int64) (int64, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (int64, error)
+	UpdateUserDetailEmail(ctx context.Context, arg UpdateUserDetailEmailParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -7,6 +7,8 @@ package queries
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createUser = `-- name: CreateUser :execlastid
@@ -21,8 +23,139 @@ func (q *Queries) CreateUser(ctx context.Context, name string) (int64, error) {
 	return result.LastInsertId()
 }
 
+const getUser = `-- name: GetUser :one
+SELECT u.id, u.name, u.country, d.email, u.created_at, u.updated_at
+FROM user u
+LEFT JOIN user_detail d ON d.user_id = u.id AND d.deleted_at IS NULL
+WHERE u.id = ? AND u.deleted_at IS NULL
+`
+
+type GetUserRow struct {
+	ID        int64          `json:"id"`
+	Name      string         `json:"name"`
+	Country   string         `json:"country"`
+	Email     sql.NullString `json:"email"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func (q *Queries) GetUser(ctx context.Context, id int64) (GetUserRow, error) {
+	row := q.db.QueryRowContext(ctx, getUser, id)
+	var i GetUserRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Country,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserForUpdate = `-- name: GetUserForUpdate :one
+SELECT u.id, u.name, u.country, d.email, u.created_at, u.updated_at
+FROM user u
+LEFT JOIN user_detail d ON d.user_id = u.id AND d.deleted_at IS NULL
+WHERE u.id = ? AND u.deleted_at IS NULL
+FOR UPDATE
+`
+
+type GetUserForUpdateRow struct {
+	ID        int64          `json:"id"`
+	Name      string         `json:"name"`
+	Country   string         `json:"country"`
+	Email     sql.NullString `json:"email"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func (q *Queries) GetUserForUpdate(ctx context.Context, id int64) (GetUserForUpdateRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserForUpdate, id)
+	var i GetUserForUpdateRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Country,
+		&i.Email,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT u.id, u.name, u.country, d.email, u.created_at, u.updated_at
+FROM user u
+LEFT JOIN user_detail d ON d.user_id = u.id AND d.deleted_at IS NULL
+WHERE u.id > ? AND u.deleted_at IS NULL
+ORDER BY u.id
+LIMIT ?
+`
+
+type ListUsersParams struct {
+	AfterID int64 `json:"after_id"`
+	Limit   int32 `json:"limit"`
+}
+
+type ListUsersRow struct {
+	ID        int64          `json:"id"`
+	Name      string         `json:"name"`
+	Country   string         `json:"country"`
+	Email     sql.NullString `json:"email"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersRow{}
+	for rows.Next() {
+		var i ListUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Country,
+			&i.Email,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeUsers = `-- name: PurgeUsers :execrows
+DELETE FROM user WHERE deleted_at IS NOT NULL AND deleted_at < ? LIMIT ?
+`
+
+type PurgeUsersParams struct {
+	DeletedBefore sql.NullTime `json:"deleted_before"`
+	Limit         int32        `json:"limit"`
+}
+
+func (q *Queries) PurgeUsers(ctx context.Context, arg PurgeUsersParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeUsers, arg.DeletedBefore, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const queryUsers = `-- name: QueryUsers :many
-SELECT id, name, country, created_at, updated_at FROM user
+SELECT id, name, country, created_at, updated_at, deleted_at FROM user WHERE deleted_at IS NULL
 `
 
 func (q *Queries) QueryUsers(ctx context.Context) ([]User, error) {
@@ -40,6 +173,7 @@ func (q *Queries) QueryUsers(ctx context.Context) ([]User, error) {
 			&i.Country,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -54,17 +188,42 @@ func (q *Queries) QueryUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
+const restoreUser = `-- name: RestoreUser :execrows
+UPDATE user SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreUser(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const softDeleteUser = `-- name: SoftDeleteUser :execrows
+UPDATE user SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteUser(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, softDeleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateUser = `-- name: UpdateUser :execrows
-UPDATE user SET country = ? WHERE name = ?
+UPDATE user SET name = ?, country = ? WHERE id = ? AND deleted_at IS NULL
 `
 
 type UpdateUserParams struct {
-	Country string `json:"country"`
 	Name    string `json:"name"`
+	Country string `json:"country"`
+	ID      int64  `json:"id"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (int64, error) {
-	result, err := q.db.ExecContext(ctx, updateUser, arg.Country, arg.Name)
+	result, err := q.db.ExecContext(ctx, updateUser, arg.Name, arg.Country, arg.ID)
 	if err != nil {
 		return 0, err
 	}
@@ -7,6 +7,7 @@ package queries
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createUserDetail = `-- name: CreateUserDetail :execlastid
@@ -25,3 +26,61 @@ func (q *Queries) CreateUserDetail(ctx context.Context, arg CreateUserDetailPara
 	}
 	return result.LastInsertId()
 }
+
+const purgeUserDetails = `-- name: PurgeUserDetails :execrows
+DELETE FROM user_detail WHERE deleted_at IS NOT NULL AND deleted_at < ? LIMIT ?
+`
+
+type PurgeUserDetailsParams struct {
+	DeletedBefore sql.NullTime `json:"deleted_before"`
+	Limit         int32        `json:"limit"`
+}
+
+func (q *Queries) PurgeUserDetails(ctx context.Context, arg PurgeUserDetailsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeUserDetails, arg.DeletedBefore, arg.Limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreUserDetail = `-- name: RestoreUserDetail :execrows
+UPDATE user_detail SET deleted_at = NULL WHERE user_id = ? AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreUserDetail(ctx context.Context, userID int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreUserDetail, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const softDeleteUserDetail = `-- name: SoftDeleteUserDetail :execrows
+UPDATE user_detail SET deleted_at = CURRENT_TIMESTAMP WHERE user_id = ? AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteUserDetail(ctx context.Context, userID int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, softDeleteUserDetail, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateUserDetailEmail = `-- name: UpdateUserDetailEmail :execrows
+UPDATE user_detail SET email = ? WHERE user_id = ? AND deleted_at IS NULL
+`
+
+type UpdateUserDetailEmailParams struct {
+	Email  string `json:"email"`
+	UserID int64  `json:"user_id"`
+}
+
+func (q *Queries) UpdateUserDetailEmail(ctx context.Context, arg UpdateUserDetailEmailParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserDetailEmail, arg.Email, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
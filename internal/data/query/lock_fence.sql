@@ -0,0 +1,6 @@
+-- name: CreateLockFence :execrows
+INSERT IGNORE INTO lock_fence (name) VALUES (?);
+
+-- name: AdvanceLockFence :execrows
+UPDATE lock_fence SET fence = sqlc.arg(fence), writes = writes + 1
+WHERE name = sqlc.arg(name) AND fence <= sqlc.arg(fence);
@@ -0,0 +1,18 @@
+-- name: CreateOutbox :execlastid
+INSERT INTO outbox (topic, aggregate_id, payload) VALUES (?, ?, ?);
+
+-- name: ListPendingOutbox :many
+SELECT id, topic, aggregate_id, payload, attempts, created_at
+FROM outbox
+WHERE delivered_at IS NULL
+ORDER BY id
+LIMIT ?;
+
+-- name: MarkOutboxDelivered :execrows
+UPDATE outbox SET delivered_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: MarkOutboxFailed :execrows
+UPDATE outbox SET attempts = attempts + 1, last_error = ? WHERE id = ?;
+
+-- name: PurgeDeliveredOutbox :execrows
+DELETE FROM outbox WHERE delivered_at IS NOT NULL AND delivered_at < sqlc.arg(delivered_before) LIMIT ?;
@@ -1,8 +1,38 @@
 -- name: QueryUsers :many
-SELECT * FROM user;
+SELECT * FROM user WHERE deleted_at IS NULL;
+
+-- name: GetUser :one
+SELECT u.id, u.name, u.country, d.email, u.created_at, u.updated_at
+FROM user u
+LEFT JOIN user_detail d ON d.user_id = u.id AND d.deleted_at IS NULL
+WHERE u.id = ? AND u.deleted_at IS NULL;
+
+-- name: GetUserForUpdate :one
+SELECT u.id, u.name, u.country, d.email, u.created_at, u.updated_at
+FROM user u
+LEFT JOIN user_detail d ON d.user_id = u.id AND d.deleted_at IS NULL
+WHERE u.id = ? AND u.deleted_at IS NULL
+FOR UPDATE;
+
+-- name: ListUsers :many
+SELECT u.id, u.name, u.country, d.email, u.created_at, u.updated_at
+FROM user u
+LEFT JOIN user_detail d ON d.user_id = u.id AND d.deleted_at IS NULL
+WHERE u.id > sqlc.arg(after_id) AND u.deleted_at IS NULL
+ORDER BY u.id
+LIMIT ?;
 
 -- name: CreateUser :execlastid
 INSERT INTO user (name) VALUES(?);
 
 -- name: UpdateUser :execrows
-UPDATE user SET country = ? WHERE name = ?;
\ No newline at end of file
+UPDATE user SET name = ?, country = ? WHERE id = ? AND deleted_at IS NULL;
+
+-- name: SoftDeleteUser :execrows
+UPDATE user SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL;
+
+-- name: RestoreUser :execrows
+UPDATE user SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL;
+
+-- name: PurgeUsers :execrows
+DELETE FROM user WHERE deleted_at IS NOT NULL AND deleted_at < sqlc.arg(deleted_before) LIMIT ?;
\ No newline at end of file
@@ -1,2 +1,14 @@
 -- name: CreateUserDetail :execlastid
-INSERT INTO user_detail (user_id,email) VALUES (?,?);
\ No newline at end of file
+INSERT INTO user_detail (user_id,email) VALUES (?,?);
+
+-- name: UpdateUserDetailEmail :execrows
+UPDATE user_detail SET email = ? WHERE user_id = ? AND deleted_at IS NULL;
+
+-- name: SoftDeleteUserDetail :execrows
+UPDATE user_detail SET deleted_at = CURRENT_TIMESTAMP WHERE user_id = ? AND deleted_at IS NULL;
+
+-- name: RestoreUserDetail :execrows
+UPDATE user_detail SET deleted_at = NULL WHERE user_id = ? AND deleted_at IS NOT NULL;
+
+-- name: PurgeUserDetails :execrows
+DELETE FROM user_detail WHERE deleted_at IS NOT NULL AND deleted_at < sqlc.arg(deleted_before) LIMIT ?;
\ No newline at end of file
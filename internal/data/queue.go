@@ -0,0 +1,13 @@
+package data
+
+import (
+	"server-template/internal/conf"
+	"server-template/pkg/queue"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// NewJobQueue enqueues the background jobs into redis streams
+func NewJobQueue(c *conf.Queue, rdb redis.UniversalClient) queue.Enqueuer {
+	return queue.NewClient(rdb, c.GetStreamPrefix())
+}
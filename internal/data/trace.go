@@ -0,0 +1,191 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+
+	"server-template/internal/data/queries"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentation = "server-template/internal/data"
+
+	roleMaster = "master"
+	roleSlave  = "slave"
+)
+
+var _ queries.Querier = (*tracedQuerier)(nil)
+
+// tracedQuerier starts a span for every statement with its sqlc name and the rows it returned or affected.
+// A new query in query/*.sql fails to compile until it is added here.
+type tracedQuerier struct {
+	q    queries.Querier
+	role string
+}
+
+func newTracedQuerier(q queries.Querier, role string) queries.Querier {
+	return &tracedQuerier{q: q, role: role}
+}
+
+// traced runs one statement in a span, rows maps its result to the row count
+func traced[T any](ctx context.Context, role, name string, rows func(T) int, fn func(context.Context) (T, error)) (T, error) {
+	ctx, span := otel.Tracer(instrumentation).Start(ctx, "sql "+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemMySQL,
+			semconv.DBOperationName(name),
+			attribute.String("db.role", role),
+		),
+	)
+	defer span.End()
+
+	res, err := fn(ctx)
+	switch {
+	case err == sql.ErrNoRows:
+		span.SetAttributes(attribute.Int("db.rows", 0))
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	default:
+		span.SetAttributes(attribute.Int("db.rows", rows(res)))
+	}
+	return res, err
+}
+
+func one[T any](T) int { return 1 }
+
+func many[T any](res []T) int { return len(res) }
+
+func affected(n int64) int { return int(n) }
+
+func (t *tracedQuerier) AdvanceLockFence(ctx context.Context, arg queries.AdvanceLockFenceParams) (int64, error) {
+	return traced(ctx, t.role, "AdvanceLockFence", affected, func(ctx context.Context) (int64, error) {
+		return t.q.AdvanceLockFence(ctx, arg)
+	})
+}
+
+func (t *tracedQuerier) CreateLockFence(ctx context.Context, name string) (int64, error) {
+	return traced(ctx, t.role, "CreateLockFence", affected, func(ctx context.Context) (int64, error) {
+		return t.q.CreateLockFence(ctx, name)
+	})
+}
+
+func (t *tracedQuerier) CreateOutbox(ctx context.Context, arg queries.CreateOutboxParams) (int64, error) {
+	return traced(ctx, t.role, "CreateOutbox", one[int64], func(ctx context.Context) (int64, error) {
+		return t.q.CreateOutbox(ctx, arg)
+	})
+}
+
+func (t *tracedQuerier) CreateUser(ctx context.Context, name string) (int64, error) {
+	return traced(ctx, t.role, "CreateUser", one[int64], func(ctx context.Context) (int64, error) {
+		return t.q.CreateUser(ctx, name)
+	})
+}
+
+func (t *tracedQuerier) CreateUserDetail(ctx context.Context, arg queries.CreateUserDetailParams) (int64, error) {
+	return traced(ctx, t.role, "CreateUserDetail", one[int64], func(ctx context.Context) (int64, error) {
+		return t.q.CreateUserDetail(ctx, arg)
+	})
+}
+
+func (t *tracedQuerier) GetUser(ctx context.Context, id int64) (queries.GetUserRow, error) {
+	return traced(ctx, t.role, "GetUser", one[queries.GetUserRow], func(ctx context.Context) (queries.GetUserRow, error) {
+		return t.q.GetUser(ctx, id)
+	})
+}
+
+func (t *tracedQuerier) GetUserForUpdate(ctx context.Context, id int64) (queries.GetUserForUpdateRow, error) {
+	return traced(ctx, t.role, "GetUserForUpdate", one[queries.GetUserForUpdateRow], func(ctx context.Context) (queries.GetUserForUpdateRow, error) {
+		return t.q.GetUserForUpdate(ctx, id)
+	})
+}
+
+func (t *tracedQuerier) ListPendingOutbox(ctx context.Context, limit int32) ([]queries.ListPendingOutboxRow, error) {
+	return traced(ctx, t.role, "ListPendingOutbox", many[queries.ListPendingOutboxRow], func(ctx context.Context) ([]queries.ListPendingOutboxRow, error) {
+		return t.q.ListPendingOutbox(ctx, limit)
+	})
+}
+
+func (t *tracedQuerier) ListUsers(ctx context.Context, arg queries.ListUsersParams) ([]queries.ListUsersRow, error) {
+	return traced(ctx, t.role, "ListUsers", many[queries.ListUsersRow], func(ctx context.Context) ([]queries.ListUsersRow, error) {
+		return t.q.ListUsers(ctx, arg)
+	})
+}
+
+func (t *tracedQuerier) MarkOutboxDelivered(ctx context.Context, id int64) (int64, error) {
+	return traced(ctx, t.role, "MarkOutboxDelivered", affected, func(ctx context.Context) (int64, error) {
+		return t.q.MarkOutboxDelivered(ctx, id)
+	})
+}
+
+func (t *tracedQuerier) MarkOutboxFailed(ctx context.Context, arg queries.MarkOutboxFailedParams) (int64, error) {
+	return traced(ctx, t.role, "MarkOutboxFailed", affected, func(ctx context.Context) (int64, error) {
+		return t.q.MarkOutboxFailed(ctx, arg)
+	})
+}
+
+func (t *tracedQuerier) PurgeDeliveredOutbox(ctx context.Context, arg queries.PurgeDeliveredOutboxParams) (int64, error) {
+	return traced(ctx, t.role, "PurgeDeliveredOutbox", affected, func(ctx context.Context) (int64, error) {
+		return t.q.PurgeDeliveredOutbox(ctx, arg)
+	})
+}
+
+func (t *tracedQuerier) PurgeUserDetails(ctx context.Context, arg queries.PurgeUserDetailsParams) (int64, error) {
+	return traced(ctx, t.role, "PurgeUserDetails", affected, func(ctx context.Context) (int64, error) {
+		return t.q.PurgeUserDetails(ctx, arg)
+	})
+}
+
+func (t *tracedQuerier) PurgeUsers(ctx context.Context, arg queries.PurgeUsersParams) (int64, error) {
+	return traced(ctx, t.role, "PurgeUsers", affected, func(ctx context.Context) (int64, error) {
+		return t.q.PurgeUsers(ctx, arg)
+	})
+}
+
+func (t *tracedQuerier) QueryUsers(ctx context.Context) ([]queries.User, error) {
+	return traced(ctx, t.role, "QueryUsers", many[queries.User], func(ctx context.Context) ([]queries.User, error) {
+		return t.q.QueryUsers(ctx)
+	})
+}
+
+func (t *tracedQuerier) RestoreUser(ctx context.Context, id int64) (int64, error) {
+	return traced(ctx, t.role, "RestoreUser", affected, func(ctx context.Context) (int64, error) {
+		return t.q.RestoreUser(ctx, id)
+	})
+}
+
+func (t *tracedQuerier) RestoreUserDetail(ctx context.Context, userID int64) (int64, error) {
+	return traced(ctx, t.role, "RestoreUserDetail", affected, func(ctx context.Context) (int64, error) {
+		return t.q.RestoreUserDetail(ctx, userID)
+	})
+}
+
+func (t *tracedQuerier) SoftDeleteUser(ctx context.Context, id int64) (int64, error) {
+	return traced(ctx, t.role, "SoftDeleteUser", affected, func(ctx context.Context) (int64, error) {
+		return t.q.SoftDeleteUser(ctx, id)
+	})
+}
+
+func (t *tracedQuerier) SoftDeleteUserDetail(ctx context.Context, userID int64) (int64, error) {
+	return traced(ctx, t.role, "SoftDeleteUserDetail", affected, func(ctx context.Context) (int64, error) {
+		return t.q.SoftDeleteUserDetail(ctx, userID)
+	})
+}
+
+func (t *tracedQuerier) UpdateUser(ctx context.Context, arg queries.UpdateUserParams) (int64, error) {
+	return traced(ctx, t.role, "UpdateUser", affected, func(ctx context.Context) (int64, error) {
+		return t.q.UpdateUser(ctx, arg)
+	})
+}
+
+func (t *tracedQuerier) UpdateUserDetailEmail(ctx context.Context, arg queries.UpdateUserDetailEmailParams) (int64, error) {
+	return traced(ctx, t.role, "UpdateUserDetailEmail", affected, func(ctx context.Context) (int64, error) {
+		return t.q.UpdateUserDetailEmail(ctx, arg)
+	})
+}
@@ -2,9 +2,14 @@ package data
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
+	pb "server-template/api/server"
 	"server-template/internal/biz"
 	"server-template/internal/data/queries"
+
+	"github.com/pkg/errors"
 )
 
 type userRepo struct {
@@ -20,7 +25,7 @@ func NewUserRepo(data *Data) biz.UserRepo {
 func (u *userRepo) CreateUser(ctx context.Context, name string) (int64, error) {
 	lastInsertId, err := u.data.WithWrite(ctx).CreateUser(ctx, name)
 	if err != nil {
-		return 0, err
+		return 0, translateError(err)
 	}
 	return lastInsertId, nil
 }
@@ -31,18 +36,149 @@ func (u *userRepo) CreateUserDetail(ctx context.Context, id int64, email string)
 		Email:  email,
 	})
 	if err != nil {
-		return 0, err
+		return 0, translateUserDetailError(err)
 	}
 	return lastInsertId, nil
 }
 
-func (u *userRepo) UpdateUserInfo(ctx context.Context, name, country string) (int64, error) {
+func (u *userRepo) UpdateUserInfo(ctx context.Context, id int64, name, country string) (int64, error) {
 	rowsAffected, err := u.data.WithWrite(ctx).UpdateUser(ctx, queries.UpdateUserParams{
 		Name:    name,
 		Country: country,
+		ID:      id,
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return rowsAffected, nil
+}
+
+func (u *userRepo) UpdateUserEmail(ctx context.Context, id int64, email string) (int64, error) {
+	rowsAffected, err := u.data.WithWrite(ctx).UpdateUserDetailEmail(ctx, queries.UpdateUserDetailEmailParams{
+		Email:  email,
+		UserID: id,
+	})
+	if err != nil {
+		return 0, translateUserDetailError(err)
+	}
+	return rowsAffected, nil
+}
+
+func (u *userRepo) GetUser(ctx context.Context, id int64) (*biz.User, error) {
+	row, err := u.data.WithRead().GetUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return nil, biz.ErrUserNotFound
+		}
+		return nil, translateError(err)
+	}
+	return &biz.User{
+		ID:        row.ID,
+		Name:      row.Name,
+		Country:   row.Country,
+		Email:     row.Email.String,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}, nil
+}
+
+func (u *userRepo) GetUserForUpdate(ctx context.Context, id int64) (*biz.User, error) {
+	row, err := u.data.WithWrite(ctx).GetUserForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return nil, biz.ErrUserNotFound
+		}
+		return nil, translateError(err)
+	}
+	return &biz.User{
+		ID:        row.ID,
+		Name:      row.Name,
+		Country:   row.Country,
+		Email:     row.Email.String,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}, nil
+}
+
+func (u *userRepo) ListUsers(ctx context.Context, afterID int64, limit int32) ([]*biz.User, error) {
+	rows, err := u.data.WithRead().ListUsers(ctx, queries.ListUsersParams{
+		AfterID: afterID,
+		Limit:   limit,
 	})
 	if err != nil {
-		return 0, err
+		return nil, translateError(err)
+	}
+	users := make([]*biz.User, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, &biz.User{
+			ID:        row.ID,
+			Name:      row.Name,
+			Country:   row.Country,
+			Email:     row.Email.String,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		})
+	}
+	return users, nil
+}
+
+func (u *userRepo) SoftDeleteUser(ctx context.Context, id int64) (int64, error) {
+	q := u.data.WithWrite(ctx)
+	rowsAffected, err := q.SoftDeleteUser(ctx, id)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	if rowsAffected == 0 {
+		return 0, nil
+	}
+	if _, err = q.SoftDeleteUserDetail(ctx, id); err != nil {
+		return 0, translateError(err)
+	}
+	return rowsAffected, nil
+}
+
+func (u *userRepo) RestoreUser(ctx context.Context, id int64) (int64, error) {
+	q := u.data.WithWrite(ctx)
+	rowsAffected, err := q.RestoreUser(ctx, id)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	if rowsAffected == 0 {
+		return 0, nil
+	}
+	if _, err = q.RestoreUserDetail(ctx, id); err != nil {
+		return 0, translateError(err)
 	}
 	return rowsAffected, nil
 }
+
+// PurgeUsers returns the larger rows affected of user and user_detail,
+// so callers keep purging until both tables are drained
+func (u *userRepo) PurgeUsers(ctx context.Context, before time.Time, limit int32) (int64, error) {
+	q := u.data.WithWrite(ctx)
+	deletedBefore := sql.NullTime{Time: before, Valid: true}
+	detailRows, err := q.PurgeUserDetails(ctx, queries.PurgeUserDetailsParams{
+		DeletedBefore: deletedBefore,
+		Limit:         limit,
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+	userRows, err := q.PurgeUsers(ctx, queries.PurgeUsersParams{
+		DeletedBefore: deletedBefore,
+		Limit:         limit,
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return max(detailRows, userRows), nil
+}
+
+// translateUserDetailError reports a duplicate email as user already exists
+func translateUserDetailError(err error) error {
+	err = translateError(err)
+	if pb.IsAlreadyExists(err) {
+		return biz.ErrUserAlreadyExists.WithCause(err)
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	nethttp "net/http"
+	"strings"
+
+	"server-template/internal/conf"
+	pkgLog "server-template/pkg/log"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+const (
+	logLevelPath = "/log/level"
+	bearerPrefix = "Bearer "
+)
+
+var _ transport.Server = (*AdminServer)(nil)
+
+// AdminServer serves operational endpoints on a separate listener that should not be exposed publicly,
+// every request needs the configured bearer token
+type AdminServer struct {
+	srv    *http.Server // nil when server.admin.addr is unset
+	levels *pkgLog.LevelHandler
+	log    *log.Helper
+}
+
+// NewAdminServer new an admin server, it serves the log levels of the default logger
+func NewAdminServer(c *conf.Server, logger log.Logger) *AdminServer {
+	s := &AdminServer{
+		levels: pkgLog.NewLevelHandler(pkgLog.Default()),
+		log:    log.NewHelper(log.With(logger, "module", "server/admin")),
+	}
+	if c.GetAdmin().GetAddr() == "" {
+		return s
+	}
+	s.srv = http.NewServer(
+		http.Address(c.Admin.Addr),
+		http.Filter(s.authenticate(c.Admin.Token)),
+	)
+	s.Handle(logLevelPath, s.levels)
+	return s
+}
+
+// LevelHandler returns the handler that changes the log levels at runtime
+func (s *AdminServer) LevelHandler() *pkgLog.LevelHandler {
+	return s.levels
+}
+
+// Handle registers an admin endpoint, it is a no-op when the admin listener is disabled
+func (s *AdminServer) Handle(path string, h nethttp.Handler) {
+	if s.srv != nil {
+		s.srv.Handle(path, h)
+	}
+}
+
+func (s *AdminServer) Start(ctx context.Context) error {
+	if s.srv == nil {
+		s.log.Info("admin server disabled")
+		return nil
+	}
+	return s.srv.Start(ctx)
+}
+
+func (s *AdminServer) Stop(ctx context.Context) error {
+	if s.srv == nil {
+		return nil
+	}
+	return s.srv.Stop(ctx)
+}
+
+func (s *AdminServer) authenticate(token string) http.FilterFunc {
+	return func(next nethttp.Handler) nethttp.Handler {
+		return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), bearerPrefix)
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				s.log.Warnf("admin request rejected, remote: %s, path: %s", r.RemoteAddr, r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(nethttp.StatusUnauthorized)
+				_ = json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
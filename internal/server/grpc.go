@@ -5,21 +5,13 @@ import (
 	"server-template/internal/conf"
 	"server-template/internal/service"
 
-	"github.com/go-kratos/kratos/v2/log"
-	"github.com/go-kratos/kratos/v2/middleware/logging"
-	"github.com/go-kratos/kratos/v2/middleware/metadata"
-	"github.com/go-kratos/kratos/v2/middleware/recovery"
 	"github.com/go-kratos/kratos/v2/transport/grpc"
 )
 
 // NewGRPCServer new a gRPC server.
-func NewGRPCServer(c *conf.Server, serverSvc *service.ServerService, logger log.Logger) *grpc.Server {
+func NewGRPCServer(c *conf.Server, mws Middlewares, serverSvc *service.ServerService) *grpc.Server {
 	opts := []grpc.ServerOption{
-		grpc.Middleware(
-			recovery.Recovery(),
-			metadata.Server(),
-			logging.Server(logger),
-		),
+		grpc.Middleware(mws...),
 	}
 	if c.Grpc.Network != "" {
 		opts = append(opts, grpc.Network(c.Grpc.Network))
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"server-template/internal/conf"
+	"server-template/pkg/health"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	livenessPath  = "/healthz"
+	readinessPath = "/readyz"
+)
+
+// Health serves /healthz and /readyz on the http server
+type Health struct {
+	*health.Health
+	shutdownDelay time.Duration
+	log           *log.Helper
+}
+
+// NewHealth new the probes with the checkers of every component.
+func NewHealth(c *conf.Server, checkers []health.Checker, logger log.Logger) *Health {
+	var opts []health.Option
+	if c.GetHealth().GetTimeout() != nil {
+		opts = append(opts, health.WithTimeout(c.Health.Timeout.AsDuration()))
+	}
+	if c.GetHealth().GetCacheTtl() != nil {
+		opts = append(opts, health.WithCacheTTL(c.Health.CacheTtl.AsDuration()))
+	}
+	h := health.New(opts...)
+	h.Register(checkers...)
+	return &Health{
+		Health:        h,
+		shutdownDelay: c.GetHealth().GetShutdownDelay().AsDuration(),
+		log:           log.NewHelper(log.With(logger, "module", "server/health")),
+	}
+}
+
+// BeforeStop reports not ready and waits for the load balancer to notice, kratos.App stops the listeners after it
+func (h *Health) BeforeStop(ctx context.Context) error {
+	h.Shutdown()
+	if h.shutdownDelay <= 0 {
+		return nil
+	}
+	h.log.Infof("not ready, stop in %s", h.shutdownDelay)
+	select {
+	case <-ctx.Done():
+	case <-time.After(h.shutdownDelay):
+	}
+	return nil
+}
@@ -1,56 +1,29 @@
 package server
 
 import (
-	"context"
-
 	"server-template/api/server"
 	"server-template/internal/conf"
 	"server-template/internal/service"
-	"server-template/pkg/middleware"
-
-	"server-template/pkg/middleware/validate"
+	"server-template/pkg/metrics"
 
-	"github.com/go-kratos/kratos/v2/log"
-	"github.com/go-kratos/kratos/v2/middleware/logging"
-	"github.com/go-kratos/kratos/v2/middleware/metadata"
-	"github.com/go-kratos/kratos/v2/middleware/ratelimit"
-	"github.com/go-kratos/kratos/v2/middleware/recovery"
-	"github.com/go-kratos/kratos/v2/middleware/selector"
 	"github.com/go-kratos/kratos/v2/transport/http"
 	"github.com/gorilla/handlers"
 )
 
-func NewWhiteListMatcher(c *conf.Server) selector.MatchFunc {
-	whiteList := make(map[string]struct{})
-	for _, v := range c.UnLoggingOp {
-		whiteList[v] = struct{}{}
-	}
-	return func(ctx context.Context, operation string) bool {
-		if _, ok := whiteList[operation]; ok {
-			return false
-		}
-		return true
-	}
-}
+const defaultMetricsPath = "/metrics"
 
 // NewHTTPServer new an HTTP server.
-func NewHTTPServer(c *conf.Server, serverSvc *service.ServerService, logger log.Logger) *http.Server {
+func NewHTTPServer(c *conf.Server, mws Middlewares, h *Health, serverSvc *service.ServerService) *http.Server {
 	opts := []http.ServerOption{
-		http.Middleware(
-			recovery.Recovery(),
-			ratelimit.Server(),
-			metadata.Server(),
-			middleware.RequestIdHandler,
-			selector.Server(logging.Server(logger)).Match(NewWhiteListMatcher(c)).Build(),
-			validate.ProtoValidate(),
-		),
+		http.Middleware(mws...),
 		http.Filter(handlers.CORS(
-			handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"}),
-			handlers.AllowedMethods([]string{"GET", "POST", "PUT", "HEAD", "OPTIONS"}),
+			handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization", "X-API-Key", "Idempotency-Key"}),
+			handlers.AllowedMethods([]string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}),
 			handlers.AllowedOrigins([]string{"*"}),
+			handlers.ExposedHeaders([]string{"X-Request-ID", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "Idempotent-Replayed"}),
 		)),
 		http.ResponseEncoder(ResponseEncoder),
-		http.ErrorEncoder(ErrorEncoder),
+		http.ErrorEncoder(NewErrorEncoder(c.Http)),
 		http.RequestDecoder(RequestDecoder),
 	}
 	if c.Http.Network != "" {
@@ -64,5 +37,14 @@ func NewHTTPServer(c *conf.Server, serverSvc *service.ServerService, logger log.
 	}
 	srv := http.NewServer(opts...)
 	server.RegisterServerHTTPServer(srv, serverSvc)
+	srv.Handle(livenessPath, h.LivenessHandler())
+	srv.Handle(readinessPath, h.ReadinessHandler())
+	if c.GetMetrics().GetEnable() {
+		path := c.Metrics.Path
+		if path == "" {
+			path = defaultMetricsPath
+		}
+		srv.Handle(path, metrics.Handler())
+	}
 	return srv
 }
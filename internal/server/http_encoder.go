@@ -8,6 +8,9 @@ import (
 	"net/http"
 	"strings"
 
+	"server-template/internal/conf"
+	"server-template/pkg/middleware/validate"
+
 	"github.com/go-kratos/kratos/v2/log"
 
 	"github.com/go-kratos/kratos/v2/errors"
@@ -23,10 +26,25 @@ type Response struct {
 }
 
 type ErrResponse struct {
-	Code    int    `json:"code" form:"code"`
-	Message string `json:"message" form:"message"`
-	Reason  string `json:"reason" form:"reason"`
-	Data    any    `json:"data" form:"data"`
+	Code    int         `json:"code" form:"code"`
+	Message string      `json:"message" form:"message"`
+	Reason  string      `json:"reason" form:"reason"`
+	Data    any         `json:"data" form:"data"`
+	Details []ErrDetail `json:"details,omitempty" form:"details"`
+}
+
+const (
+	ErrDetailTypeMetadata       = "metadata"
+	ErrDetailTypeFieldViolation = "field_violation"
+)
+
+// ErrDetail is a structured error detail, Type tells which fields are set
+type ErrDetail struct {
+	Type     string            `json:"type" form:"type"`
+	Field    string            `json:"field,omitempty" form:"field"`
+	Rule     string            `json:"rule,omitempty" form:"rule"`
+	Message  string            `json:"message,omitempty" form:"message"`
+	Metadata map[string]string `json:"metadata,omitempty" form:"metadata"`
 }
 
 var marshalOpt = protojson.MarshalOptions{
@@ -34,35 +52,62 @@ var marshalOpt = protojson.MarshalOptions{
 	EmitUnpopulated: true,
 }
 
-func ErrorEncoder(w http.ResponseWriter, r *http.Request, err error) {
-	if err == nil {
-		return
-	}
-
-	se := errors.FromError(err)
-	if se.Code == http.StatusTooManyRequests {
-		w.WriteHeader(http.StatusTooManyRequests)
-		_, _ = w.Write([]byte(se.Reason))
-		return
+// NewErrorEncoder writes errors in the json envelope, the http status is 200 or the error code
+// depending on the configured error mode
+func NewErrorEncoder(c *conf.Server_HTTP) khttp.EncodeErrorFunc {
+	useStatusCode := c.GetErrorMode() == conf.Server_HTTP_STATUS_CODE
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		if err == nil {
+			return
+		}
+
+		se := errors.FromError(err)
+		reply := &ErrResponse{
+			Code:    int(se.Code),
+			Message: se.Message,
+			Reason:  se.Reason,
+			Data:    nil,
+			Details: errDetails(se),
+		}
+
+		codec, _ := khttp.CodecForRequest(r, "Accept")
+		body, err := codec.Marshal(reply)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		status := http.StatusOK
+		if useStatusCode {
+			status = int(se.Code)
+			if status < 100 || status > 599 {
+				status = http.StatusInternalServerError
+			}
+		}
+
+		w.Header().Set("Content-Type", strings.Join([]string{"application", codec.Name()}, "/"))
+		w.WriteHeader(status)
+		_, _ = w.Write(body)
 	}
+}
 
-	reply := &ErrResponse{
-		Code:    int(se.Code),
-		Message: se.Message,
-		Reason:  se.Reason,
-		Data:    nil,
+func errDetails(se *errors.Error) []ErrDetail {
+	var details []ErrDetail
+	if len(se.Metadata) > 0 {
+		details = append(details, ErrDetail{
+			Type:     ErrDetailTypeMetadata,
+			Metadata: se.Metadata,
+		})
 	}
-
-	codec, _ := khttp.CodecForRequest(r, "Accept")
-	body, err := codec.Marshal(reply)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+	for _, v := range validate.FieldViolations(se.Unwrap()) {
+		details = append(details, ErrDetail{
+			Type:    ErrDetailTypeFieldViolation,
+			Field:   v.Field,
+			Rule:    v.Rule,
+			Message: v.Message,
+		})
 	}
-
-	w.Header().Set("Content-Type", strings.Join([]string{"application", codec.Name()}, "/"))
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(body)
+	return details
 }
 
 func ResponseEncoder(w http.ResponseWriter, r *http.Request, v any) error {
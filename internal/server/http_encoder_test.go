@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "server-template/api/server"
+	"server-template/internal/conf"
+	"server-template/pkg/limiter"
+	"server-template/pkg/middleware/validate"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorEncoder(t *testing.T) {
+	_, valErr := validate.ProtoValidate()(func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	})(context.Background(), &v1.GetUserReq{Id: "0"})
+	require.Error(t, valErr)
+
+	tests := []struct {
+		name       string
+		mode       conf.Server_HTTP_ErrorMode
+		err        error
+		wantStatus int
+		want       ErrResponse
+	}{
+		{
+			name:       "envelope",
+			mode:       conf.Server_HTTP_ENVELOPE,
+			err:        errors.NotFound("USER_NOT_FOUND", "user not found"),
+			wantStatus: http.StatusOK,
+			want:       ErrResponse{Code: 404, Message: "user not found", Reason: "USER_NOT_FOUND"},
+		},
+		{
+			name:       "status code",
+			mode:       conf.Server_HTTP_STATUS_CODE,
+			err:        errors.NotFound("USER_NOT_FOUND", "user not found"),
+			wantStatus: http.StatusNotFound,
+			want:       ErrResponse{Code: 404, Message: "user not found", Reason: "USER_NOT_FOUND"},
+		},
+		{
+			name:       "status code out of range",
+			mode:       conf.Server_HTTP_STATUS_CODE,
+			err:        errors.New(42, "ODD", "odd code"),
+			wantStatus: http.StatusInternalServerError,
+			want:       ErrResponse{Code: 42, Message: "odd code", Reason: "ODD"},
+		},
+		{
+			name:       "rate limited",
+			mode:       conf.Server_HTTP_STATUS_CODE,
+			err:        limiter.ErrLimitExceed,
+			wantStatus: http.StatusTooManyRequests,
+			want:       ErrResponse{Code: 429, Message: "rate limit exceeded", Reason: "RATELIMIT"},
+		},
+		{
+			name:       "metadata",
+			mode:       conf.Server_HTTP_ENVELOPE,
+			err:        errors.BadRequest("INVALID_MASK_PATH", "invalid path").WithMetadata(map[string]string{"path": "id"}),
+			wantStatus: http.StatusOK,
+			want: ErrResponse{Code: 400, Message: "invalid path", Reason: "INVALID_MASK_PATH", Details: []ErrDetail{
+				{Type: ErrDetailTypeMetadata, Metadata: map[string]string{"path": "id"}},
+			}},
+		},
+		{
+			name:       "field violations",
+			mode:       conf.Server_HTTP_STATUS_CODE,
+			err:        valErr,
+			wantStatus: http.StatusBadRequest,
+			want: ErrResponse{Code: 400, Message: errors.FromError(valErr).Message, Reason: "VALIDATOR", Details: []ErrDetail{
+				{Type: ErrDetailTypeFieldViolation, Field: "id", Rule: "string.pattern", Message: "value does not match regex pattern `^[1-9][0-9]{0,18}$`"},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encode := NewErrorEncoder(&conf.Server_HTTP{ErrorMode: tt.mode})
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/v1/users/0", nil)
+			encode(w, r, tt.err)
+
+			require.Equal(t, tt.wantStatus, w.Code)
+			require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+			var got ErrResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
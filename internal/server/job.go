@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"server-template/internal/biz"
+	"server-template/internal/conf"
+	"server-template/pkg/cron"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	cronPath = "/cron"
+
+	jobPurgeDeletedUser = "purge_deleted_user"
+
+	defaultPurgeInterval  = time.Hour
+	defaultPurgeBatchSize = 500
+)
+
+var _ transport.Server = (*JobServer)(nil)
+
+// JobServer runs the periodic background jobs on cron schedules, each tick runs on one replica.
+// It starts and stops with kratos.App, the runs are listed on the admin endpoint /cron.
+type JobServer struct {
+	*cron.Scheduler
+	user *biz.UserBiz
+	log  *log.Helper
+}
+
+// NewJobServer new a background job server, add the periodic jobs here.
+func NewJobServer(c *conf.Job, user *biz.UserBiz, rdb redis.UniversalClient, admin *AdminServer, logger log.Logger) (*JobServer, error) {
+	s := &JobServer{
+		Scheduler: cron.New(rdb, cron.WithHistorySize(c.GetHistorySize()), cron.WithLogger(logger)),
+		user:      user,
+		log:       log.NewHelper(log.With(logger, "module", "server/job")),
+	}
+
+	if cfg := c.GetPurgeDeletedUser(); cfg.GetRetention() == nil {
+		s.log.Info("purge deleted user job disabled")
+	} else {
+		interval := defaultPurgeInterval
+		if cfg.GetInterval() != nil {
+			interval = cfg.Interval.AsDuration()
+		}
+		job := newCronJob(jobPurgeDeletedUser, cfg.GetSchedule(), interval)
+		job.Run = func(ctx context.Context) error {
+			return s.purgeDeletedUser(ctx, cfg)
+		}
+		if err := s.Add(job); err != nil {
+			return nil, err
+		}
+	}
+
+	admin.Handle(cronPath, s.Handler())
+	return s, nil
+}
+
+// newCronJob reads the schedule of a job, it runs every interval when schedule.cron is unset
+func newCronJob(name string, c *conf.Job_Schedule, interval time.Duration) cron.Job {
+	job := cron.Job{
+		Name:     name,
+		Schedule: c.GetCron(),
+		Timeout:  c.GetTimeout().AsDuration(),
+	}
+	if job.Schedule == "" {
+		job.Schedule = "@every " + interval.String()
+	}
+	if c.GetMisfire() == conf.Job_CATCH_UP {
+		job.Misfire = cron.CatchUp
+	}
+	return job
+}
+
+func (s *JobServer) purgeDeletedUser(ctx context.Context, cfg *conf.Job_PurgeDeletedUser) error {
+	batchSize := cfg.GetBatchSize()
+	if batchSize <= 0 {
+		batchSize = defaultPurgeBatchSize
+	}
+	total, err := s.user.PurgeDeletedUsers(ctx, cfg.GetRetention().AsDuration(), batchSize)
+	if err != nil {
+		return err
+	}
+	s.log.Infof("purge deleted users done, purged: %d", total)
+	return nil
+}
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"server-template/api/server"
+	"server-template/internal/conf"
+	"server-template/pkg/limiter"
+	"server-template/pkg/metrics"
+	"server-template/pkg/middleware"
+	"server-template/pkg/middleware/auth"
+	"server-template/pkg/middleware/idempotency"
+	"server-template/pkg/middleware/logging"
+	"server-template/pkg/middleware/validate"
+
+	"github.com/go-kratos/kratos/v2/log"
+	kmiddleware "github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/metadata"
+	"github.com/go-kratos/kratos/v2/middleware/ratelimit"
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	"github.com/go-kratos/kratos/v2/middleware/selector"
+	"github.com/go-kratos/kratos/v2/middleware/tracing"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// Middlewares is the middleware chain shared by the http and grpc servers
+type Middlewares []kmiddleware.Middleware
+
+var _ conf.Reloadable = (*MiddlewareState)(nil)
+
+// MiddlewareState is the part of the middleware chain that follows config reloads:
+// the un_logging_op whitelist and the rate limit rules
+type MiddlewareState struct {
+	unLogging operationSet
+	rateLimit *limiter.RuleSet
+	log       *log.Helper
+}
+
+func NewMiddlewareState(c *conf.Server, logger log.Logger) (*MiddlewareState, error) {
+	rules, err := newRateLimitRules(c.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+	s := &MiddlewareState{
+		rateLimit: limiter.NewRuleSet(rules),
+		log:       log.NewHelper(log.With(logger, "module", "server/middleware")),
+	}
+	s.unLogging.Store(c.UnLoggingOp)
+	return s, nil
+}
+
+func (s *MiddlewareState) Validate(c *conf.Config) error {
+	_, err := newRateLimitRules(c.GetServer().GetRateLimit())
+	return err
+}
+
+func (s *MiddlewareState) Apply(old, new *conf.Config) {
+	if !slices.Equal(old.GetServer().GetUnLoggingOp(), new.GetServer().GetUnLoggingOp()) {
+		s.unLogging.Store(new.GetServer().GetUnLoggingOp())
+		s.log.Infof("un_logging_op reloaded: %v", new.GetServer().GetUnLoggingOp())
+	}
+	if !proto.Equal(old.GetServer().GetRateLimit(), new.GetServer().GetRateLimit()) {
+		// validated, can't fail
+		rules, _ := newRateLimitRules(new.GetServer().GetRateLimit())
+		s.rateLimit.Store(rules)
+		s.log.Infof("rate limit reloaded, rules: %d", len(rules))
+	}
+}
+
+// NewWhiteListMatcher matches every operation except the un_logging_op ones
+func (s *MiddlewareState) NewWhiteListMatcher() selector.MatchFunc {
+	return func(ctx context.Context, operation string) bool {
+		return !s.unLogging.Has(operation)
+	}
+}
+
+// operationSet is a set of operations that can be replaced while serving
+type operationSet struct {
+	ops atomic.Pointer[map[string]struct{}]
+}
+
+func (s *operationSet) Store(ops []string) {
+	m := make(map[string]struct{}, len(ops))
+	for _, v := range ops {
+		m[v] = struct{}{}
+	}
+	s.ops.Store(&m)
+}
+
+func (s *operationSet) Has(operation string) bool {
+	_, ok := (*s.ops.Load())[operation]
+	return ok
+}
+
+// newOperationMatcher matches the listed operations only
+func newOperationMatcher(ops []string) selector.MatchFunc {
+	var set operationSet
+	set.Store(ops)
+	return func(ctx context.Context, operation string) bool {
+		return set.Has(operation)
+	}
+}
+
+// newSkipOperationMatcher matches every operation except the listed ones
+func newSkipOperationMatcher(ops []string) selector.MatchFunc {
+	var set operationSet
+	set.Store(ops)
+	return func(ctx context.Context, operation string) bool {
+		return !set.Has(operation)
+	}
+}
+
+const (
+	defaultAPIKeyHeader   = "X-API-Key"
+	defaultIdempotencyTTL = time.Hour * 24
+)
+
+// NewMiddlewares builds the middleware chain once, so both transports share the same limiter and rules.
+// The bbr limiter protects this process from overload, the redis limiter enforces quotas across replicas.
+func NewMiddlewares(c *conf.Server, state *MiddlewareState, rdb redis.UniversalClient, logger log.Logger) (Middlewares, error) {
+	mws := Middlewares{
+		recovery.Recovery(),
+		tracing.Server(), // first, so the request id can fall back to the trace id
+		metrics.Server(), // before the limiters, so rejected requests are counted
+		ratelimit.Server(),
+		metadata.Server(),
+		middleware.RequestIdHandler,
+		selector.Server(logging.Server(logger)).Match(state.NewWhiteListMatcher()).Build(),
+	}
+
+	if c.GetAuth().GetEnable() {
+		authMw, err := newAuthMiddleware(c.Auth)
+		if err != nil {
+			return nil, err
+		}
+		mws = append(mws,
+			selector.Server(authMw).Match(newSkipOperationMatcher(c.Auth.PublicOp)).Build(),
+			auth.Authorize(server.File_server_server_proto.Services().ByName("Server")),
+		)
+	}
+
+	// after auth, so rules by user see the claims. Always in the chain, a reload may enable it.
+	mws = append(mws, limiter.Server(limiter.New(rdb), state.rateLimit))
+
+	if c.GetIdempotency().GetEnable() {
+		ttl := defaultIdempotencyTTL
+		if c.Idempotency.Ttl != nil {
+			ttl = c.Idempotency.Ttl.AsDuration()
+		}
+		mutating := idempotency.MutatingOperations(server.File_server_server_proto.Services().ByName("Server"))
+		mws = append(mws, selector.Server(idempotency.Server(rdb, ttl)).Match(newOperationMatcher(mutating)).Build())
+	}
+
+	mws = append(mws, validate.ProtoValidate())
+	return mws, nil
+}
+
+// newRateLimitRules returns no rules when rate limiting is disabled
+func newRateLimitRules(c *conf.Server_RateLimit) ([]limiter.Rule, error) {
+	if !c.GetEnable() {
+		return nil, nil
+	}
+	apiKeyHeader := c.ApiKeyHeader
+	if apiKeyHeader == "" {
+		apiKeyHeader = defaultAPIKeyHeader
+	}
+
+	rules := make([]limiter.Rule, 0, len(c.Rules))
+	for _, r := range c.Rules {
+		if r.Operation == "" || r.Limit <= 0 || r.Period.AsDuration() <= 0 {
+			return nil, errors.Errorf("invalid rate limit rule: %v", r)
+		}
+		rule := limiter.Rule{
+			Operation: r.Operation,
+			Name:      strings.ToLower(r.Identity.String()),
+			Limit: limiter.Limit{
+				Rate:   int(r.Limit),
+				Period: r.Period.AsDuration(),
+				Burst:  int(r.Burst),
+			},
+		}
+		switch r.Identity {
+		case conf.Server_RateLimit_IP:
+			rule.Identity = limiter.IdentityIP(c.TrustProxy)
+		case conf.Server_RateLimit_USER:
+			rule.Identity = limiter.IdentityUser()
+		case conf.Server_RateLimit_API_KEY:
+			rule.Identity = limiter.IdentityHeader(apiKeyHeader)
+		default:
+			return nil, errors.Errorf("unknown rate limit identity: %v", r.Identity)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func newAuthMiddleware(c *conf.Server_Auth) (kmiddleware.Middleware, error) {
+	var opts []auth.Option
+	if c.HmacSecret != "" {
+		opts = append(opts, auth.WithHMACSecret([]byte(c.HmacSecret)))
+	}
+	switch {
+	case c.JwksFile != "":
+		keySet, err := auth.LoadKeySetFile(c.JwksFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, auth.WithKeySet(keySet))
+	case c.JwksUrl != "":
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		keySet, err := auth.NewRemoteKeySet(ctx, c.JwksUrl, c.JwksRefreshInterval.AsDuration())
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, auth.WithKeySet(keySet))
+	}
+	if len(opts) == 0 {
+		return nil, errors.New("auth enabled without hmac_secret, jwks_file or jwks_url")
+	}
+	if c.Issuer != "" {
+		opts = append(opts, auth.WithIssuer(c.Issuer))
+	}
+	if len(c.Audience) > 0 {
+		opts = append(opts, auth.WithAudience(c.Audience...))
+	}
+	return auth.Server(opts...), nil
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"server-template/internal/conf"
+	lock "server-template/pkg/lock"
+	"server-template/pkg/outbox"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	outboxRelayLockKey = "lock:outbox:relay"
+
+	defaultOutboxPollInterval = time.Second
+	defaultOutboxBatchSize    = 100
+	outboxPurgeInterval       = time.Hour
+	outboxPurgeBatchSize      = 1000
+)
+
+var _ transport.Server = (*OutboxRelay)(nil)
+
+// OutboxRelay publishes the outbox to redis streams. Every replica runs one, the one holding the lock
+// relays while the others wait to take over.
+type OutboxRelay struct {
+	c         *conf.Outbox
+	relay     *outbox.Relay
+	batchSize int32
+	store     outbox.Store
+	rdb       redis.UniversalClient
+	log       *log.Helper
+
+	quit   context.Context // done on Stop, even one called before Start
+	cancel context.CancelFunc
+}
+
+// NewOutboxRelay new an outbox relay.
+func NewOutboxRelay(c *conf.Outbox, store outbox.Store, rdb redis.UniversalClient, logger log.Logger) *OutboxRelay {
+	batchSize := c.GetBatchSize()
+	if batchSize <= 0 {
+		batchSize = defaultOutboxBatchSize
+	}
+	sink := outbox.NewRedisStreamSink(rdb, c.GetStreamPrefix(), c.GetStreamMaxLen())
+	quit, cancel := context.WithCancel(context.Background())
+	return &OutboxRelay{
+		c:         c,
+		relay:     outbox.NewRelay(store, sink, batchSize),
+		batchSize: batchSize,
+		store:     store,
+		rdb:       rdb,
+		log:       log.NewHelper(log.With(logger, "module", "server/outbox")),
+		quit:      quit,
+		cancel:    cancel,
+	}
+}
+
+func (s *OutboxRelay) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(s.quit, cancel)()
+
+	if !s.c.GetEnable() {
+		s.log.Info("outbox relay disabled")
+		<-ctx.Done()
+		return nil
+	}
+
+	interval := defaultOutboxPollInterval
+	if s.c.GetPollInterval() != nil {
+		interval = s.c.PollInterval.AsDuration()
+	}
+	for {
+		lease, ok := s.lease(ctx, interval)
+		if !ok {
+			return nil
+		}
+		s.log.Info("outbox relay started")
+		// stops when the lease is lost, another replica may be relaying already
+		s.run(lease.Context(), interval)
+		if err := lease.Err(); err != nil {
+			s.log.Errorf("outbox relay lost its lock, err: %+v", err)
+		}
+		if _, err := lease.Release(context.WithoutCancel(ctx)); err != nil {
+			s.log.Warnf("outbox relay unlock failed, err: %+v", err)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+func (s *OutboxRelay) Stop(ctx context.Context) error {
+	s.cancel()
+	return nil
+}
+
+// lease waits until this replica holds the relay lock, the lock is renewed until released or lost
+func (s *OutboxRelay) lease(ctx context.Context, interval time.Duration) (*lock.Lease, bool) {
+	for {
+		lease, err := lock.LeaseWithKey(ctx, outboxRelayLockKey, s.rdb)
+		if err != nil {
+			s.log.Warnf("outbox relay lock failed, err: %+v", err)
+		}
+		if lease != nil {
+			return lease, true
+		}
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (s *OutboxRelay) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var purgedAt time.Time
+	for {
+		n, err := s.relay.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.log.Warnf("relay outbox failed, relayed: %d, err: %+v", n, err)
+		}
+		if retention := s.c.GetRetention(); retention != nil && time.Since(purgedAt) > outboxPurgeInterval {
+			s.purge(ctx, retention.AsDuration())
+			purgedAt = time.Now()
+		}
+		// a full batch means more events are waiting
+		if err == nil && n > 0 && n >= int(s.batchSize) {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *OutboxRelay) purge(ctx context.Context, retention time.Duration) {
+	before := time.Now().Add(-retention)
+	var total int64
+	for {
+		rows, err := s.store.Purge(ctx, before, outboxPurgeBatchSize)
+		total += rows
+		if err != nil {
+			s.log.Warnf("purge delivered outbox failed, purged: %d, err: %+v", total, err)
+			return
+		}
+		if rows < outboxPurgeBatchSize || ctx.Err() != nil {
+			break
+		}
+	}
+	if total > 0 {
+		s.log.Infof("purge delivered outbox done, purged: %d", total)
+	}
+}
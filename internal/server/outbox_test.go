@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"server-template/internal/conf"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/require"
+)
+
+// requireStop checks that Stop ends Start, also when it is called first
+func requireStop(t *testing.T, newServer func() transport.Server) {
+	for _, stopFirst := range []bool{false, true} {
+		s := newServer()
+		if stopFirst {
+			require.NoError(t, s.Stop(context.Background()))
+		}
+		done := make(chan error)
+		go func() { done <- s.Start(context.Background()) }()
+		require.NoError(t, s.Stop(context.Background()))
+
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("server did not stop")
+		}
+	}
+}
+
+func TestOutboxRelayStop(t *testing.T) {
+	requireStop(t, func() transport.Server {
+		return NewOutboxRelay(&conf.Outbox{}, nil, nil, log.DefaultLogger)
+	})
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+
+	"server-template/internal/biz"
+	"server-template/internal/conf"
+	"server-template/pkg/queue"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-redis/redis/v8"
+)
+
+var _ transport.Server = (*QueueServer)(nil)
+
+// QueueServer runs the background job workers, it starts and stops with kratos.App
+type QueueServer struct {
+	c      *conf.Queue
+	worker *queue.Worker
+	log    *log.Helper
+
+	quit   context.Context // done on Stop, stops Start when the workers are disabled
+	cancel context.CancelFunc
+}
+
+// NewQueueServer new a queue server, register the handlers of the jobs enqueued by biz here.
+func NewQueueServer(c *conf.Queue, user *biz.UserBiz, rdb redis.UniversalClient, logger log.Logger) *QueueServer {
+	opts := []queue.Option{
+		queue.WithPrefix(c.GetStreamPrefix()),
+		queue.WithConcurrency(int(c.GetConcurrency())),
+		queue.WithDeadMaxLen(c.GetDeadMaxLen()),
+		queue.WithLogger(logger),
+	}
+	if c.GetGroup() != "" {
+		opts = append(opts, queue.WithGroup(c.Group))
+	}
+	if c.GetClaimIdle() != nil {
+		opts = append(opts, queue.WithClaimIdle(c.ClaimIdle.AsDuration()))
+	}
+	if c.GetMaxDeliveries() > 0 {
+		opts = append(opts, queue.WithMaxDeliveries(c.MaxDeliveries))
+	}
+	w := queue.NewWorker(rdb, opts...)
+	queue.Handle(w, biz.JobSendWelcomeEmail, user.SendWelcomeEmail)
+
+	quit, cancel := context.WithCancel(context.Background())
+	return &QueueServer{
+		c:      c,
+		worker: w,
+		log:    log.NewHelper(log.With(logger, "module", "server/queue")),
+		quit:   quit,
+		cancel: cancel,
+	}
+}
+
+func (s *QueueServer) Start(ctx context.Context) error {
+	if !s.c.GetEnable() {
+		s.log.Info("queue workers disabled")
+		select {
+		case <-ctx.Done():
+		case <-s.quit.Done():
+		}
+		return nil
+	}
+	return s.worker.Start(ctx)
+}
+
+func (s *QueueServer) Stop(ctx context.Context) error {
+	if !s.c.GetEnable() {
+		s.cancel()
+		return nil
+	}
+	return s.worker.Stop(ctx)
+}
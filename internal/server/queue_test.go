@@ -0,0 +1,16 @@
+package server
+
+import (
+	"testing"
+
+	"server-template/internal/conf"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+func TestQueueServerStop(t *testing.T) {
+	requireStop(t, func() transport.Server {
+		return NewQueueServer(&conf.Queue{}, nil, nil, log.DefaultLogger)
+	})
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"sync"
+
+	"server-template/internal/conf"
+	"server-template/pkg/feature"
+	pkgLog "server-template/pkg/log"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// hotPaths are the settings applied without a restart, a change anywhere else only logs a warning
+var hotPaths = []string{
+	"log.level",
+	"log.modules",
+	"features",
+	"server.un_logging_op",
+	"server.rate_limit",
+	"db.master.max_open_conn",
+	"db.master.max_idle_conn",
+	"db.master.max_lifetime_conn",
+	"db.slave.max_open_conn",
+	"db.slave.max_idle_conn",
+	"db.slave.max_lifetime_conn",
+}
+
+var _ transport.Server = (*ConfigWatcher)(nil)
+
+// ConfigWatcher reloads the config when its source changes, validates it with every component
+// and applies it only when all of them accept it
+type ConfigWatcher struct {
+	src         config.Config
+	reloadables []conf.Reloadable
+	log         *log.Helper
+
+	mu      sync.Mutex
+	current *conf.Config
+}
+
+// NewConfigWatcher new a config watcher, log level and feature flags are always reloaded.
+// A reloaded log level drops the temporary change made through levels.
+func NewConfigWatcher(src config.Config, bc *conf.Config, levels *pkgLog.LevelHandler, logger log.Logger, reloadables ...conf.Reloadable) *ConfigWatcher {
+	return &ConfigWatcher{
+		src:         src,
+		reloadables: append([]conf.Reloadable{logLevel{levels: levels}, features{}}, reloadables...),
+		log:         log.NewHelper(log.With(logger, "module", "server/config")),
+		current:     proto.Clone(bc).(*conf.Config),
+	}
+}
+
+// Start watches every top level section, the source stops notifying when main closes it
+func (w *ConfigWatcher) Start(ctx context.Context) error {
+	fields := w.current.ProtoReflect().Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		key := string(fields.Get(i).Name())
+		err := w.src.Watch(key, func(string, config.Value) {
+			w.reload()
+		})
+		if err != nil && !errors.Is(err, config.ErrNotFound) {
+			return errors.Wrapf(err, "watch config %s failed", key)
+		}
+	}
+	return nil
+}
+
+func (w *ConfigWatcher) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (w *ConfigWatcher) reload() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next, err := conf.Scan(w.src)
+	if err != nil {
+		w.log.Errorf("config change rejected, err: %v", err)
+		return
+	}
+	// one file write notifies every changed section, the first reload applies them all
+	changes := conf.Diff(w.current, next)
+	if len(changes) == 0 {
+		return
+	}
+	for _, r := range w.reloadables {
+		if err := r.Validate(next); err != nil {
+			w.log.Errorf("config change rejected, err: %v", err)
+			return
+		}
+	}
+
+	for _, c := range changes {
+		if isHot(c.Path) {
+			w.log.Infof("config changed, %s", c)
+		} else {
+			w.log.Warnf("config changed but needs a restart to apply, %s", c)
+		}
+	}
+	for _, r := range w.reloadables {
+		r.Apply(w.current, next)
+	}
+	w.current = next
+}
+
+func isHot(path string) bool {
+	for _, p := range hotPaths {
+		if path == p || strings.HasPrefix(path, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+type logLevel struct {
+	levels *pkgLog.LevelHandler
+}
+
+func (logLevel) Validate(c *conf.Config) error {
+	if level := c.GetLog().GetLevel(); level != "" {
+		if _, err := pkgLog.ParseLevel(level); err != nil {
+			return errors.Wrapf(err, "invalid log.level %q", level)
+		}
+	}
+	if _, err := pkgLog.ParseModuleLevels(c.GetLog().GetModules()); err != nil {
+		return errors.Wrap(err, "invalid log.modules")
+	}
+	return nil
+}
+
+func (l logLevel) Apply(old, new *conf.Config) {
+	levelChanged := old.GetLog().GetLevel() != new.GetLog().GetLevel()
+	modulesChanged := !maps.Equal(old.GetLog().GetModules(), new.GetLog().GetModules())
+	if !levelChanged && !modulesChanged {
+		return
+	}
+	// the config replaces a temporary change from the admin endpoint, all of it is applied
+	// so no temporary level is left behind
+	if l.levels != nil && l.levels.Reset() {
+		log.Warn("log config changed, drop the temporary log level change")
+		levelChanged, modulesChanged = true, true
+	}
+	if levelChanged {
+		l.applyLevel(new)
+	}
+	if modulesChanged {
+		modules, _ := pkgLog.ParseModuleLevels(new.GetLog().GetModules())
+		pkgLog.SetModuleLevels(modules)
+	}
+}
+
+func (logLevel) applyLevel(new *conf.Config) {
+	if new.GetLog().GetLevel() == "" {
+		log.Warn("log.level removed, keep the current level until restart")
+		return
+	}
+	level, _ := pkgLog.ParseLevel(new.GetLog().GetLevel())
+	pkgLog.SetLevel(level)
+}
+
+type features struct{}
+
+func (features) Validate(c *conf.Config) error {
+	return nil
+}
+
+func (features) Apply(old, new *conf.Config) {
+	if !maps.Equal(old.GetFeatures(), new.GetFeatures()) {
+		feature.Set(new.GetFeatures())
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"server-template/internal/conf"
+	"server-template/pkg/env"
+	pkgLog "server-template/pkg/log"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogLevelApply(t *testing.T) {
+	prev := pkgLog.Default()
+	defer pkgLog.ResetDefault(prev)
+
+	tests := []struct {
+		name        string
+		temporary   string // temporary change from the admin endpoint
+		new         *conf.Log
+		wantLevel   pkgLog.Level
+		wantModules map[string]pkgLog.Level
+		wantRevert  bool
+	}{
+		{
+			name:        "level",
+			new:         &conf.Log{Level: "warn", Modules: map[string]string{"db": "error"}},
+			wantLevel:   pkgLog.WarnLevel,
+			wantModules: map[string]pkgLog.Level{"db": pkgLog.ErrorLevel},
+		},
+		{
+			name:        "unrelated change keeps the temporary level",
+			temporary:   `{"level":"debug","modules":{},"duration":"1h"}`,
+			new:         &conf.Log{Level: "info", Modules: map[string]string{"db": "error"}},
+			wantLevel:   pkgLog.DebugLevel,
+			wantModules: map[string]pkgLog.Level{},
+			wantRevert:  true,
+		},
+		{
+			name:        "module change drops the temporary level",
+			temporary:   `{"level":"debug","modules":{},"duration":"1h"}`,
+			new:         &conf.Log{Level: "info", Modules: map[string]string{"db": "warn"}},
+			wantLevel:   pkgLog.InfoLevel,
+			wantModules: map[string]pkgLog.Level{"db": pkgLog.WarnLevel},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := &conf.Log{Level: "info", Modules: map[string]string{"db": "error"}}
+			pkgLog.ResetDefault(pkgLog.New(io.Discard, old, env.ModeProd))
+			admin := NewAdminServer(&conf.Server{}, prev)
+			if tt.temporary != "" {
+				changeLevel(t, admin.LevelHandler(), tt.temporary)
+			}
+
+			logLevel{levels: admin.LevelHandler()}.Apply(&conf.Config{Log: old}, &conf.Config{Log: tt.new})
+			require.Equal(t, tt.wantLevel, pkgLog.Default().Level())
+			require.Equal(t, tt.wantModules, pkgLog.Default().ModuleLevels())
+			require.Equal(t, tt.wantRevert, admin.LevelHandler().Reset())
+		})
+	}
+}
+
+func changeLevel(t *testing.T, h http.Handler, body string) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, logLevelPath, strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/go-kratos/kratos/v2/log"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type ServerService struct {
@@ -40,6 +41,86 @@ func (b *ServerService) CreateUser(ctx context.Context, req *pb.CreateUserReq) (
 	}, nil
 }
 
+func (b *ServerService) GetUser(ctx context.Context, req *pb.GetUserReq) (*pb.GetUserReply, error) {
+	id, err := strconv.ParseInt(req.Id, 10, 64)
+	if err != nil {
+		return nil, biz.ErrUserNotFound
+	}
+	user, err := b.user.GetUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetUserReply{
+		User: toPbUser(user),
+	}, nil
+}
+
+func (b *ServerService) ListUsers(ctx context.Context, req *pb.ListUsersReq) (*pb.ListUsersReply, error) {
+	users, nextPageToken, err := b.user.ListUsers(ctx, req.PageToken, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	reply := &pb.ListUsersReply{
+		Users:         make([]*pb.User, 0, len(users)),
+		NextPageToken: nextPageToken,
+	}
+	for _, user := range users {
+		reply.Users = append(reply.Users, toPbUser(user))
+	}
+	return reply, nil
+}
+
+func (b *ServerService) UpdateUser(ctx context.Context, req *pb.UpdateUserReq) (*pb.UpdateUserReply, error) {
+	id, err := strconv.ParseInt(req.Id, 10, 64)
+	if err != nil {
+		return nil, biz.ErrUserNotFound
+	}
+	user, err := b.user.UpdateUser(ctx, id, &biz.User{
+		Name:    req.Name,
+		Country: req.Country,
+		Email:   req.Email,
+	}, req.UpdateMask.GetPaths())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UpdateUserReply{
+		User: toPbUser(user),
+	}, nil
+}
+
+func (b *ServerService) DeleteUser(ctx context.Context, req *pb.DeleteUserReq) (*emptypb.Empty, error) {
+	id, err := strconv.ParseInt(req.Id, 10, 64)
+	if err != nil {
+		return nil, biz.ErrUserNotFound
+	}
+	if err = b.user.DeleteUser(ctx, id); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (b *ServerService) RestoreUser(ctx context.Context, req *pb.RestoreUserReq) (*emptypb.Empty, error) {
+	id, err := strconv.ParseInt(req.Id, 10, 64)
+	if err != nil {
+		return nil, biz.ErrUserNotFound
+	}
+	if err = b.user.RestoreUser(ctx, id); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func toPbUser(user *biz.User) *pb.User {
+	return &pb.User{
+		Id:        strconv.FormatInt(user.ID, 10),
+		Name:      user.Name,
+		Country:   user.Country,
+		Email:     user.Email,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+	}
+}
+
 func (s *ServerService) Ping(
 	ctx context.Context, in *emptypb.Empty,
 ) (*emptypb.Empty, error) {
@@ -0,0 +1,228 @@
+package confsource
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const format = "json"
+
+var durationName = (&durationpb.Duration{}).ProtoReflect().Descriptor().FullName()
+
+// Layered stacks sources, later ones override earlier ones. Only base is watched,
+// the overrides are loaded again after every base change so they keep winning over it.
+func Layered(base config.Source, overrides ...config.Source) config.Source {
+	return &layered{base: base, overrides: overrides}
+}
+
+type layered struct {
+	base      config.Source
+	overrides []config.Source
+}
+
+func (l *layered) Load() ([]*config.KeyValue, error) {
+	kvs, err := l.base.Load()
+	if err != nil {
+		return nil, err
+	}
+	return l.withOverrides(kvs)
+}
+
+func (l *layered) withOverrides(kvs []*config.KeyValue) ([]*config.KeyValue, error) {
+	for _, o := range l.overrides {
+		okvs, err := o.Load()
+		if err != nil {
+			return nil, err
+		}
+		kvs = append(kvs, okvs...)
+	}
+	return kvs, nil
+}
+
+func (l *layered) Watch() (config.Watcher, error) {
+	w, err := l.base.Watch()
+	if err != nil {
+		return nil, err
+	}
+	return &layeredWatcher{Watcher: w, l: l}, nil
+}
+
+type layeredWatcher struct {
+	config.Watcher
+	l *layered
+}
+
+func (w *layeredWatcher) Next() ([]*config.KeyValue, error) {
+	kvs, err := w.Watcher.Next()
+	if err != nil {
+		return nil, err
+	}
+	return w.l.withOverrides(kvs)
+}
+
+// static is a source built once, it never changes
+type static struct {
+	name string
+	load func() (map[string]any, error)
+}
+
+func (s *static) Load() ([]*config.KeyValue, error) {
+	values, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshal %s config failed", s.name)
+	}
+	return []*config.KeyValue{{Key: s.name, Value: data, Format: format}}, nil
+}
+
+// Watch is never called, overrides are reloaded by Layered
+func (s *static) Watch() (config.Watcher, error) {
+	return nil, errors.Errorf("%s config can't be watched, stack it with Layered", s.name)
+}
+
+// set puts raw into values at the field path, converted to the json the field type expects
+func set(values map[string]any, fields []protoreflect.FieldDescriptor, raw string) error {
+	for _, fd := range fields[:len(fields)-1] {
+		name := string(fd.Name())
+		sub, ok := values[name].(map[string]any)
+		if !ok {
+			sub = make(map[string]any)
+			values[name] = sub
+		}
+		values = sub
+	}
+	fd := fields[len(fields)-1]
+	v, err := convert(fd, raw)
+	if err != nil {
+		return err
+	}
+	values[string(fd.Name())] = v
+	return nil
+}
+
+// convert parses raw by field type. Lists are comma separated, maps are k=v pairs,
+// lists of messages are a json array.
+func convert(fd protoreflect.FieldDescriptor, raw string) (any, error) {
+	switch {
+	case fd.IsMap():
+		m := make(map[string]any)
+		for _, pair := range split(raw) {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return nil, errors.Errorf("invalid map entry %q, want key=value", pair)
+			}
+			cv, err := convertScalar(fd.MapValue(), v)
+			if err != nil {
+				return nil, err
+			}
+			m[strings.TrimSpace(k)] = cv
+		}
+		return m, nil
+	case fd.IsList() && fd.Kind() == protoreflect.MessageKind && fd.Message().FullName() != durationName:
+		var list []any
+		if err := json.Unmarshal([]byte(raw), &list); err != nil {
+			return nil, errors.Wrap(err, "invalid json array")
+		}
+		return list, nil
+	case fd.IsList():
+		list := make([]any, 0)
+		for _, item := range split(raw) {
+			v, err := convertScalar(fd, item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	}
+	return convertScalar(fd, raw)
+}
+
+func convertScalar(fd protoreflect.FieldDescriptor, raw string) (any, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return strconv.ParseBool(raw)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return strconv.ParseInt(raw, 10, 32)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return strconv.ParseUint(raw, 10, 32)
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// protojson reads 64 bit integers from strings without losing precision
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return strconv.ParseFloat(raw, 64)
+	case protoreflect.EnumKind:
+		if fd.Enum().Values().ByName(protoreflect.Name(raw)) == nil {
+			return nil, errors.Errorf("unknown %s value %q", fd.Enum().Name(), raw)
+		}
+		return raw, nil
+	case protoreflect.MessageKind:
+		if fd.Message().FullName() != durationName {
+			return nil, errors.Errorf("%s can't be set from a string", fd.Message().FullName())
+		}
+		// accept go durations like 1h30m, protojson wants seconds
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, err
+		}
+		b, err := protojson.Marshal(durationpb.New(d))
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(b), nil
+	}
+	return raw, nil
+}
+
+func split(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// isSection is a singular nested message, its fields are set one by one
+func isSection(fd protoreflect.FieldDescriptor) bool {
+	return fd.Kind() == protoreflect.MessageKind && !fd.IsList() && !fd.IsMap() && fd.Message().FullName() != durationName
+}
+
+// lookup resolves a dotted path like db.master.dsn to its fields
+func lookup(desc protoreflect.MessageDescriptor, path string) ([]protoreflect.FieldDescriptor, error) {
+	var fields []protoreflect.FieldDescriptor
+	for _, name := range strings.Split(path, ".") {
+		if desc == nil {
+			return nil, errors.Errorf("unknown config path %q", path)
+		}
+		fd := desc.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return nil, errors.Errorf("unknown config path %q", path)
+		}
+		fields = append(fields, fd)
+		desc = nil
+		if isSection(fd) {
+			desc = fd.Message()
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,88 @@
+package confsource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"server-template/internal/conf"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/go-kratos/kratos/v2/config/file"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayered(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+db:
+  master:
+    dsn: yaml-dsn
+    max_open_conn: 100
+redis:
+  passwd: yaml-pass
+server:
+  http:
+    addr: 0.0.0.0:8000
+`), 0o644))
+	secret := filepath.Join(dir, "redis_passwd")
+	require.NoError(t, os.WriteFile(secret, []byte("file-pass\n"), 0o600))
+
+	t.Setenv("APP_DB_MASTER_DSN", "env-dsn")
+	t.Setenv("APP_DB_MASTER_MAX_OPEN_CONN", "50")
+	t.Setenv("APP_REDIS_PASSWD", "env-pass")
+	t.Setenv("APP_REDIS_PASSWD_FILE", secret)
+	t.Setenv("APP_REDIS_ADDRS", "a:6379, b:6379")
+	t.Setenv("APP_SERVER_HTTP_TIMEOUT", "1m30s")
+	t.Setenv("APP_SERVER_HTTP_ERROR_MODE", "STATUS_CODE")
+	t.Setenv("APP_FEATURES", "beta=true,old=false")
+
+	desc := (&conf.Config{}).ProtoReflect().Descriptor()
+	c := config.New(config.WithSource(Layered(
+		file.NewSource(path),
+		Env("APP", desc),
+		Flags([]string{"db.master.max_open_conn=20", "server.http.addr=:9999"}, desc),
+	)))
+	require.NoError(t, c.Load())
+	defer c.Close()
+
+	var bc conf.Config
+	require.NoError(t, c.Scan(&bc))
+	require.Equal(t, "env-dsn", bc.Db.Master.Dsn)
+	require.Equal(t, int32(20), bc.Db.Master.MaxOpenConn)
+	require.Equal(t, "file-pass", bc.Redis.Passwd)
+	require.Equal(t, []string{"a:6379", "b:6379"}, bc.Redis.Addrs)
+	require.Equal(t, "1m30s", bc.Server.Http.Timeout.AsDuration().String())
+	require.Equal(t, conf.Server_HTTP_STATUS_CODE, bc.Server.Http.ErrorMode)
+	require.Equal(t, ":9999", bc.Server.Http.Addr)
+	require.Equal(t, map[string]bool{"beta": true, "old": false}, bc.Features)
+}
+
+func TestInvalid(t *testing.T) {
+	desc := (&conf.Config{}).ProtoReflect().Descriptor()
+	tests := []struct {
+		name string
+		src  config.Source
+	}{
+		{
+			name: "unknown path",
+			src:  Flags([]string{"db.master.nope=1"}, desc),
+		},
+		{
+			name: "not a number",
+			src:  Flags([]string{"db.master.max_open_conn=many"}, desc),
+		},
+		{
+			name: "unknown enum",
+			src:  Flags([]string{"server.http.error_mode=PLAIN"}, desc),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.src.Load()
+			require.Error(t, err)
+		})
+	}
+}
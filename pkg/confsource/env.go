@@ -0,0 +1,71 @@
+package confsource
+
+import (
+	"os"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// fileSuffix points a variable at a file holding its value, eg: docker and kubernetes secrets
+const fileSuffix = "_FILE"
+
+// Env maps environment variables onto the fields of desc, the name is the prefix and the
+// upper cased field path, eg: APP_DB_MASTER_DSN sets db.master.dsn. APP_DB_MASTER_DSN_FILE
+// reads the value from a file and wins over APP_DB_MASTER_DSN.
+func Env(prefix string, desc protoreflect.MessageDescriptor) config.Source {
+	return &static{name: "env", load: func() (map[string]any, error) {
+		values := make(map[string]any)
+		var err error
+		walk(desc, nil, func(fields []protoreflect.FieldDescriptor) {
+			if err != nil {
+				return
+			}
+			name := envName(prefix, fields)
+			if v, ok := os.LookupEnv(name); ok {
+				if err = set(values, fields, v); err != nil {
+					err = errors.Wrapf(err, "invalid %s", name)
+					return
+				}
+			}
+			if path, ok := os.LookupEnv(name + fileSuffix); ok {
+				var b []byte
+				if b, err = os.ReadFile(path); err != nil {
+					err = errors.Wrapf(err, "read %s%s failed", name, fileSuffix)
+					return
+				}
+				if err = set(values, fields, strings.TrimRight(string(b), "\r\n")); err != nil {
+					err = errors.Wrapf(err, "invalid %s%s", name, fileSuffix)
+				}
+			}
+		})
+		return values, err
+	}}
+}
+
+func envName(prefix string, fields []protoreflect.FieldDescriptor) string {
+	names := make([]string, 0, len(fields)+1)
+	if prefix != "" {
+		names = append(names, prefix)
+	}
+	for _, fd := range fields {
+		names = append(names, strings.ToUpper(string(fd.Name())))
+	}
+	return strings.Join(names, "_")
+}
+
+// walk calls fn with the path of every field that holds a value
+func walk(desc protoreflect.MessageDescriptor, parent []protoreflect.FieldDescriptor, fn func([]protoreflect.FieldDescriptor)) {
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		path := append(append(make([]protoreflect.FieldDescriptor, 0, len(parent)+1), parent...), fd)
+		if isSection(fd) {
+			walk(fd.Message(), path, fn)
+			continue
+		}
+		fn(path)
+	}
+}
@@ -0,0 +1,42 @@
+package confsource
+
+import (
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Sets collects repeated -set path=value flags
+type Sets []string
+
+func (s *Sets) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *Sets) Set(v string) error {
+	if !strings.Contains(v, "=") {
+		return errors.Errorf("invalid %q, want path=value", v)
+	}
+	*s = append(*s, v)
+	return nil
+}
+
+// Flags maps path=value pairs onto the fields of desc, eg: db.master.max_open_conn=50
+func Flags(sets []string, desc protoreflect.MessageDescriptor) config.Source {
+	return &static{name: "flags", load: func() (map[string]any, error) {
+		values := make(map[string]any)
+		for _, s := range sets {
+			path, v, _ := strings.Cut(s, "=")
+			fields, err := lookup(desc, path)
+			if err != nil {
+				return nil, err
+			}
+			if err := set(values, fields, v); err != nil {
+				return nil, errors.Wrapf(err, "invalid -set %s", path)
+			}
+		}
+		return values, nil
+	}}
+}
@@ -0,0 +1,362 @@
+// Package cron runs periodic jobs on cron schedules across replicas. Every replica schedules every job,
+// on each tick the replica that takes the job lock runs it and the others skip the tick. The time of the
+// last run is kept in redis, so a tick already run by another replica or missed while every replica was
+// down is detected.
+package cron
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"server-template/pkg"
+	lock "server-template/pkg/lock"
+	"server-template/pkg/metrics"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	robfig "github.com/robfig/cron/v3"
+)
+
+const (
+	defaultPrefix      = "cron:"
+	defaultHistorySize = 20
+	// maxCatchUp bounds the missed runs of a CatchUp job run at once, the older ones are dropped
+	maxCatchUp = 10
+)
+
+var (
+	runsTotal   = metrics.NewCounter("cron_runs_total", "Cron job runs, by job and result: ok or failed.", "job", "result")
+	runDuration = metrics.NewHistogram("cron_run_duration_seconds", "Cron job run time, by job.", nil, "job")
+	missedTotal = metrics.NewCounter("cron_missed_total", "Cron job runs dropped by the misfire policy, by job.", "job")
+)
+
+// parser accepts 5 fields, an optional leading seconds field, and descriptors like @hourly or @every 1h
+var parser = robfig.NewParser(
+	robfig.SecondOptional | robfig.Minute | robfig.Hour | robfig.Dom | robfig.Month | robfig.Dow | robfig.Descriptor,
+)
+
+// Misfire tells what to do with the runs missed while the job was late or every replica was down
+type Misfire int
+
+const (
+	// Skip drops the missed runs, the job runs once for the latest of them
+	Skip Misfire = iota
+	// CatchUp runs the missed runs one after another, at most 10, also at start
+	CatchUp
+)
+
+func (m Misfire) String() string {
+	if m == CatchUp {
+		return "catch_up"
+	}
+	return "skip"
+}
+
+// Job is a periodic job
+type Job struct {
+	Name     string
+	Schedule string // cron expression, eg: 0 3 * * *, @hourly, @every 10m
+	Misfire  Misfire
+	Timeout  time.Duration // cancels the context of a run, 0 runs without timeout
+	Run      func(ctx context.Context) error
+}
+
+type entry struct {
+	Job
+	sched robfig.Schedule
+}
+
+type scheduledKey struct{}
+
+// ScheduledTime returns the tick a run is for, it differs from now when the run is late or caught up
+func ScheduledTime(ctx context.Context) time.Time {
+	t, _ := ctx.Value(scheduledKey{}).(time.Time)
+	return t
+}
+
+type options struct {
+	prefix      string
+	historySize int64
+	replica     string
+	logger      log.Logger
+}
+
+// Option configures a Scheduler
+type Option func(*options)
+
+// WithPrefix sets the prefix of the redis keys, defaults to cron:
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithHistorySize keeps the last n runs of every job, defaults to 20
+func WithHistorySize(n int64) Option {
+	return func(o *options) {
+		o.historySize = n
+	}
+}
+
+// WithReplica names this replica in the history, defaults to the hostname
+func WithReplica(replica string) Option {
+	return func(o *options) {
+		o.replica = replica
+	}
+}
+
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+var _ transport.Server = (*Scheduler)(nil)
+
+// Scheduler runs the jobs added to it, it starts and stops with kratos.App
+type Scheduler struct {
+	rdb     redis.UniversalClient
+	opts    options
+	entries []*entry
+	log     *log.Helper
+
+	quit    context.Context // done on Stop, even one called before Start, cancels the running jobs
+	cancel  context.CancelFunc
+	started atomic.Bool
+	done    chan struct{}
+}
+
+func New(rdb redis.UniversalClient, opts ...Option) *Scheduler {
+	o := options{
+		prefix:      defaultPrefix,
+		historySize: defaultHistorySize,
+		logger:      log.GetLogger(),
+	}
+	o.replica, _ = os.Hostname()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.historySize <= 0 {
+		o.historySize = defaultHistorySize
+	}
+	s := &Scheduler{
+		rdb:  rdb,
+		opts: o,
+		log:  log.NewHelper(log.With(o.logger, "module", "cron")),
+		done: make(chan struct{}),
+	}
+	s.quit, s.cancel = context.WithCancel(context.Background())
+	return s
+}
+
+// Add schedules job, call it before Start
+func (s *Scheduler) Add(job Job) error {
+	sched, err := parser.Parse(job.Schedule)
+	if err != nil {
+		return errors.Wrapf(err, "invalid schedule %q of job %s", job.Schedule, job.Name)
+	}
+	s.entries = append(s.entries, &entry{Job: job, sched: sched})
+	return nil
+}
+
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.started.Store(true)
+	defer close(s.done)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(s.quit, cancel)()
+
+	var wg sync.WaitGroup
+	for _, e := range s.entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}()
+	}
+	s.log.Infof("cron scheduler started, jobs: %d", len(s.entries))
+	wg.Wait()
+	return nil
+}
+
+// Stop cancels the running jobs and waits for them to return
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.cancel()
+	// a Start that comes later returns at once
+	if !s.started.Load() {
+		return nil
+	}
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	defer pkg.Recover(nil)
+
+	if e.Misfire == CatchUp {
+		s.tick(ctx, e, time.Now(), true)
+	}
+	for {
+		next := e.sched.Next(time.Now())
+		if next.IsZero() {
+			s.log.Warnf("job %s never runs, schedule: %s", e.Name, e.Schedule)
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.tick(ctx, e, next, false)
+	}
+}
+
+// tick runs the job for the ticks due at now, on the replica holding the job lock. The lock is held
+// while the job runs, so a run lasting past the next tick makes the other replicas skip it.
+// The run is cancelled if the lock is lost, its context carries the lease and its fencing token, see lock.FromContext.
+func (s *Scheduler) tick(ctx context.Context, e *entry, now time.Time, startup bool) {
+	lease, err := lock.LeaseWithKey(ctx, s.lockKey(e.Name), s.rdb)
+	if err != nil {
+		s.log.Warnf("lock job %s failed, err: %+v", e.Name, err)
+		return
+	}
+	if lease == nil {
+		s.log.Debugf("job %s is running on another replica, skip", e.Name)
+		return
+	}
+	defer func() {
+		if err := lease.Err(); err != nil {
+			s.log.Errorf("job %s lost its lock, err: %+v", e.Name, err)
+		}
+		if _, err := lease.Release(context.WithoutCancel(ctx)); err != nil {
+			s.log.Warnf("unlock job %s failed, err: %+v", e.Name, err)
+		}
+	}()
+	ctx = lease.Context()
+
+	last, err := s.lastRun(ctx, e)
+	if err != nil {
+		s.log.Warnf("read last run of job %s failed, err: %+v", e.Name, err)
+		return
+	}
+	var due []time.Time
+	switch {
+	case last.IsZero() && startup:
+		return
+	case last.IsZero():
+		due = []time.Time{now}
+	default:
+		limit := 1
+		if e.Misfire == CatchUp {
+			limit = maxCatchUp
+		}
+		var missed int
+		due, missed = dueTimes(e.sched, last, now, limit)
+		if missed > 0 {
+			missedTotal.Add(float64(missed), e.Name)
+			s.log.Warnf("job %s missed %d runs since %s, policy: %s", e.Name, missed, last.Format(time.RFC3339), e.Misfire)
+		}
+	}
+
+	for _, at := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		s.run(ctx, e, at)
+		if ctx.Err() != nil {
+			// stopped or lost the lock mid run, leave it due
+			return
+		}
+		// a failed run is not retried, the error is in the history
+		if err := s.setLastRun(ctx, e, at); err != nil {
+			s.log.Warnf("save last run of job %s failed, err: %+v", e.Name, err)
+		}
+	}
+}
+
+// dueTimes lists the ticks after last up to now, keeping the latest limit of them, and counts the dropped ones
+func dueTimes(sched robfig.Schedule, last, now time.Time, limit int) (due []time.Time, dropped int) {
+	for t := sched.Next(last); !t.IsZero() && !t.After(now); t = sched.Next(t) {
+		due = append(due, t)
+		if len(due) > limit {
+			due = due[1:]
+			dropped++
+		}
+	}
+	return due, dropped
+}
+
+func (s *Scheduler) run(ctx context.Context, e *entry, at time.Time) {
+	var cancel context.CancelFunc
+	if e.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+	ctx = context.WithValue(ctx, scheduledKey{}, at)
+
+	start := time.Now()
+	err := e.call(ctx)
+	elapsed := time.Since(start)
+	runDuration.Observe(elapsed.Seconds(), e.Name)
+
+	r := &Run{
+		Job:       e.Name,
+		Scheduled: at,
+		Started:   start,
+		Duration:  elapsed.String(),
+		Replica:   s.opts.replica,
+	}
+	if err != nil {
+		runsTotal.Inc(e.Name, "failed")
+		r.Error = err.Error()
+		s.log.Errorf("job %s failed, scheduled: %s, err: %+v", e.Name, at.Format(time.RFC3339), err)
+	} else {
+		runsTotal.Inc(e.Name, "ok")
+		s.log.Infof("job %s done, scheduled: %s, took: %s", e.Name, at.Format(time.RFC3339), elapsed)
+	}
+	if err := s.record(context.WithoutCancel(ctx), r); err != nil {
+		s.log.Warnf("record run of job %s failed, err: %+v", e.Name, err)
+	}
+}
+
+func (e *entry) call(ctx context.Context) (err error) {
+	defer pkg.Recover(func() {
+		err = errors.Errorf("job %s panicked", e.Name)
+	})
+	return e.Run(ctx)
+}
+
+func (s *Scheduler) lockKey(job string) string {
+	return "lock:" + s.opts.prefix + job
+}
+
+func (s *Scheduler) lastKey(job string) string {
+	return s.opts.prefix + job + ":last"
+}
+
+func (s *Scheduler) lastRun(ctx context.Context, e *entry) (time.Time, error) {
+	v, err := s.rdb.Get(ctx, s.lastKey(e.Name)).Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	return t, errors.Wrapf(err, "parse last run %q failed", v)
+}
+
+func (s *Scheduler) setLastRun(ctx context.Context, e *entry, at time.Time) error {
+	return s.rdb.Set(context.WithoutCancel(ctx), s.lastKey(e.Name), at.Format(time.RFC3339Nano), 0).Err()
+}
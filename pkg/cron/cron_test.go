@@ -0,0 +1,98 @@
+package cron
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDueTimes(t *testing.T) {
+	hourly, err := parser.Parse("0 * * * *")
+	require.NoError(t, err)
+	every, err := parser.Parse("@every 1h")
+	require.NoError(t, err)
+
+	at := func(hour, min int) time.Time {
+		return time.Date(2024, 1, 1, hour, min, 0, 0, time.Local)
+	}
+	tests := []struct {
+		name        string
+		schedule    string
+		last        time.Time
+		now         time.Time
+		limit       int
+		wantDue     []time.Time
+		wantDropped int
+	}{
+		{name: "on time", last: at(1, 0), now: at(2, 0), limit: 1, wantDue: []time.Time{at(2, 0)}},
+		{name: "already run", last: at(2, 0), now: at(2, 0), limit: 1},
+		{name: "skip", last: at(1, 0), now: at(4, 0), limit: 1, wantDue: []time.Time{at(4, 0)}, wantDropped: 2},
+		{name: "catch up", last: at(1, 0), now: at(4, 0), limit: 10, wantDue: []time.Time{at(2, 0), at(3, 0), at(4, 0)}},
+		{name: "catch up limit", last: at(1, 0), now: at(4, 0), limit: 2, wantDue: []time.Time{at(3, 0), at(4, 0)}, wantDropped: 1},
+		{name: "started late", last: at(1, 0), now: at(2, 30), limit: 10, wantDue: []time.Time{at(2, 0)}},
+		// another replica ran it 20m ago, the tick of this replica is not due
+		{name: "every not due", schedule: "every", last: at(1, 0), now: at(1, 20), limit: 1},
+		{name: "every due", schedule: "every", last: at(1, 0), now: at(2, 20), limit: 1, wantDue: []time.Time{at(2, 0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sched := hourly
+			if tt.schedule == "every" {
+				sched = every
+			}
+			due, dropped := dueTimes(sched, tt.last, tt.now, tt.limit)
+			require.Equal(t, tt.wantDue, due)
+			require.Equal(t, tt.wantDropped, dropped)
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	s := New(nil)
+	require.NoError(t, s.Add(Job{Name: "a", Schedule: "*/5 * * * *"}))
+	require.NoError(t, s.Add(Job{Name: "b", Schedule: "30 0 3 * * *"}))
+	require.NoError(t, s.Add(Job{Name: "c", Schedule: "@every 90m"}))
+	require.Error(t, s.Add(Job{Name: "d", Schedule: "every hour"}))
+	require.Len(t, s.entries, 3)
+}
+
+func TestCallRecoversPanic(t *testing.T) {
+	e := &entry{Job: Job{Name: "a", Run: func(ctx context.Context) error { panic("boom") }}}
+	require.EqualError(t, e.call(context.Background()), "job a panicked")
+}
+
+func TestStartStop(t *testing.T) {
+	m := miniredis.RunT(t)
+	s := New(redis.NewClient(&redis.Options{Addr: m.Addr()}), WithLogger(log.DefaultLogger))
+	require.NoError(t, s.Add(Job{Name: "a", Schedule: "@every 1h", Run: func(ctx context.Context) error { return nil }}))
+
+	done := make(chan error)
+	go func() { done <- s.Start(context.Background()) }()
+	require.NoError(t, s.Stop(context.Background()))
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+}
+
+func TestStopBeforeStart(t *testing.T) {
+	m := miniredis.RunT(t)
+	s := New(redis.NewClient(&redis.Options{Addr: m.Addr()}), WithLogger(log.DefaultLogger))
+	require.NoError(t, s.Stop(context.Background()))
+
+	done := make(chan error)
+	go func() { done <- s.Start(context.Background()) }()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+}
@@ -0,0 +1,106 @@
+package cron
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Run is a run of a job, kept in redis so the history covers every replica
+type Run struct {
+	Job       string    `json:"job"`
+	Scheduled time.Time `json:"scheduled"`
+	Started   time.Time `json:"started"`
+	Duration  string    `json:"duration"`
+	Replica   string    `json:"replica"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// JobState is a job with its latest runs, newest first
+type JobState struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule"`
+	Misfire  string     `json:"misfire"`
+	Timeout  string     `json:"timeout,omitempty"`
+	LastRun  *time.Time `json:"lastRun,omitempty"`
+	NextRun  time.Time  `json:"nextRun"`
+	History  []*Run     `json:"history"`
+}
+
+func (s *Scheduler) historyKey(job string) string {
+	return s.opts.prefix + job + ":history"
+}
+
+func (s *Scheduler) record(ctx context.Context, r *Run) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return errors.Wrap(err, "marshal run failed")
+	}
+	key := s.historyKey(r.Job)
+	pipe := s.rdb.Pipeline()
+	pipe.LPush(ctx, key, b)
+	pipe.LTrim(ctx, key, 0, s.opts.historySize-1)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Jobs returns the jobs with their latest runs
+func (s *Scheduler) Jobs(ctx context.Context) ([]*JobState, error) {
+	now := time.Now()
+	states := make([]*JobState, 0, len(s.entries))
+	for _, e := range s.entries {
+		state := &JobState{
+			Name:     e.Name,
+			Schedule: e.Schedule,
+			Misfire:  e.Misfire.String(),
+			NextRun:  e.sched.Next(now),
+			History:  []*Run{},
+		}
+		if e.Timeout > 0 {
+			state.Timeout = e.Timeout.String()
+		}
+		last, err := s.lastRun(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+		if !last.IsZero() {
+			state.LastRun = &last
+		}
+		list, err := s.rdb.LRange(ctx, s.historyKey(e.Name), 0, s.opts.historySize-1).Result()
+		if err != nil {
+			return nil, errors.Wrapf(err, "read history of job %s failed", e.Name)
+		}
+		for _, v := range list {
+			r := &Run{}
+			if err := json.Unmarshal([]byte(v), r); err != nil {
+				continue
+			}
+			state.History = append(state.History, r)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// Handler serves the jobs and their run history as json on GET
+func (s *Scheduler) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "method not allowed"})
+			return
+		}
+		jobs, err := s.Jobs(r.Context())
+		if err != nil {
+			s.log.Warnf("read cron jobs failed, err: %+v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "read jobs failed"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"jobs": jobs})
+	})
+}
@@ -0,0 +1,23 @@
+package feature
+
+import (
+	"maps"
+	"sync/atomic"
+)
+
+var flags atomic.Pointer[map[string]bool]
+
+// Set replaces every flag, it is called at startup and on config reload
+func Set(m map[string]bool) {
+	m = maps.Clone(m)
+	flags.Store(&m)
+}
+
+// Enabled reports whether the flag is on, unknown flags are off
+func Enabled(name string) bool {
+	m := flags.Load()
+	if m == nil {
+		return false
+	}
+	return (*m)[name]
+}
@@ -0,0 +1,180 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp           = "up"
+	StatusDown         = "down"
+	StatusShuttingDown = "shutting_down"
+
+	defaultTimeout  = time.Second
+	defaultCacheTTL = time.Second * 2
+)
+
+// Checker is a dependency readiness depends on
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checker struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (c checker) Name() string { return c.name }
+
+func (c checker) Check(ctx context.Context) error { return c.check(ctx) }
+
+// NewChecker adapts a ping function, eg: health.NewChecker("mysql_master", db.PingContext)
+func NewChecker(name string, check func(ctx context.Context) error) Checker {
+	return checker{name: name, check: check}
+}
+
+type Component struct {
+	Status  string `json:"status"`
+	Latency string `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status     string               `json:"status"`
+	Components map[string]Component `json:"components,omitempty"`
+}
+
+type options struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+}
+
+type Option func(*options)
+
+// WithTimeout bounds every single check
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithCacheTTL reuses a report for d, so frequent probes don't hammer the dependencies
+func WithCacheTTL(d time.Duration) Option {
+	return func(o *options) {
+		o.cacheTTL = d
+	}
+}
+
+// Health serves liveness and readiness, readiness runs every registered checker concurrently
+type Health struct {
+	opts     options
+	checkers []Checker
+	shutdown atomic.Bool
+
+	mu        sync.Mutex
+	report    Report
+	checkedAt time.Time
+}
+
+func New(opts ...Option) *Health {
+	o := options{timeout: defaultTimeout, cacheTTL: defaultCacheTTL}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Health{opts: o}
+}
+
+// Register adds checkers, call it before serving
+func (h *Health) Register(checkers ...Checker) {
+	h.checkers = append(h.checkers, checkers...)
+}
+
+// Shutdown flips readiness to down for good, so the load balancer drains this instance before the listeners close
+func (h *Health) Shutdown() {
+	h.shutdown.Store(true)
+}
+
+// Ready returns the cached report or checks every dependency when it is older than the cache ttl
+func (h *Health) Ready(ctx context.Context) Report {
+	if h.shutdown.Load() {
+		return Report{Status: StatusShuttingDown}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.checkedAt.IsZero() && time.Since(h.checkedAt) < h.opts.cacheTTL {
+		return h.report
+	}
+
+	components := make(map[string]Component, len(h.checkers))
+	var (
+		wg  sync.WaitGroup
+		cmu sync.Mutex
+	)
+	for _, c := range h.checkers {
+		wg.Add(1)
+		go func(c Checker) {
+			defer wg.Done()
+			comp := h.check(ctx, c)
+			cmu.Lock()
+			components[c.Name()] = comp
+			cmu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Components: components}
+	for _, comp := range components {
+		if comp.Status != StatusUp {
+			report.Status = StatusDown
+			break
+		}
+	}
+	h.report, h.checkedAt = report, time.Now()
+	return report
+}
+
+func (h *Health) check(ctx context.Context, c Checker) Component {
+	// the result is cached for other probes, a caller that went away must not fail it
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), h.opts.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.Check(ctx)
+	comp := Component{Status: StatusUp, Latency: time.Since(start).String()}
+	if err != nil {
+		comp.Status, comp.Error = StatusDown, err.Error()
+	}
+	return comp
+}
+
+// LivenessHandler answers as long as the process can serve http
+func (h *Health) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, Report{Status: StatusUp})
+	})
+}
+
+// ReadinessHandler answers 503 when a dependency is down or the server is shutting down
+func (h *Health) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := h.Ready(r.Context())
+		code := http.StatusOK
+		if report.Status != StatusUp {
+			code = http.StatusServiceUnavailable
+		}
+		writeJSON(w, code, report)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,84 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReady(t *testing.T) {
+	slow := NewChecker("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	up := NewChecker("up", func(ctx context.Context) error { return nil })
+	down := NewChecker("down", func(ctx context.Context) error { return errors.New("connection refused") })
+
+	tests := []struct {
+		name     string
+		checkers []Checker
+		shutdown bool
+		want     string
+		code     int
+	}{
+		{
+			name:     "all up",
+			checkers: []Checker{up},
+			want:     StatusUp,
+			code:     http.StatusOK,
+		},
+		{
+			name:     "one down",
+			checkers: []Checker{up, down},
+			want:     StatusDown,
+			code:     http.StatusServiceUnavailable,
+		},
+		{
+			name:     "timeout",
+			checkers: []Checker{up, slow},
+			want:     StatusDown,
+			code:     http.StatusServiceUnavailable,
+		},
+		{
+			name:     "shutting down",
+			checkers: []Checker{up},
+			shutdown: true,
+			want:     StatusShuttingDown,
+			code:     http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(WithTimeout(time.Millisecond * 10))
+			h.Register(tt.checkers...)
+			if tt.shutdown {
+				h.Shutdown()
+			}
+			require.Equal(t, tt.want, h.Ready(context.Background()).Status)
+
+			rec := httptest.NewRecorder()
+			h.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+			require.Equal(t, tt.code, rec.Code)
+		})
+	}
+}
+
+func TestReadyCache(t *testing.T) {
+	var calls atomic.Int32
+	h := New(WithCacheTTL(time.Minute))
+	h.Register(NewChecker("counted", func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}))
+
+	h.Ready(context.Background())
+	h.Ready(context.Background())
+	require.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,123 @@
+package limiter
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+// gcra is a token bucket expressed as the theoretical arrival time (TAT) of the next request,
+// the bucket is one key holding the TAT in ms, so every check is a single atomic script.
+// KEYS[1] bucket key, ARGV limit, period ms, burst, cost.
+// Returns allowed, remaining, retry after ms, reset after ms.
+const gcra = `
+redis.replicate_commands()
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local interval = period / limit
+local tat = tonumber(redis.call('GET', KEYS[1])) or now
+if tat < now then
+  tat = now
+end
+local newTat = tat + interval * cost
+local diff = now - (newTat - interval * burst)
+if diff < 0 then
+  return {0, 0, math.ceil(-diff), math.ceil(tat - now)}
+end
+local reset = math.ceil(newTat - now)
+redis.call('SET', KEYS[1], string.format('%.3f', newTat), 'PX', reset)
+return {1, math.floor(diff / interval), 0, reset}
+`
+
+// refund gives back cost tokens taken by gcra, a bucket that is full again is deleted.
+// KEYS[1] bucket key, ARGV limit, period ms, cost.
+const gcraRefund = `
+redis.replicate_commands()
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if not tat then
+  return 0
+end
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+tat = tat - tonumber(ARGV[2]) / tonumber(ARGV[1]) * tonumber(ARGV[3])
+if tat <= now then
+  redis.call('DEL', KEYS[1])
+  return 1
+end
+redis.call('SET', KEYS[1], string.format('%.3f', tat), 'PX', math.ceil(tat - now))
+return 1
+`
+
+var (
+	gcraScript   = redis.NewScript(gcra)
+	refundScript = redis.NewScript(gcraRefund)
+)
+
+// Limit allows Rate requests per Period with bursts of up to Burst requests
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	Burst  int
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      Limit
+	Remaining  int
+	RetryAfter time.Duration // zero when allowed
+	ResetAfter time.Duration // until the bucket is full again
+}
+
+// Limiter is a distributed rate limiter shared by all replicas through redis
+type Limiter struct {
+	rdb redis.UniversalClient
+}
+
+func New(rdb redis.UniversalClient) *Limiter {
+	return &Limiter{rdb: rdb}
+}
+
+// Allow takes one token from the bucket of key
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	if limit.Rate <= 0 || limit.Period <= 0 {
+		return nil, errors.Errorf("invalid limit, rate: %d, period: %s", limit.Rate, limit.Period)
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = limit.Rate
+	}
+
+	res, err := gcraScript.Run(ctx, l.rdb, []string{key},
+		limit.Rate, limit.Period.Milliseconds(), limit.Burst, 1,
+	).Int64Slice()
+	if err != nil {
+		return nil, errors.Wrapf(err, "rate limit key: %s failed", key)
+	}
+	if len(res) != 4 {
+		return nil, errors.Errorf("rate limit key: %s unexpected result: %v", key, res)
+	}
+
+	return &Result{
+		Allowed:    res[0] == 1,
+		Limit:      limit,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+		ResetAfter: time.Duration(res[3]) * time.Millisecond,
+	}, nil
+}
+
+// Refund gives back the token taken by an allowed Allow, eg: when another limit rejected the request
+func (l *Limiter) Refund(ctx context.Context, key string, limit Limit) error {
+	if limit.Rate <= 0 || limit.Period <= 0 {
+		return errors.Errorf("invalid limit, rate: %d, period: %s", limit.Rate, limit.Period)
+	}
+	if err := refundScript.Run(ctx, l.rdb, []string{key}, limit.Rate, limit.Period.Milliseconds(), 1).Err(); err != nil {
+		return errors.Wrapf(err, "refund rate limit key: %s failed", key)
+	}
+	return nil
+}
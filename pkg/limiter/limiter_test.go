@@ -0,0 +1,75 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(t *testing.T) (*Limiter, *miniredis.Miniredis) {
+	m := miniredis.RunT(t)
+	m.SetTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	return New(redis.NewClient(&redis.Options{Addr: m.Addr()})), m
+}
+
+func TestAllow(t *testing.T) {
+	l, m := newTestLimiter(t)
+	ctx := context.Background()
+	limit := Limit{Rate: 2, Period: time.Second}
+
+	for _, remaining := range []int{1, 0} {
+		res, err := l.Allow(ctx, "k", limit)
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+		require.Equal(t, remaining, res.Remaining)
+		require.Zero(t, res.RetryAfter)
+	}
+
+	res, err := l.Allow(ctx, "k", limit)
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, 500*time.Millisecond, res.RetryAfter)
+	require.Equal(t, time.Second, res.ResetAfter)
+
+	// one token is back after period/rate
+	m.SetTime(time.Date(2024, 1, 1, 0, 0, 0, int(500*time.Millisecond), time.UTC))
+	res, err = l.Allow(ctx, "k", limit)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, 0, res.Remaining)
+
+	res, err = l.Allow(ctx, "other", limit)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	_, err = l.Allow(ctx, "k", Limit{Rate: 0, Period: time.Second})
+	require.Error(t, err)
+}
+
+func TestRefund(t *testing.T) {
+	l, m := newTestLimiter(t)
+	ctx := context.Background()
+	limit := Limit{Rate: 2, Period: time.Second}
+
+	require.NoError(t, l.Refund(ctx, "k", limit))
+	require.False(t, m.Exists("k"))
+
+	for _, remaining := range []int{1, 0} {
+		res, err := l.Allow(ctx, "k", limit)
+		require.NoError(t, err)
+		require.Equal(t, remaining, res.Remaining)
+	}
+	require.NoError(t, l.Refund(ctx, "k", limit))
+	res, err := l.Allow(ctx, "k", limit)
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	// a bucket that is full again is removed
+	require.NoError(t, l.Refund(ctx, "k", limit))
+	require.NoError(t, l.Refund(ctx, "k", limit))
+	require.False(t, m.Exists("k"))
+}
@@ -0,0 +1,229 @@
+package limiter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"server-template/pkg/log"
+	"server-template/pkg/middleware/auth"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+
+	// AnyOperation matches every operation
+	AnyOperation = "*"
+
+	keyPrefix = "ratelimit:"
+	// allowTimeout bounds the redis round trip, a slow redis fails open instead of slowing every request
+	allowTimeout = time.Millisecond * 200
+)
+
+var ErrLimitExceed = errors.New(429, "RATELIMIT", "rate limit exceeded")
+
+// IdentityFunc returns who the request is counted against, false skips the rule
+type IdentityFunc func(ctx context.Context) (string, bool)
+
+type Rule struct {
+	Operation string // AnyOperation matches every operation
+	Name      string // name of the identity, part of the bucket key
+	Identity  IdentityFunc
+	Limit     Limit
+}
+
+// bucket is a token taken for a rule
+type bucket struct {
+	key  string
+	rule Rule
+}
+
+// RuleSet holds the rules by operation, they can be replaced while serving
+type RuleSet struct {
+	byOp atomic.Pointer[map[string][]Rule]
+}
+
+func NewRuleSet(rules []Rule) *RuleSet {
+	s := &RuleSet{}
+	s.Store(rules)
+	return s
+}
+
+// Store replaces every rule, an empty set disables limiting
+func (s *RuleSet) Store(rules []Rule) {
+	byOp := make(map[string][]Rule)
+	for _, r := range rules {
+		byOp[r.Operation] = append(byOp[r.Operation], r)
+	}
+	s.byOp.Store(&byOp)
+}
+
+// Server is a middleware that counts each request against every matching rule
+// and rejects it when any bucket is empty. A rejected request gives back the tokens
+// it took from the buckets checked before, so it doesn't count against them.
+// Redis errors let the request through.
+func Server(l *Limiter, rules *RuleSet) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			byOp := *rules.byOp.Load()
+			ops, all := byOp[tr.Operation()], byOp[AnyOperation]
+			if len(ops)+len(all) == 0 {
+				return handler(ctx, req)
+			}
+
+			var (
+				strictest *Result
+				taken     []bucket
+			)
+			for _, r := range append(append(make([]Rule, 0, len(ops)+len(all)), ops...), all...) {
+				id, ok := r.Identity(ctx)
+				if !ok {
+					continue
+				}
+				key := bucketKey(tr.Operation(), r, id)
+				res, err := allow(ctx, l, key, r.Limit)
+				if err != nil {
+					log.WithContext(ctx).Warnf("rate limit %s by %s failed, let it through, err: %v", tr.Operation(), r.Name, err)
+					continue
+				}
+				if strictest == nil || !res.Allowed || res.Remaining < strictest.Remaining {
+					strictest = res
+				}
+				if !res.Allowed {
+					break
+				}
+				taken = append(taken, bucket{key: key, rule: r})
+			}
+			if strictest == nil {
+				return handler(ctx, req)
+			}
+
+			setHeaders(tr.ReplyHeader(), strictest)
+			if !strictest.Allowed {
+				for _, b := range taken {
+					if err := refund(ctx, l, b.key, b.rule.Limit); err != nil {
+						log.WithContext(ctx).Warnf("refund rate limit %s by %s failed, err: %v", tr.Operation(), b.rule.Name, err)
+					}
+				}
+				return nil, ErrLimitExceed
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func allow(ctx context.Context, l *Limiter, key string, limit Limit) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, allowTimeout)
+	defer cancel()
+	return l.Allow(ctx, key, limit)
+}
+
+func refund(ctx context.Context, l *Limiter, key string, limit Limit) error {
+	ctx, cancel := context.WithTimeout(ctx, allowTimeout)
+	defer cancel()
+	return l.Refund(ctx, key, limit)
+}
+
+// bucketKey hashes the identity so api keys never show up in redis,
+// the limit is part of the key so a changed rule starts with a full bucket
+func bucketKey(operation string, r Rule, id string) string {
+	if r.Operation == AnyOperation {
+		operation = AnyOperation
+	}
+	sum := sha256.Sum256([]byte(id))
+	return fmt.Sprintf("%s%s:%s:%d/%s:%s", keyPrefix, operation, r.Name,
+		r.Limit.Rate, r.Limit.Period, hex.EncodeToString(sum[:16]))
+}
+
+func setHeaders(h transport.Header, res *Result) {
+	h.Set(HeaderLimit, strconv.Itoa(res.Limit.Rate))
+	h.Set(HeaderRemaining, strconv.Itoa(res.Remaining))
+	h.Set(HeaderReset, seconds(res.ResetAfter))
+	if !res.Allowed {
+		h.Set(HeaderRetryAfter, seconds(res.RetryAfter))
+	}
+}
+
+// seconds rounds up, so a client waiting that long is never rejected again
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// IdentityIP counts by client ip. With trustProxy the last X-Forwarded-For entry or X-Real-IP is used,
+// only enable it behind a proxy that appends to or sets these headers. The entries left of the last one
+// come from the client and are never trusted.
+func IdentityIP(trustProxy bool) IdentityFunc {
+	return func(ctx context.Context) (string, bool) {
+		if ht, ok := transport.FromServerContext(ctx); ok {
+			if trustProxy {
+				xff := ht.RequestHeader().Get("X-Forwarded-For")
+				if i := strings.LastIndexByte(xff, ','); i >= 0 {
+					xff = xff[i+1:]
+				}
+				if ip := strings.TrimSpace(xff); ip != "" {
+					return ip, true
+				}
+				if ip := ht.RequestHeader().Get("X-Real-IP"); ip != "" {
+					return ip, true
+				}
+			}
+			if ht, ok := ht.(khttp.Transporter); ok {
+				return host(ht.Request().RemoteAddr), true
+			}
+		}
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			return host(p.Addr.String()), true
+		}
+		return "", false
+	}
+}
+
+// IdentityUser counts by the jwt subject, anonymous requests are skipped
+func IdentityUser() IdentityFunc {
+	return func(ctx context.Context) (string, bool) {
+		claims, ok := auth.FromContext(ctx)
+		if !ok || claims.Subject == "" {
+			return "", false
+		}
+		return claims.Subject, true
+	}
+}
+
+// IdentityHeader counts by a request header such as X-API-Key, requests without it are skipped
+func IdentityHeader(key string) IdentityFunc {
+	return func(ctx context.Context) (string, bool) {
+		tr, ok := transport.FromServerContext(ctx)
+		if !ok {
+			return "", false
+		}
+		v := tr.RequestHeader().Get(key)
+		return v, v != ""
+	}
+}
+
+func host(addr string) string {
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		return h
+	}
+	return addr
+}
@@ -0,0 +1,224 @@
+package limiter
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/peer"
+)
+
+type headerCarrier map[string]string
+
+func (h headerCarrier) Get(key string) string { return h[key] }
+func (h headerCarrier) Set(key, value string) { h[key] = value }
+func (h headerCarrier) Add(key, value string) { h[key] = value }
+func (h headerCarrier) Keys() []string        { return nil }
+func (h headerCarrier) Values(string) []string {
+	return nil
+}
+
+type testTransport struct {
+	operation string
+	header    headerCarrier
+	reply     headerCarrier
+}
+
+func (t *testTransport) Kind() transport.Kind            { return transport.KindGRPC }
+func (t *testTransport) Endpoint() string                { return "" }
+func (t *testTransport) Operation() string               { return t.operation }
+func (t *testTransport) RequestHeader() transport.Header { return t.header }
+func (t *testTransport) ReplyHeader() transport.Header   { return t.reply }
+
+func identity(id string) IdentityFunc {
+	return func(context.Context) (string, bool) { return id, id != "" }
+}
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation string
+		rules     []Rule
+		calls     int
+		wantErr   bool
+		wantReply headerCarrier
+	}{
+		{
+			name:      "no rule",
+			operation: "/a",
+			rules:     []Rule{{Operation: "/b", Name: "ip", Identity: identity("1"), Limit: Limit{Rate: 1, Period: time.Minute}}},
+			calls:     2,
+			wantReply: headerCarrier{},
+		},
+		{
+			name:      "operation",
+			operation: "/a",
+			rules:     []Rule{{Operation: "/a", Name: "ip", Identity: identity("1"), Limit: Limit{Rate: 2, Period: time.Minute}}},
+			calls:     2,
+			wantReply: headerCarrier{HeaderLimit: "2", HeaderRemaining: "0", HeaderReset: "60"},
+		},
+		{
+			name:      "any operation",
+			operation: "/a",
+			rules:     []Rule{{Operation: AnyOperation, Name: "ip", Identity: identity("1"), Limit: Limit{Rate: 1, Period: time.Minute}}},
+			calls:     2,
+			wantErr:   true,
+			wantReply: headerCarrier{HeaderLimit: "1", HeaderRemaining: "0", HeaderReset: "60", HeaderRetryAfter: "60"},
+		},
+		{
+			name:      "strictest rule",
+			operation: "/a",
+			rules: []Rule{
+				{Operation: "/a", Name: "ip", Identity: identity("1"), Limit: Limit{Rate: 10, Period: time.Minute}},
+				{Operation: AnyOperation, Name: "user", Identity: identity("1"), Limit: Limit{Rate: 3, Period: time.Minute}},
+			},
+			calls:     1,
+			wantReply: headerCarrier{HeaderLimit: "3", HeaderRemaining: "2", HeaderReset: "20"},
+		},
+		{
+			name:      "identity skipped",
+			operation: "/a",
+			rules:     []Rule{{Operation: "/a", Name: "user", Identity: identity(""), Limit: Limit{Rate: 1, Period: time.Minute}}},
+			calls:     2,
+			wantReply: headerCarrier{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestLimiter(t)
+			h := Server(l, NewRuleSet(tt.rules))(func(ctx context.Context, req any) (any, error) {
+				return "ok", nil
+			})
+
+			var (
+				tr  *testTransport
+				err error
+			)
+			for i := 0; i < tt.calls; i++ {
+				tr = &testTransport{operation: tt.operation, header: headerCarrier{}, reply: headerCarrier{}}
+				_, err = h(transport.NewServerContext(context.Background(), tr), nil)
+			}
+			require.Equal(t, tt.wantErr, err != nil, err)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrLimitExceed)
+			}
+			require.Equal(t, tt.wantReply, tr.reply)
+		})
+	}
+}
+
+func TestServerRefund(t *testing.T) {
+	l, _ := newTestLimiter(t)
+	byOp := Rule{Operation: "/a", Name: "ip", Identity: identity("1"), Limit: Limit{Rate: 10, Period: time.Minute}}
+	anyOp := Rule{Operation: AnyOperation, Name: "user", Identity: identity("1"), Limit: Limit{Rate: 1, Period: time.Minute}}
+	h := Server(l, NewRuleSet([]Rule{byOp, anyOp}))(func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+
+	for i, wantErr := range []bool{false, true, true} {
+		tr := &testTransport{operation: "/a", header: headerCarrier{}, reply: headerCarrier{}}
+		_, err := h(transport.NewServerContext(context.Background(), tr), nil)
+		require.Equal(t, wantErr, err != nil, "call %d: %v", i, err)
+	}
+
+	// only the allowed request took a token from the bucket of the first rule
+	res, err := l.Allow(context.Background(), bucketKey("/a", byOp, "1"), byOp.Limit)
+	require.NoError(t, err)
+	require.Equal(t, 8, res.Remaining)
+}
+
+// slowHook blocks every command until its context is done, like a redis that doesn't answer
+type slowHook struct{}
+
+func (slowHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	<-ctx.Done()
+	return ctx, ctx.Err()
+}
+
+func (slowHook) AfterProcess(context.Context, redis.Cmder) error { return nil }
+
+func (slowHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (slowHook) AfterProcessPipeline(context.Context, []redis.Cmder) error { return nil }
+
+func TestServerFailOpen(t *testing.T) {
+	rules := NewRuleSet([]Rule{{Operation: AnyOperation, Name: "ip", Identity: identity("1"), Limit: Limit{Rate: 1, Period: time.Minute}}})
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+
+	t.Run("error", func(t *testing.T) {
+		l, m := newTestLimiter(t)
+		m.SetError("LOADING redis is loading")
+		tr := &testTransport{operation: "/a", header: headerCarrier{}, reply: headerCarrier{}}
+		reply, err := Server(l, rules)(handler)(transport.NewServerContext(context.Background(), tr), nil)
+		require.NoError(t, err)
+		require.Equal(t, "ok", reply)
+		require.Empty(t, tr.reply)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		l, _ := newTestLimiter(t)
+		l.rdb.AddHook(slowHook{})
+		tr := &testTransport{operation: "/a", header: headerCarrier{}, reply: headerCarrier{}}
+		start := time.Now()
+		reply, err := Server(l, rules)(handler)(transport.NewServerContext(context.Background(), tr), nil)
+		require.NoError(t, err)
+		require.Equal(t, "ok", reply)
+		require.Less(t, time.Since(start), allowTimeout*5)
+	})
+}
+
+func TestBucketKey(t *testing.T) {
+	limit := Limit{Rate: 10, Period: time.Minute}
+	byOp := bucketKey("/a", Rule{Operation: "/a", Name: "ip", Limit: limit}, "1.2.3.4")
+	require.Equal(t, "ratelimit:/a:ip:10/1m0s:6694f83c9f476da31f5df6bcc520034e", byOp)
+
+	// the rules on every operation share one bucket
+	anyOp := bucketKey("/a", Rule{Operation: AnyOperation, Name: "ip", Limit: limit}, "1.2.3.4")
+	require.Equal(t, anyOp, bucketKey("/b", Rule{Operation: AnyOperation, Name: "ip", Limit: limit}, "1.2.3.4"))
+	require.Equal(t, "ratelimit:*:ip:10/1m0s:6694f83c9f476da31f5df6bcc520034e", anyOp)
+
+	// a changed limit starts with a new bucket
+	require.NotEqual(t, byOp, bucketKey("/a", Rule{Operation: "/a", Name: "ip", Limit: Limit{Rate: 20, Period: time.Minute}}, "1.2.3.4"))
+}
+
+func TestSetHeaders(t *testing.T) {
+	h := headerCarrier{}
+	setHeaders(h, &Result{Allowed: true, Limit: Limit{Rate: 5}, Remaining: 3, ResetAfter: 1500 * time.Millisecond})
+	require.Equal(t, headerCarrier{HeaderLimit: "5", HeaderRemaining: "3", HeaderReset: "2"}, h)
+
+	h = headerCarrier{}
+	setHeaders(h, &Result{Limit: Limit{Rate: 5}, RetryAfter: time.Millisecond, ResetAfter: 2 * time.Second})
+	require.Equal(t, headerCarrier{HeaderLimit: "5", HeaderRemaining: "0", HeaderReset: "2", HeaderRetryAfter: "1"}, h)
+}
+
+func TestIdentityIP(t *testing.T) {
+	grpcPeer := &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4000}}
+	tests := []struct {
+		name       string
+		trustProxy bool
+		header     headerCarrier
+		want       string
+	}{
+		{name: "peer", header: headerCarrier{"X-Forwarded-For": "1.1.1.1"}, want: "10.0.0.1"},
+		{name: "last forwarded", trustProxy: true, header: headerCarrier{"X-Forwarded-For": "1.1.1.1, 2.2.2.2"}, want: "2.2.2.2"},
+		{name: "single forwarded", trustProxy: true, header: headerCarrier{"X-Forwarded-For": "2.2.2.2"}, want: "2.2.2.2"},
+		{name: "real ip", trustProxy: true, header: headerCarrier{"X-Real-IP": "3.3.3.3"}, want: "3.3.3.3"},
+		{name: "empty forwarded", trustProxy: true, header: headerCarrier{"X-Forwarded-For": "1.1.1.1, "}, want: "10.0.0.1"},
+		{name: "no header", trustProxy: true, header: headerCarrier{}, want: "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := peer.NewContext(context.Background(), grpcPeer)
+			ctx = transport.NewServerContext(ctx, &testTransport{header: tt.header})
+			ip, ok := IdentityIP(tt.trustProxy)(ctx)
+			require.True(t, ok)
+			require.Equal(t, tt.want, ip)
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package sync
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"server-template/pkg/log"
+	"server-template/pkg/retry"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+const (
+	// acquire sets the lock and increments its fence, the fence key is never expired.
+	// A missing fence key, the first acquisition or redis lost its data, starts at the redis time in
+	// microseconds, above every token handed out before as long as there were fewer than one per µs.
+	acquire = `
+redis.replicate_commands()
+if not redis.call('set', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+  return {0, 0}
+end
+local n = redis.call('incr', KEYS[2])
+if n > 1 then
+  return {n, 0}
+end
+local t = redis.call('time')
+local start = t[1] .. string.format('%06d', tonumber(t[2]))
+redis.call('set', KEYS[2], start)
+return {tonumber(start), 1}
+`
+	// compareAndExpire extends the lock only while it still holds our value
+	compareAndExpire = "if redis.call('get', KEYS[1]) == ARGV[1] then return redis.call('pexpire', KEYS[1], ARGV[2]) else return 0 end"
+
+	defaultLeaseTTL = time.Second * 30
+)
+
+var (
+	// ErrLeaseLost is the cause of a lease lost to expiry or to another holder
+	ErrLeaseLost = errors.New("lease lost")
+	// ErrLeaseReleased is the cause of a released lease
+	ErrLeaseReleased = errors.New("lease released")
+)
+
+type leaseKey struct{}
+
+// Lease is a held lock, renewed until released. When a renewal fails the lease is lost: Lost is closed
+// and Context is cancelled, stop the critical section as another holder may already be in it.
+//
+// Token is a fencing token, it grows with every acquisition of the key, also across a redis that lost
+// its data, provided the redis clock doesn't go back. Pass it to the writes of the critical section so
+// a stale holder's writes are rejected, eg:
+//
+//	UPDATE job SET state = ?, fence = ? WHERE id = ? AND fence <= ?
+//
+// Code called with the lease Context finds the key and the token with FromContext.
+type Lease struct {
+	key    string
+	value  string
+	token  int64
+	ttl    time.Duration
+	client redis.UniversalClient
+
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	lost     chan struct{}
+	lostOnce sync.Once
+	lostErr  error
+}
+
+// Acquire takes the lock of key for ttl in one attempt and renews it in the background until the lease is
+// released, lost or ctx is done. It returns a nil lease and no error when another holder has the lock,
+// use a Locker to wait for it.
+func Acquire(ctx context.Context, key string, ttl time.Duration, client redis.UniversalClient) (*Lease, error) {
+	return NewLocker(client, WithTTL(ttl)).obtain(ctx, ctx, key)
+}
+
+func newLease(ctx context.Context, client redis.UniversalClient, key, value string, token int64, ttl time.Duration) *Lease {
+	l := &Lease{
+		key:    key,
+		value:  value,
+		token:  token,
+		ttl:    ttl,
+		client: client,
+		lost:   make(chan struct{}),
+	}
+	l.ctx, l.cancel = context.WithCancelCause(context.WithValue(ctx, leaseKey{}, l))
+	go l.renew()
+	return l
+}
+
+// LeaseWithKey acquires key for 30s, retrying redis errors for up to 15s, renewed like Acquire.
+// Use a Locker for another ttl or to wait while the lock is busy.
+func LeaseWithKey(ctx context.Context, key string, client redis.UniversalClient) (lease *Lease, err error) {
+	err = retry.BackoffRetry(func() error {
+		if ctx.Err() != nil {
+			return retry.Permanent(ctx.Err())
+		}
+		lease, err = Acquire(ctx, key, defaultLeaseTTL, client)
+		if err != nil {
+			log.Warnf("lock %s failed, err: %+v, retry", key, err)
+		}
+		return err
+	})
+	return lease, err
+}
+
+// Token is the fencing token of the lease
+func (l *Lease) Token() int64 {
+	return l.token
+}
+
+// Key is the locked key
+func (l *Lease) Key() string {
+	return l.key
+}
+
+// Context is done when the lease is lost or released, it carries the lease, see FromContext
+func (l *Lease) Context() context.Context {
+	return l.ctx
+}
+
+// Lost is closed when a renewal fails
+func (l *Lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Err tells why the lease was lost, it is nil while the lease is held or once released
+func (l *Lease) Err() error {
+	select {
+	case <-l.lost:
+		return l.lostErr
+	default:
+		return nil
+	}
+}
+
+// Release stops the renewal and deletes the lock if it is still ours
+func (l *Lease) Release(ctx context.Context) (bool, error) {
+	l.cancel(ErrLeaseReleased)
+	res, err := l.client.Eval(ctx, compareAndDel, []string{l.key}, l.value).Int64()
+	if err != nil {
+		return false, errors.Wrapf(err, "release lock %s failed", l.key)
+	}
+	return res == 1, nil
+}
+
+// renew extends the lock every third of its ttl, it gives up once the lock is gone or would expire
+// before the next try
+func (l *Lease) renew() {
+	interval := l.ttl / 3
+	expireAt := time.Now().Add(l.ttl)
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), interval/2)
+		res, err := l.client.Eval(ctx, compareAndExpire, []string{l.key}, l.value, l.ttl.Milliseconds()).Int64()
+		cancel()
+		switch {
+		case err == nil && res == 1:
+			renewTotal.Inc("ok")
+			expireAt = start.Add(l.ttl)
+			timer.Reset(interval)
+		case err == nil:
+			renewTotal.Inc("failed")
+			l.lose(errors.Wrapf(ErrLeaseLost, "lock %s expired or taken by another holder", l.key))
+			return
+		default:
+			renewTotal.Inc("failed")
+			log.Warnf("renew lock %s failed, err: %+v", l.key, err)
+			retryIn := interval / 4
+			if time.Until(expireAt) <= retryIn {
+				l.lose(errors.Wrapf(ErrLeaseLost, "renew lock %s failed until expiry: %v", l.key, err))
+				return
+			}
+			timer.Reset(retryIn)
+		}
+	}
+}
+
+func (l *Lease) lose(err error) {
+	l.lostOnce.Do(func() {
+		lostTotal.Inc()
+		log.Warnf("%v", err)
+		l.lostErr = err
+		close(l.lost)
+		l.cancel(err)
+	})
+}
+
+// FromContext returns the lease whose Context ctx is or derives from
+func FromContext(ctx context.Context) (*Lease, bool) {
+	l, ok := ctx.Value(leaseKey{}).(*Lease)
+	return l, ok
+}
+
+// TokenFrom returns the fencing token of the lease whose Context ctx is or derives from
+func TokenFrom(ctx context.Context) (int64, bool) {
+	if l, ok := FromContext(ctx); ok {
+		return l.token, true
+	}
+	return 0, false
+}
+
+// fenceKey hashes to the slot of key, so the acquire script can touch both keys on a cluster
+func fenceKey(key string) string {
+	if i := strings.IndexByte(key, '{'); i >= 0 {
+		if j := strings.IndexByte(key[i+1:], '}'); j > 0 {
+			return key + ":fence"
+		}
+	}
+	if !strings.Contains(key, "}") {
+		return "{" + key + "}:fence"
+	}
+	// can't be tagged, fine on a single node, use a hash tag in such a key on a cluster
+	return key + ":fence"
+}
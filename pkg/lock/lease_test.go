@@ -0,0 +1,162 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T) (redis.UniversalClient, *miniredis.Miniredis) {
+	m := miniredis.RunT(t)
+	return redis.NewClient(&redis.Options{Addr: m.Addr()}), m
+}
+
+func TestFenceKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "lock:job", want: "{lock:job}:fence"},
+		{key: "lock:{job}", want: "lock:{job}:fence"},
+		{key: "{lock}:job", want: "{lock}:job:fence"},
+		// an empty tag hashes the whole key, which can't be tagged
+		{key: "lock:{}job", want: "lock:{}job:fence"},
+		{key: "lock:{job", want: "{lock:{job}:fence"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			require.Equal(t, tt.want, fenceKey(tt.key))
+		})
+	}
+}
+
+func TestLeaseLose(t *testing.T) {
+	l := &Lease{key: "lock:job", token: 7, lost: make(chan struct{})}
+	l.ctx, l.cancel = context.WithCancelCause(context.WithValue(context.Background(), leaseKey{}, l))
+	require.NoError(t, l.Err())
+
+	token, ok := TokenFrom(l.Context())
+	require.True(t, ok)
+	require.Equal(t, int64(7), token)
+
+	l.lose(errors.Wrap(ErrLeaseLost, "expired"))
+	l.lose(errors.New("twice"))
+	<-l.Lost()
+	require.ErrorIs(t, l.Err(), ErrLeaseLost)
+	require.ErrorIs(t, context.Cause(l.Context()), ErrLeaseLost)
+}
+
+func TestAcquire(t *testing.T) {
+	rdb, m := newTestClient(t)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.SetTime(now)
+
+	l, err := Acquire(ctx, "lock:job", time.Minute, rdb)
+	require.NoError(t, err)
+	require.NotNil(t, l)
+	require.Equal(t, now.UnixMicro(), l.Token())
+	require.Equal(t, time.Minute, m.TTL("lock:job"))
+
+	got, ok := FromContext(l.Context())
+	require.True(t, ok)
+	require.Equal(t, "lock:job", got.Key())
+
+	busy, err := Acquire(ctx, "lock:job", time.Minute, rdb)
+	require.NoError(t, err)
+	require.Nil(t, busy)
+
+	ok, err = l.Release(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.ErrorIs(t, context.Cause(l.Context()), ErrLeaseReleased)
+	ok, err = l.Release(ctx)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// every acquisition gets a greater token, also after the lock expired
+	next, err := Acquire(ctx, "lock:job", time.Minute, rdb)
+	require.NoError(t, err)
+	require.Equal(t, now.UnixMicro()+1, next.Token())
+	m.FastForward(time.Minute)
+	next, err = Acquire(ctx, "lock:job", time.Minute, rdb)
+	require.NoError(t, err)
+	defer next.Release(ctx)
+	require.Equal(t, now.UnixMicro()+2, next.Token())
+}
+
+func TestAcquireFenceLost(t *testing.T) {
+	rdb, m := newTestClient(t)
+	ctx := context.Background()
+	m.SetTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var stale int64
+	for i := 0; i < 3; i++ {
+		l, err := Acquire(ctx, "lock:job", time.Minute, rdb)
+		require.NoError(t, err)
+		stale = l.Token()
+		_, err = l.Release(ctx)
+		require.NoError(t, err)
+	}
+
+	// redis lost its data, the fence restarts above the tokens handed out before
+	m.FlushAll()
+	m.SetTime(time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC))
+	l, err := Acquire(ctx, "lock:job", time.Minute, rdb)
+	require.NoError(t, err)
+	defer l.Release(ctx)
+	require.Greater(t, l.Token(), stale)
+}
+
+func TestLeaseRenew(t *testing.T) {
+	rdb, m := newTestClient(t)
+	ctx := context.Background()
+
+	l, err := Acquire(ctx, "lock:job", time.Millisecond*300, rdb)
+	require.NoError(t, err)
+	defer l.Release(ctx)
+
+	m.FastForward(time.Millisecond * 250)
+	require.Eventually(t, func() bool {
+		return m.TTL("lock:job") > time.Millisecond*250
+	}, time.Second, time.Millisecond*10)
+	require.NoError(t, l.Err())
+}
+
+func TestLeaseLost(t *testing.T) {
+	tests := []struct {
+		name  string
+		steal func(m *miniredis.Miniredis)
+	}{
+		{name: "taken", steal: func(m *miniredis.Miniredis) {
+			require.NoError(t, m.Set("lock:job", "other"))
+		}},
+		{name: "expired", steal: func(m *miniredis.Miniredis) {
+			m.FastForward(time.Second)
+		}},
+		{name: "redis down", steal: func(m *miniredis.Miniredis) {
+			m.SetError("LOADING redis is loading")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rdb, m := newTestClient(t)
+			l, err := Acquire(context.Background(), "lock:job", time.Millisecond*300, rdb)
+			require.NoError(t, err)
+
+			tt.steal(m)
+			select {
+			case <-l.Lost():
+			case <-time.After(time.Second):
+				t.Fatal("lease not lost")
+			}
+			require.ErrorIs(t, l.Err(), ErrLeaseLost)
+			require.ErrorIs(t, context.Cause(l.Context()), ErrLeaseLost)
+		})
+	}
+}
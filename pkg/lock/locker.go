@@ -0,0 +1,238 @@
+package sync
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"server-template/pkg"
+	"server-template/pkg/log"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+const (
+	// acquireTimeout bounds one acquire attempt, within the deadline of the caller
+	acquireTimeout = time.Second * 3
+	// metadataSep separates the random part of the lock value from its metadata
+	metadataSep = "|"
+	// minRetryWait is the shortest wait between attempts, so a busy lock is never polled in a tight loop
+	minRetryWait = time.Millisecond * 10
+)
+
+// ErrNotObtained is returned by Locker.Acquire when the lock stays busy until the retries stop or max
+// wait elapses
+var ErrNotObtained = errors.New("lock not obtained")
+
+// RetryStrategy tells how long to wait before retrying a busy lock
+type RetryStrategy interface {
+	// NextBackoff returns the wait after the given failed attempt, counted from 1, a negative wait stops.
+	// Waits under 10ms are raised to 10ms.
+	NextBackoff(attempt int) time.Duration
+}
+
+type noRetry struct{}
+
+func (noRetry) NextBackoff(int) time.Duration { return -1 }
+
+// NoRetry tries once, it is the default
+func NoRetry() RetryStrategy {
+	return noRetry{}
+}
+
+type fixedRetry time.Duration
+
+func (f fixedRetry) NextBackoff(int) time.Duration { return time.Duration(f) }
+
+// FixedRetry waits d between attempts, at least 10ms
+func FixedRetry(d time.Duration) RetryStrategy {
+	return fixedRetry(max(d, minRetryWait))
+}
+
+type backoffRetry struct {
+	min, max time.Duration
+}
+
+// NextBackoff doubles min every attempt up to max and picks a random wait in the upper half,
+// so lockers waiting on the same key spread out
+func (b backoffRetry) NextBackoff(attempt int) time.Duration {
+	d := b.max
+	if attempt < 32 {
+		if exp := b.min << (attempt - 1); exp > 0 && exp < b.max {
+			d = exp
+		}
+	}
+	if d <= 1 {
+		return d
+	}
+	return d/2 + rand.N(d/2)
+}
+
+// BackoffRetry waits exponentially longer between attempts, from min up to max, with jitter.
+// A min below 10ms is raised to 10ms.
+func BackoffRetry(min, max time.Duration) RetryStrategy {
+	if min < minRetryWait {
+		min = minRetryWait
+	}
+	if max < min {
+		max = min
+	}
+	return backoffRetry{min: min, max: max}
+}
+
+type lockerOptions struct {
+	ttl      time.Duration
+	retry    RetryStrategy
+	maxWait  time.Duration
+	metadata string
+}
+
+// LockerOption configures a Locker
+type LockerOption func(*lockerOptions)
+
+// WithTTL expires the lock ttl after the last renewal, defaults to 30s
+func WithTTL(ttl time.Duration) LockerOption {
+	return func(o *lockerOptions) {
+		o.ttl = ttl
+	}
+}
+
+// WithRetry retries a busy lock with the strategy, defaults to NoRetry
+func WithRetry(strategy RetryStrategy) LockerOption {
+	return func(o *lockerOptions) {
+		o.retry = strategy
+	}
+}
+
+// WithMaxWait gives up retrying after d, 0 retries until the context is done
+func WithMaxWait(d time.Duration) LockerOption {
+	return func(o *lockerOptions) {
+		o.maxWait = d
+	}
+}
+
+// WithMetadata stores metadata with the lock, eg: the hostname, read it with Locker.Metadata
+func WithMetadata(metadata string) LockerOption {
+	return func(o *lockerOptions) {
+		o.metadata = metadata
+	}
+}
+
+// Locker takes leases on redis keys, see Lease
+type Locker struct {
+	client redis.UniversalClient
+	opts   lockerOptions
+}
+
+func NewLocker(client redis.UniversalClient, opts ...LockerOption) *Locker {
+	o := lockerOptions{
+		ttl:   defaultLeaseTTL,
+		retry: NoRetry(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.ttl <= 0 {
+		o.ttl = defaultLeaseTTL
+	}
+	if o.retry == nil {
+		o.retry = NoRetry()
+	}
+	return &Locker{client: client, opts: o}
+}
+
+// Acquire takes the lock of key, retrying while it is busy or redis fails. It stops at the first of:
+// the retry strategy gives up, max wait elapses, ctx is done. It returns ErrNotObtained when the lock
+// stayed busy, the redis error when the last attempt failed, or ctx.Err. The lease is renewed until
+// released, lost or ctx is done.
+func (l *Locker) Acquire(ctx context.Context, key string) (*Lease, error) {
+	waitCtx := ctx
+	if l.opts.maxWait > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, l.opts.maxWait)
+		defer cancel()
+	}
+
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		lease, err := l.obtain(waitCtx, ctx, key)
+		if lease != nil {
+			return lease, nil
+		}
+		wait := l.opts.retry.NextBackoff(attempt)
+		if wait < 0 {
+			return nil, notObtained(err)
+		}
+		wait = max(wait, minRetryWait)
+		if timer == nil {
+			timer = time.NewTimer(wait)
+		} else {
+			timer.Reset(wait)
+		}
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// max wait elapsed
+			return nil, notObtained(err)
+		case <-timer.C:
+		}
+	}
+}
+
+func notObtained(err error) error {
+	if err != nil {
+		return err
+	}
+	return ErrNotObtained
+}
+
+// obtain makes one attempt bounded by ctx, it returns a nil lease and no error when the lock is busy.
+// The lease lives until leaseCtx is done.
+func (l *Locker) obtain(ctx, leaseCtx context.Context, key string) (*Lease, error) {
+	value := pkg.GenID()
+	if l.opts.metadata != "" {
+		value += metadataSep + l.opts.metadata
+	}
+	actx, cancel := context.WithTimeout(ctx, acquireTimeout)
+	res, err := l.client.Eval(actx, acquire, []string{key, fenceKey(key)}, value, l.opts.ttl.Milliseconds()).Int64Slice()
+	cancel()
+	if err == nil && len(res) != 2 {
+		err = errors.Errorf("unexpected result: %v", res)
+	}
+	if err != nil {
+		acquireTotal.Inc("error")
+		return nil, errors.Wrapf(err, "acquire lock %s failed", key)
+	}
+	token, started := res[0], res[1] == 1
+	if token == 0 {
+		acquireTotal.Inc("busy")
+		return nil, nil
+	}
+	acquireTotal.Inc("locked")
+	if started {
+		log.Infof("fence of lock %s started at %d, first acquisition or redis lost the fence key", key, token)
+	}
+	return newLease(leaseCtx, l.client, key, value, token, l.opts.ttl), nil
+}
+
+// Metadata returns the metadata stored by the holder of key, redis.Nil when the lock is free
+func (l *Locker) Metadata(ctx context.Context, key string) (string, error) {
+	value, err := l.client.Get(ctx, key).Result()
+	if err != nil {
+		return "", err
+	}
+	_, metadata, _ := strings.Cut(value, metadataSep)
+	return metadata, nil
+}
@@ -0,0 +1,126 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryStrategy(t *testing.T) {
+	require.Negative(t, NoRetry().NextBackoff(1))
+	require.Equal(t, time.Second, FixedRetry(time.Second).NextBackoff(5))
+	require.Equal(t, minRetryWait, FixedRetry(0).NextBackoff(1))
+	require.Equal(t, minRetryWait, FixedRetry(-time.Second).NextBackoff(1))
+	require.GreaterOrEqual(t, BackoffRetry(0, 0).NextBackoff(1), minRetryWait/2)
+
+	b := BackoffRetry(time.Millisecond*10, time.Millisecond*100)
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{attempt: 1, min: time.Millisecond * 5, max: time.Millisecond * 10},
+		{attempt: 2, min: time.Millisecond * 10, max: time.Millisecond * 20},
+		{attempt: 4, min: time.Millisecond * 40, max: time.Millisecond * 80},
+		{attempt: 5, min: time.Millisecond * 50, max: time.Millisecond * 100},
+		{attempt: 100, min: time.Millisecond * 50, max: time.Millisecond * 100},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			d := b.NextBackoff(tt.attempt)
+			require.GreaterOrEqual(t, d, tt.min, "attempt %d", tt.attempt)
+			require.LessOrEqual(t, d, tt.max, "attempt %d", tt.attempt)
+		}
+	}
+}
+
+func TestLockerAcquire(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []LockerOption
+		timeout time.Duration // of the caller context
+		release time.Duration // the holder releases the lock after it
+		wantErr error
+		minWait time.Duration
+	}{
+		{name: "no retry", wantErr: ErrNotObtained},
+		{name: "max wait", opts: []LockerOption{WithRetry(FixedRetry(time.Millisecond * 20)), WithMaxWait(time.Millisecond * 100)},
+			wantErr: ErrNotObtained, minWait: time.Millisecond * 100},
+		{name: "context done", opts: []LockerOption{WithRetry(FixedRetry(time.Millisecond * 20))}, timeout: time.Millisecond * 100,
+			wantErr: context.DeadlineExceeded, minWait: time.Millisecond * 100},
+		{name: "released while waiting", opts: []LockerOption{WithRetry(BackoffRetry(time.Millisecond*10, time.Millisecond*40))},
+			release: time.Millisecond * 100, minWait: time.Millisecond * 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rdb, _ := newTestClient(t)
+			holder, err := NewLocker(rdb).Acquire(context.Background(), "lock:job")
+			require.NoError(t, err)
+			if tt.release > 0 {
+				time.AfterFunc(tt.release, func() { _, _ = holder.Release(context.Background()) })
+			}
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			start := time.Now()
+			l, err := NewLocker(rdb, tt.opts...).Acquire(ctx, "lock:job")
+			require.GreaterOrEqual(t, time.Since(start), tt.minWait)
+			require.Less(t, time.Since(start), time.Second)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Nil(t, l)
+				return
+			}
+			require.NoError(t, err)
+			require.Greater(t, l.Token(), holder.Token())
+			_, _ = l.Release(context.Background())
+		})
+	}
+}
+
+func TestLockerAcquireNoTightLoop(t *testing.T) {
+	rdb, m := newTestClient(t)
+	holder, err := NewLocker(rdb).Acquire(context.Background(), "lock:job")
+	require.NoError(t, err)
+	defer holder.Release(context.Background())
+
+	before := m.CommandCount()
+	_, err = NewLocker(rdb, WithRetry(FixedRetry(0)), WithMaxWait(time.Millisecond*100)).Acquire(context.Background(), "lock:job")
+	require.ErrorIs(t, err, ErrNotObtained)
+	// an attempt is the EVAL and the SET in the script, once per 10ms
+	require.LessOrEqual(t, m.CommandCount()-before, 2*(100/10+1))
+}
+
+func TestLockerRedisError(t *testing.T) {
+	rdb, m := newTestClient(t)
+	m.SetError("ERR redis is down")
+	_, err := NewLocker(rdb, WithRetry(FixedRetry(time.Millisecond*20)), WithMaxWait(time.Millisecond*50)).Acquire(context.Background(), "lock:job")
+	require.ErrorContains(t, err, "redis is down")
+	require.NotErrorIs(t, err, ErrNotObtained)
+}
+
+func TestLockerMetadata(t *testing.T) {
+	rdb, _ := newTestClient(t)
+	ctx := context.Background()
+	locker := NewLocker(rdb, WithMetadata("host-1|pid-7"))
+
+	_, err := locker.Metadata(ctx, "lock:job")
+	require.ErrorIs(t, err, redis.Nil)
+
+	l, err := locker.Acquire(ctx, "lock:job")
+	require.NoError(t, err)
+	metadata, err := locker.Metadata(ctx, "lock:job")
+	require.NoError(t, err)
+	require.Equal(t, "host-1|pid-7", metadata)
+
+	// the metadata doesn't get in the way of renewing and releasing
+	ok, err := l.Release(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
@@ -4,9 +4,8 @@ import (
 	"context"
 	"time"
 
-	"server-template/pkg"
 	"server-template/pkg/log"
-	"server-template/pkg/retry"
+	"server-template/pkg/metrics"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/pkg/errors"
@@ -18,32 +17,51 @@ const (
 	errWaitInternal = time.Millisecond * 200
 )
 
-func TryLock(ctxWithCancel context.Context, key, value string, expire, retry time.Duration, client redis.UniversalClient) {
+var (
+	acquireTotal = metrics.NewCounter("lock_acquire_total", "Lock attempts, by result: locked, busy or error.", "result")
+	renewTotal   = metrics.NewCounter("lock_renew_total", "Lock renewals, by result: ok or failed.", "result")
+	lostTotal    = metrics.NewCounter("lock_lost_total", "Locks lost because a renewal failed before unlock.")
+)
+
+// TryLock retries Lock until the lock is taken or ctx is done, use a Locker with a retry strategy instead
+func TryLock(ctxWithCancel context.Context, key, value string, expire, retry time.Duration, client redis.UniversalClient) error {
 	for {
+		wait := retry
 		ok, err := Lock(ctxWithCancel, key, value, expire, client)
-		if err != nil {
+		switch {
+		case err != nil:
 			log.Warnf("try lock %s error: %v", key, err)
-			time.Sleep(errWaitInternal)
-			continue
+			wait = errWaitInternal
+		case !ok:
+			log.Debugf("%s locked, try again later", key)
+		default:
+			return nil
 		}
 
-		if !ok {
-			log.Debugf("%s locked, try again later", key)
-			time.Sleep(retry)
-			continue
+		select {
+		case <-ctxWithCancel.Done():
+			return ctxWithCancel.Err()
+		case <-time.After(wait):
 		}
-		return
 	}
 }
 
+// Deprecated: the renewal stops silently when it fails and the caller keeps running without the lock,
+// use Acquire.
 func Lock(ctxWithCancel context.Context, key, value string, expire time.Duration, client redis.UniversalClient) (ok bool, err error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	ctx, cancel := context.WithTimeout(ctxWithCancel, acquireTimeout)
 	ok, err = client.SetNX(ctx, key, value, expire).Result()
 	cancel()
 	if err != nil {
+		acquireTotal.Inc("error")
 		err = errors.Wrapf(err, "set key: %s value: %s failed", key, value)
 		return
 	}
+	if !ok {
+		acquireTotal.Inc("busy")
+	} else {
+		acquireTotal.Inc("locked")
+	}
 
 	// renew lock when obtain lock
 	if ok {
@@ -55,19 +73,26 @@ func Lock(ctxWithCancel context.Context, key, value string, expire time.Duration
 					log.Debugf("got exit signal, pExpire task exit, key: %s value: %s", key, value)
 					return
 				default:
+					// only extend the lock while it is still ours
 					ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
-					ok, err1 := client.PExpire(ctx, key, expire).Result()
+					res, err1 := client.Eval(ctx, compareAndExpire, []string{key}, value, expire.Milliseconds()).Int64()
 					cancel()
+					ok := res == 1
 					if err1 != nil {
+						renewTotal.Inc("failed")
+						lostTotal.Inc()
 						log.Errorf("pExpire key: %s failed: %s", key, err1.Error())
 						time.Sleep(time.Millisecond * 20)
 						return
 					}
 					if !ok {
+						renewTotal.Inc("failed")
+						lostTotal.Inc()
 						log.Warnf("pExpire key: %s %s failed", key, expire)
 						time.Sleep(time.Millisecond * 20)
 						return
 					}
+					renewTotal.Inc("ok")
 					log.Debugf("pExpire key: %s %s success", key, expire)
 					time.Sleep(expire - time.Second)
 				}
@@ -94,43 +119,36 @@ func Ping(client *redis.ClusterClient) (err error) {
 	return
 }
 
-func TryLockWithKey(ctx context.Context, key string, client redis.UniversalClient) func() (bool, error) {
-	ctx1, cancel := context.WithCancel(ctx)
-	value := pkg.GenID()
-	expire := time.Second * 30
-	retry := time.Millisecond * 100
-	TryLock(ctx1, key, value, expire, retry, client)
+// TryLockWithKey waits for key until it is taken or ctx is done, the lock expires 30s after the last renewal
+func TryLockWithKey(ctx context.Context, key string, client redis.UniversalClient) (func() (bool, error), error) {
+	lease, err := NewLocker(client, WithRetry(FixedRetry(time.Millisecond*100))).Acquire(ctx, key)
+	if err != nil {
+		return nil, err
+	}
 
 	return func() (bool, error) {
-		ctx, cancel1 := context.WithTimeout(context.Background(), time.Second)
-		defer cancel1()
-		return Unlock(ctx, key, value, cancel, client)
-	}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		return lease.Release(ctx)
+	}, nil
 }
 
+// LockWithKey takes key on a lease, see LeaseWithKey. The caller is not told when the lock is lost,
+// use LeaseWithKey to stop the critical section then.
 func LockWithKey(ctx context.Context, key string, client redis.UniversalClient) (isLocked bool, cancelFunc func() bool, err error) {
-	ctx1, cancel := context.WithCancel(ctx)
-	value := pkg.GenID()
-	expire := time.Second * 30
-
-	err = retry.BackoffRetry(func() error {
-		isLocked, err = Lock(ctx1, key, value, expire, client)
-		if err != nil {
-			log.Warnf("lock %s failed, err: %+v, retry", key, err)
-			return err
-		}
-		return nil
-	})
+	lease, err := LeaseWithKey(ctx, key, client)
+	if lease == nil {
+		return false, func() bool { return false }, err
+	}
 
 	cancelFunc = func() bool {
-		ctx, cancel1 := context.WithTimeout(context.Background(), time.Second)
-		defer cancel1()
-		ok, err := Unlock(ctx, key, value, cancel, client)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		ok, err := lease.Release(ctx)
 		if err != nil {
-			log.Errorf("unlock failed, key: %s, value: %s", key, value)
+			log.Errorf("unlock failed, key: %s, err: %+v", key, err)
 		}
 		return ok
 	}
-
-	return
+	return true, cancelFunc, nil
 }
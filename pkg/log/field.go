@@ -56,5 +56,9 @@ var (
 	Durationp   = zap.Durationp
 	Object      = zap.Object
 	Inline      = zap.Inline
-	Any         = zap.Any
 )
+
+// Any is zap.Any with the sensitive fields of proto messages redacted
+func Any(key string, value any) Field {
+	return zap.Any(key, redactValue(value))
+}
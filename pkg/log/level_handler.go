@@ -0,0 +1,175 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxLevelDuration bounds a temporary level change, on-call can renew it
+const maxLevelDuration = time.Hour * 24
+
+type levelState struct {
+	Level    string            `json:"level"`
+	Modules  map[string]string `json:"modules"`
+	RevertAt *time.Time        `json:"revertAt,omitempty"`
+}
+
+// levelRequest changes the global level and replaces the module levels when they are set,
+// with a duration the previous levels come back once it elapses, eg: {"level":"debug","duration":"15m"}
+type levelRequest struct {
+	Level    string            `json:"level"`
+	Modules  map[string]string `json:"modules"`
+	Duration string            `json:"duration"`
+}
+
+type levelSnapshot struct {
+	level   Level
+	modules map[string]Level
+}
+
+// LevelHandler reads (GET) and changes (PUT) the levels of a logger at runtime
+type LevelHandler struct {
+	l *Logger
+
+	mu       sync.Mutex
+	timer    *time.Timer
+	gen      int // tells a stale timer that fired while a change held mu
+	revertAt time.Time
+	saved    *levelSnapshot // levels before a temporary change
+}
+
+func NewLevelHandler(l *Logger) *LevelHandler {
+	return &LevelHandler{l: l}
+}
+
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req levelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeLevelError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+			return
+		}
+		if err := h.change(req); err != nil {
+			writeLevelError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	default:
+		writeLevelError(w, http.StatusMethodNotAllowed, "only GET and PUT are supported")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(h.state())
+}
+
+func (h *LevelHandler) state() levelState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := levelState{
+		Level:   h.l.Level().String(),
+		Modules: make(map[string]string),
+	}
+	for module, lvl := range h.l.ModuleLevels() {
+		s.Modules[module] = lvl.String()
+	}
+	if h.saved != nil {
+		revertAt := h.revertAt
+		s.RevertAt = &revertAt
+	}
+	return s
+}
+
+func (h *LevelHandler) change(req levelRequest) error {
+	level := h.l.Level()
+	if req.Level != "" {
+		lvl, err := ParseLevel(req.Level)
+		if err != nil {
+			return err
+		}
+		level = lvl
+	}
+	modules := h.l.ModuleLevels()
+	if req.Modules != nil {
+		m, err := ParseModuleLevels(req.Modules)
+		if err != nil {
+			return err
+		}
+		modules = m
+	}
+	var d time.Duration
+	if req.Duration != "" {
+		var err error
+		if d, err = time.ParseDuration(req.Duration); err != nil {
+			return err
+		}
+		if d <= 0 || d > maxLevelDuration {
+			return fmt.Errorf("duration must be greater than 0 and at most %s", maxLevelDuration)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+	h.gen++
+	if d == 0 {
+		h.saved = nil
+	} else {
+		// a renewed temporary change still reverts to the levels before the first one
+		if h.saved == nil {
+			h.saved = &levelSnapshot{level: h.l.Level(), modules: h.l.ModuleLevels()}
+		}
+		h.revertAt = time.Now().Add(d)
+		gen := h.gen
+		h.timer = time.AfterFunc(d, func() { h.revert(gen) })
+	}
+	h.l.SetLevel(level)
+	h.l.SetModuleLevels(modules)
+	h.l.Warnf("log level changed to %s, modules: %v, duration: %s", level, req.Modules, d)
+	return nil
+}
+
+// Reset drops a pending temporary change without reverting it, call it when the levels are set from
+// elsewhere such as a config reload, or the revert would restore stale levels. It reports whether a
+// temporary change was pending.
+func (h *LevelHandler) Reset() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+	h.gen++
+	pending := h.saved != nil
+	h.saved = nil
+	return pending
+}
+
+func (h *LevelHandler) revert(gen int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.saved == nil || gen != h.gen {
+		return
+	}
+	h.l.SetLevel(h.saved.level)
+	h.l.SetModuleLevels(h.saved.modules)
+	h.l.Warnf("log level reverted to %s", h.saved.level)
+	h.saved = nil
+	h.timer = nil
+}
+
+func writeLevelError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,88 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"server-template/internal/conf"
+	"server-template/pkg/env"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelHandler(t *testing.T) {
+	l := New(io.Discard, &conf.Log{Level: "info", Modules: map[string]string{"db": "warn"}}, env.ModeProd)
+	h := NewLevelHandler(l)
+
+	do := func(method, body string) (int, levelState) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, "/log/level", strings.NewReader(body)))
+		var s levelState
+		_ = json.NewDecoder(bytes.NewReader(rec.Body.Bytes())).Decode(&s)
+		return rec.Code, s
+	}
+
+	code, s := do(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, levelState{Level: "info", Modules: map[string]string{"db": "warn"}}, s)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"invalid level", `{"level":"verbose"}`, http.StatusBadRequest},
+		{"invalid module level", `{"modules":{"db":"loud"}}`, http.StatusBadRequest},
+		{"invalid duration", `{"level":"debug","duration":"25h"}`, http.StatusBadRequest},
+		{"invalid body", `level=debug`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _ := do(http.MethodPut, tt.body)
+			require.Equal(t, tt.code, code)
+		})
+	}
+
+	code, s = do(http.MethodPut, `{"level":"debug","modules":{},"duration":"50ms"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "debug", s.Level)
+	require.Empty(t, s.Modules)
+	require.NotNil(t, s.RevertAt)
+
+	// renewing keeps the levels from before the first temporary change
+	_, _ = do(http.MethodPut, `{"level":"debug","modules":{"db":"debug"},"duration":"50ms"}`)
+	require.Eventually(t, func() bool {
+		_, s = do(http.MethodGet, "")
+		return s.RevertAt == nil
+	}, time.Second, time.Millisecond*10)
+	require.Equal(t, levelState{Level: "info", Modules: map[string]string{"db": "warn"}}, s)
+
+	// a permanent change cancels the pending revert
+	_, _ = do(http.MethodPut, `{"level":"debug","duration":"50ms"}`)
+	_, s = do(http.MethodPut, `{"level":"error"}`)
+	require.Nil(t, s.RevertAt)
+	time.Sleep(time.Millisecond * 100)
+	require.Equal(t, ErrorLevel, l.Level())
+}
+
+func TestLevelHandlerReset(t *testing.T) {
+	l := New(io.Discard, &conf.Log{Level: "info"}, env.ModeProd)
+	h := NewLevelHandler(l)
+	require.False(t, h.Reset())
+
+	require.NoError(t, h.change(levelRequest{Level: "debug", Duration: "50ms"}))
+	require.True(t, h.Reset())
+	require.Nil(t, h.state().RevertAt)
+
+	// the levels set after the reset are not reverted
+	l.SetLevel(WarnLevel)
+	time.Sleep(time.Millisecond * 100)
+	require.Equal(t, WarnLevel, l.Level())
+	require.False(t, h.Reset())
+}
@@ -3,13 +3,16 @@ package log
 import (
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"sync"
+	"time"
 
 	"server-template/internal/conf"
 	"server-template/pkg/env"
 
 	"github.com/go-kratos/kratos/v2/log"
+	zaplogfmt "github.com/jsternberg/zap-logfmt"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -61,13 +64,18 @@ const (
 	DebugLevel Level = zap.DebugLevel // -1
 )
 
+// ParseLevel parses debug, info, warn, error, dpanic, panic or fatal
+func ParseLevel(text string) (Level, error) {
+	return zapcore.ParseLevel(text)
+}
+
 type Field = zap.Field
 
 var _ log.Logger = (*Logger)(nil)
 
 type Logger struct {
 	l      *zap.Logger // zap ensure that zap.Logger is safe for concurrent use
-	level  zap.AtomicLevel
+	levels *levels
 	msgKey string
 }
 
@@ -87,7 +95,7 @@ func (l *Logger) Log(level log.Level, keyvals ...interface{}) error {
 			msg, _ = keyvals[i+1].(string)
 			continue
 		}
-		data = append(data, zap.Any(fmt.Sprint(keyvals[i]), keyvals[i+1]))
+		data = append(data, zap.Any(fmt.Sprint(keyvals[i]), redactValue(keyvals[i+1])))
 	}
 
 	switch level {
@@ -110,7 +118,7 @@ func (l *Logger) Debug(msg string, fields ...Field) {
 }
 
 func (l *Logger) Debugf(msg string, args ...any) {
-	l.l.Sugar().Debugf(msg, args)
+	l.l.Sugar().Debugf(msg, redactArgs(args)...)
 }
 
 func (l *Logger) Info(msg string, fields ...Field) {
@@ -118,7 +126,7 @@ func (l *Logger) Info(msg string, fields ...Field) {
 }
 
 func (l *Logger) Infof(msg string, args ...any) {
-	l.l.Sugar().Infof(msg, args...)
+	l.l.Sugar().Infof(msg, redactArgs(args)...)
 }
 
 func (l *Logger) Warn(msg string, fields ...Field) {
@@ -126,7 +134,7 @@ func (l *Logger) Warn(msg string, fields ...Field) {
 }
 
 func (l *Logger) Warnf(msg string, args ...any) {
-	l.l.Sugar().Warnf(msg, args...)
+	l.l.Sugar().Warnf(msg, redactArgs(args)...)
 }
 
 func (l *Logger) Error(msg string, fields ...Field) {
@@ -134,7 +142,7 @@ func (l *Logger) Error(msg string, fields ...Field) {
 }
 
 func (l *Logger) Errorf(msg string, args ...any) {
-	l.l.Sugar().Errorf(msg, args...)
+	l.l.Sugar().Errorf(msg, redactArgs(args)...)
 }
 
 func (l *Logger) DPanic(msg string, fields ...Field) {
@@ -142,7 +150,7 @@ func (l *Logger) DPanic(msg string, fields ...Field) {
 }
 
 func (l *Logger) DPanicf(msg string, args ...any) {
-	l.l.Sugar().DPanicf(msg, args...)
+	l.l.Sugar().DPanicf(msg, redactArgs(args)...)
 }
 
 func (l *Logger) Panic(msg string, fields ...Field) {
@@ -150,7 +158,7 @@ func (l *Logger) Panic(msg string, fields ...Field) {
 }
 
 func (l *Logger) Panicf(msg string, args ...any) {
-	l.l.Sugar().Panicf(msg, args...)
+	l.l.Sugar().Panicf(msg, redactArgs(args)...)
 }
 
 func (l *Logger) Fatal(msg string, fields ...Field) {
@@ -158,12 +166,12 @@ func (l *Logger) Fatal(msg string, fields ...Field) {
 }
 
 func (l *Logger) Fatalf(msg string, args ...any) {
-	l.l.Sugar().Fatalf(msg, args...)
+	l.l.Sugar().Fatalf(msg, redactArgs(args)...)
 }
 
 func (l *Logger) With(fields ...Field) *Logger {
 	logger := l.l.With(fields...)
-	return &Logger{l: logger, level: l.level, msgKey: l.msgKey}
+	return &Logger{l: logger, levels: l.levels, msgKey: l.msgKey}
 }
 
 var (
@@ -233,27 +241,44 @@ func New(w io.Writer, logConfig *conf.Log, envArg string, opts ...Option) *Logge
 	encConfig.EncodeTime = zapcore.RFC3339TimeEncoder
 	encConfig.MessageKey = "message"
 
-	atomicLevel := zap.NewAtomicLevelAt(zapcore.InfoLevel)
-
-	var enc zapcore.Encoder
+	level := zapcore.InfoLevel
+	encoding := "json"
 	switch envArg {
 	case env.ModeDev, env.ModeTest:
+		level = zapcore.DebugLevel
+		encoding = "console"
+	}
+	if l, err := ParseLevel(logConfig.Level); logConfig.Level != "" && err == nil {
+		level = l
+	}
+	if logConfig.Encoding != "" {
+		encoding = logConfig.Encoding
+	}
+	// invalid module levels are rejected by the config validation
+	modules, _ := ParseModuleLevels(logConfig.Modules)
+	levels := newLevels(level, modules)
+
+	var enc zapcore.Encoder
+	switch encoding {
+	case "console":
 		enc = zapcore.NewConsoleEncoder(encConfig)
-		atomicLevel = zap.NewAtomicLevelAt(zapcore.DebugLevel)
-	case env.ModeEmpty, env.ModeProd:
+	case "logfmt":
+		enc = zaplogfmt.NewEncoder(encConfig)
+	default:
 		enc = zapcore.NewJSONEncoder(encConfig)
 	}
 
+	// the level is checked by moduleCore, the writers log whatever reaches them
 	var cores []zapcore.Core
 	if logConfig.IsWriteFile {
 		fileWriterSync := getFileLogWriter(
-			logConfig.LogFile.Name, false,
-			int(logConfig.LogFile.MaxSize), int(logConfig.LogFile.MaxAge),
+			logConfig.LogFile.Name, logConfig.LogFile.Compress,
+			int(logConfig.LogFile.MaxSize), int(logConfig.LogFile.MaxAge), int(logConfig.LogFile.MaxBackups),
 		)
 		core := zapcore.NewCore(
 			enc,
 			zapcore.AddSync(fileWriterSync),
-			atomicLevel,
+			zapcore.DebugLevel,
 		)
 		cores = append(cores, core)
 	}
@@ -261,15 +286,23 @@ func New(w io.Writer, logConfig *conf.Log, envArg string, opts ...Option) *Logge
 	core := zapcore.NewCore(
 		enc,
 		zapcore.AddSync(w),
-		atomicLevel,
+		zapcore.DebugLevel,
 	)
 
 	cores = append(cores, core)
 
-	core = zapcore.NewTee(cores...)
+	core = &moduleCore{Core: zapcore.NewTee(cores...), levels: levels}
+	// a zero initial would drop every entry after the first one, treat it as no sampling
+	if s := logConfig.Sampling; s.GetInitial() > 0 {
+		tick := time.Second
+		if s.Tick != nil {
+			tick = s.Tick.AsDuration()
+		}
+		core = zapcore.NewSamplerWithOptions(core, tick, int(s.Initial), int(s.Thereafter))
+	}
 	return &Logger{
 		l:      zap.New(core, opts...),
-		level:  atomicLevel,
+		levels: levels,
 		msgKey: log.DefaultMessageKey,
 	}
 }
@@ -277,11 +310,31 @@ func New(w io.Writer, logConfig *conf.Log, envArg string, opts ...Option) *Logge
 // SetLevel alters the logging level on runtime
 // it is concurrent-safe
 func (l *Logger) SetLevel(level Level) {
-	l.level.SetLevel(zapcore.Level(level))
+	l.levels.global.SetLevel(zapcore.Level(level))
 }
 
 func SetLevel(level Level) {
-	std.level.SetLevel(zapcore.Level(level))
+	std.levels.global.SetLevel(zapcore.Level(level))
+}
+
+// Level returns the global logging level
+func (l *Logger) Level() Level {
+	return l.levels.global.Level()
+}
+
+// ModuleLevels returns a copy of the per module levels
+func (l *Logger) ModuleLevels() map[string]Level {
+	return maps.Clone(*l.levels.modules.Load())
+}
+
+// SetModuleLevels replaces the per module levels, modules left out fall back to the global level
+func (l *Logger) SetModuleLevels(modules map[string]Level) {
+	modules = maps.Clone(modules)
+	l.levels.modules.Store(&modules)
+}
+
+func SetModuleLevels(modules map[string]Level) {
+	std.SetModuleLevels(modules)
 }
 
 func (l *Logger) Sync() error {
@@ -298,14 +351,15 @@ func Sync() error {
 
 func getFileLogWriter(
 	filePath string, isCompress bool,
-	fileMaxSize, backUpFileMaxAge int,
+	fileMaxSize, backUpFileMaxAge, maxBackups int,
 ) (writeSyncer zapcore.WriteSyncer) {
 	lumberJackLogger := &lumberjack.Logger{
-		Filename:  filePath,
-		MaxSize:   fileMaxSize,
-		MaxAge:    backUpFileMaxAge,
-		Compress:  false,
-		LocalTime: true,
+		Filename:   filePath,
+		MaxSize:    fileMaxSize,
+		MaxAge:     backUpFileMaxAge,
+		MaxBackups: maxBackups,
+		Compress:   isCompress,
+		LocalTime:  true,
 	}
 
 	return zapcore.AddSync(lumberJackLogger)
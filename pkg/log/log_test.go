@@ -0,0 +1,35 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"server-template/internal/conf"
+	"server-template/pkg/env"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampling(t *testing.T) {
+	tests := []struct {
+		name     string
+		sampling *conf.Log_Sampling
+		want     int
+	}{
+		{name: "unset", want: 3},
+		{name: "empty", sampling: &conf.Log_Sampling{}, want: 3},
+		{name: "first only", sampling: &conf.Log_Sampling{Initial: 1}, want: 1},
+		{name: "every second", sampling: &conf.Log_Sampling{Initial: 1, Thereafter: 2}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := New(&buf, &conf.Log{Level: "info", Encoding: "logfmt", Sampling: tt.sampling}, env.ModeProd)
+			for i := 0; i < 3; i++ {
+				l.Info("m")
+			}
+			require.Equal(t, tt.want, strings.Count(buf.String(), "\n"), buf.String())
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package log
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ModuleKey is the field biz, data and service tag their loggers with, eg: log.With(logger, "module", "data")
+const ModuleKey = "module"
+
+// levels holds the global level and the per module overrides, both can be changed at runtime
+type levels struct {
+	global  zap.AtomicLevel
+	modules atomic.Pointer[map[string]Level]
+}
+
+func newLevels(global Level, modules map[string]Level) *levels {
+	l := &levels{global: zap.NewAtomicLevelAt(global)}
+	l.modules.Store(&modules)
+	return l
+}
+
+// of returns the level of module, modules without an override use the global level
+func (l *levels) of(module string) Level {
+	if module != "" {
+		if lvl, ok := (*l.modules.Load())[module]; ok {
+			return lvl
+		}
+	}
+	return l.global.Level()
+}
+
+// Enabled reports whether any module logs lvl, the exact check is done once the module field is known
+func (l *levels) Enabled(lvl Level) bool {
+	if l.global.Enabled(lvl) {
+		return true
+	}
+	for _, m := range *l.modules.Load() {
+		if m.Enabled(lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseModuleLevels parses the per module levels of conf.Log
+func ParseModuleLevels(modules map[string]string) (map[string]Level, error) {
+	m := make(map[string]Level, len(modules))
+	for module, text := range modules {
+		lvl, err := ParseLevel(text)
+		if err != nil {
+			return nil, err
+		}
+		m[module] = lvl
+	}
+	return m, nil
+}
+
+// moduleCore filters entries by the level of their module field, kratos loggers pass it with every entry
+// while zap loggers carry it from With
+type moduleCore struct {
+	zapcore.Core
+	levels *levels
+	module string
+}
+
+func (c *moduleCore) Enabled(lvl Level) bool {
+	if c.module != "" {
+		return c.levels.of(c.module).Enabled(lvl)
+	}
+	return c.levels.Enabled(lvl)
+}
+
+func (c *moduleCore) With(fields []zapcore.Field) zapcore.Core {
+	return &moduleCore{
+		Core:   c.Core.With(fields),
+		levels: c.levels,
+		module: moduleOf(fields, c.module),
+	}
+}
+
+func (c *moduleCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *moduleCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.levels.of(moduleOf(fields, c.module)).Enabled(ent.Level) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+func moduleOf(fields []zapcore.Field, module string) string {
+	for _, f := range fields {
+		if f.Key == ModuleKey && f.Type == zapcore.StringType {
+			module = f.String
+		}
+	}
+	return module
+}
@@ -0,0 +1,49 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"server-template/internal/conf"
+	"server-template/pkg/env"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleLevels(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, &conf.Log{
+		Level:    "info",
+		Encoding: "logfmt",
+		Modules:  map[string]string{"data": "debug", "biz": "error"},
+	}, env.ModeProd)
+
+	tests := []struct {
+		name   string
+		log    func()
+		logged bool
+	}{
+		{"global info", func() { l.Info("m") }, true},
+		{"global debug", func() { l.Debug("m") }, false},
+		{"kratos module debug", func() { log.NewHelper(log.With(l, ModuleKey, "data")).Debug("m") }, true},
+		{"kratos module warn", func() { log.NewHelper(log.With(l, ModuleKey, "biz")).Warn("m") }, false},
+		{"kratos module error", func() { log.NewHelper(log.With(l, ModuleKey, "biz")).Error("m") }, true},
+		{"kratos other module", func() { log.NewHelper(log.With(l, ModuleKey, "service")).Debug("m") }, false},
+		{"zap module debug", func() { l.With(String(ModuleKey, "data")).Debug("m") }, true},
+		{"zap module warn", func() { l.With(String(ModuleKey, "biz")).Warn("m") }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tt.log()
+			require.Equal(t, tt.logged, buf.Len() > 0, buf.String())
+		})
+	}
+
+	buf.Reset()
+	l.SetModuleLevels(map[string]Level{"biz": WarnLevel})
+	log.NewHelper(log.With(l, ModuleKey, "biz")).Warn("m")
+	require.Contains(t, buf.String(), "module=biz")
+	require.Equal(t, map[string]Level{"biz": WarnLevel}, l.ModuleLevels())
+}
@@ -0,0 +1,133 @@
+package log
+
+import (
+	"sync"
+
+	logpb "server-template/api/log"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const redacted = "***"
+
+// sensitiveMessages caches whether a message type holds a (api.log.sensitive) field, directly or nested,
+// so messages without any are logged without a copy
+var sensitiveMessages sync.Map // protoreflect.FullName -> bool
+
+// IsSensitive reports whether the field is annotated with (api.log.sensitive) = true
+func IsSensitive(fd protoreflect.FieldDescriptor) bool {
+	sensitive, _ := proto.GetExtension(fd.Options(), logpb.E_Sensitive).(bool)
+	return sensitive
+}
+
+// Redact returns a copy of m with every sensitive field masked, strings become *** and other kinds are cleared.
+// m itself is returned when its type has no sensitive field.
+func Redact(m proto.Message) proto.Message {
+	if m == nil || !hasSensitive(m.ProtoReflect().Descriptor(), nil) {
+		return m
+	}
+	r := proto.Clone(m)
+	redact(r.ProtoReflect())
+	return r
+}
+
+// redactValue redacts the proto messages passed to the logger, other values are returned as is
+func redactValue(v any) any {
+	if m, ok := v.(proto.Message); ok {
+		return Redact(m)
+	}
+	return v
+}
+
+// redactArgs redacts the proto messages of printf style args, the caller's slice is not modified
+func redactArgs(args []any) []any {
+	copied := false
+	for i, arg := range args {
+		if m, ok := arg.(proto.Message); ok {
+			if !copied {
+				args = append([]any(nil), args...)
+				copied = true
+			}
+			args[i] = Redact(m)
+		}
+	}
+	return args
+}
+
+func hasSensitive(md protoreflect.MessageDescriptor, visiting map[protoreflect.FullName]bool) bool {
+	if v, ok := sensitiveMessages.Load(md.FullName()); ok {
+		return v.(bool)
+	}
+	// a recursive type is answered by the outermost call, which is also the only one cached:
+	// inner results may have stopped at a type still being visited
+	if visiting[md.FullName()] {
+		return false
+	}
+	outermost := visiting == nil
+	if outermost {
+		visiting = make(map[protoreflect.FullName]bool)
+	}
+	visiting[md.FullName()] = true
+
+	found := false
+	fields := md.Fields()
+	for i := 0; i < fields.Len() && !found; i++ {
+		fd := fields.Get(i)
+		switch {
+		case IsSensitive(fd):
+			found = true
+		case fd.IsMap():
+			if mv := fd.MapValue(); mv.Kind() == protoreflect.MessageKind {
+				found = hasSensitive(mv.Message(), visiting)
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			found = hasSensitive(fd.Message(), visiting)
+		}
+	}
+	if outermost {
+		sensitiveMessages.Store(md.FullName(), found)
+	}
+	return found
+}
+
+func redact(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case IsSensitive(fd):
+			redactField(m, fd, v)
+		case fd.IsMap() && fd.MapValue().Kind() == protoreflect.MessageKind:
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				redact(mv.Message())
+				return true
+			})
+		case fd.IsList() && fd.Kind() == protoreflect.MessageKind:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				redact(list.Get(i).Message())
+			}
+		case !fd.IsMap() && fd.Kind() == protoreflect.MessageKind:
+			redact(v.Message())
+		}
+		return true
+	})
+}
+
+func redactField(m protoreflect.Message, fd protoreflect.FieldDescriptor, v protoreflect.Value) {
+	switch {
+	case fd.IsList() && fd.Kind() == protoreflect.StringKind:
+		list := v.List()
+		for i := 0; i < list.Len(); i++ {
+			list.Set(i, protoreflect.ValueOfString(redacted))
+		}
+	case fd.IsMap() && fd.MapValue().Kind() == protoreflect.StringKind:
+		v.Map().Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+			v.Map().Set(k, protoreflect.ValueOfString(redacted))
+			return true
+		})
+	case !fd.IsList() && !fd.IsMap() && fd.Kind() == protoreflect.StringKind:
+		m.Set(fd, protoreflect.ValueOfString(redacted))
+	default:
+		m.Clear(fd)
+	}
+}
@@ -0,0 +1,62 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+
+	"server-template/api/server"
+	"server-template/internal/conf"
+	"server-template/pkg/env"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		in   proto.Message
+		want proto.Message
+	}{
+		{
+			name: "field",
+			in:   &server.CreateUserReq{Name: "a", LastName: "bc", Email: "a@b.c"},
+			want: &server.CreateUserReq{Name: "a", LastName: "bc", Email: redacted},
+		},
+		{
+			name: "unset field",
+			in:   &server.CreateUserReq{Name: "a"},
+			want: &server.CreateUserReq{Name: "a"},
+		},
+		{
+			name: "nested list",
+			in:   &server.ListUsersReply{Users: []*server.User{{Id: "1", Email: "a@b.c"}, {Id: "2", Email: "d@e.f"}}},
+			want: &server.ListUsersReply{Users: []*server.User{{Id: "1", Email: redacted}, {Id: "2", Email: redacted}}},
+		},
+		{
+			name: "no sensitive field",
+			in:   &server.GetUserReq{Id: "1"},
+			want: &server.GetUserReq{Id: "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := proto.Clone(tt.in)
+			require.True(t, proto.Equal(tt.want, Redact(in)))
+			require.True(t, proto.Equal(tt.in, in), "input must not be modified")
+		})
+	}
+}
+
+func TestLoggerRedacts(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, &conf.Log{Level: "info"}, env.ModeProd)
+	req := &server.CreateUserReq{Name: "a", Email: "a@b.c"}
+
+	l.Infof("create user %v", req)
+	log.NewHelper(l).Infow("req", req)
+	l.Info("create user", Any("req", req))
+	require.NotContains(t, buf.String(), "a@b.c")
+	require.Equal(t, "a@b.c", req.Email)
+}
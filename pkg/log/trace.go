@@ -0,0 +1,22 @@
+package log
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TraceIDKey = "traceId"
+	SpanIDKey  = "spanId"
+)
+
+// WithContext returns the default logger with the trace id and span id of ctx, for the package level helpers
+// that don't see the request context, eg: log.WithContext(ctx).Warnf(...)
+func WithContext(ctx context.Context) *Logger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return Default()
+	}
+	return Default().With(String(TraceIDKey, sc.TraceID().String()), String(SpanIDKey, sc.SpanID().String()))
+}
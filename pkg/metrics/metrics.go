@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes every metric registered through this package
+const Namespace = "server"
+
+// registry holds everything exported on the metrics endpoint, the go runtime and process metrics included
+var registry = prometheus.NewRegistry()
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsAll)),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+type Counter interface {
+	Inc(labelValues ...string)
+	Add(v float64, labelValues ...string)
+}
+
+type Gauge interface {
+	Set(v float64, labelValues ...string)
+	Add(v float64, labelValues ...string)
+}
+
+type Histogram interface {
+	Observe(v float64, labelValues ...string)
+}
+
+// NewCounter registers a counter, it panics when the name is already taken like the other constructors,
+// so call them from package level vars or init
+func NewCounter(name, help string, labels ...string) Counter {
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Namespace: Namespace, Name: name, Help: help}, labels)
+	registry.MustRegister(vec)
+	return counter{vec}
+}
+
+func NewGauge(name, help string, labels ...string) Gauge {
+	vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: Namespace, Name: name, Help: help}, labels)
+	registry.MustRegister(vec)
+	return gauge{vec}
+}
+
+// NewHistogram registers a histogram, nil buckets uses prometheus.DefBuckets
+func NewHistogram(name, help string, buckets []float64, labels ...string) Histogram {
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Namespace: Namespace, Name: name, Help: help, Buckets: buckets}, labels)
+	registry.MustRegister(vec)
+	return histogram{vec}
+}
+
+// Register adds a custom collector, eg: pool stats read on scrape
+func Register(c prometheus.Collector) error {
+	return registry.Register(c)
+}
+
+// RegisterDB exports the pool stats of db, name tells the pools apart, eg: master, slave
+func RegisterDB(db *sql.DB, name string) error {
+	return Register(collectors.NewDBStatsCollector(db, name))
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+type counter struct{ vec *prometheus.CounterVec }
+
+func (c counter) Inc(labelValues ...string) { c.vec.WithLabelValues(labelValues...).Inc() }
+
+func (c counter) Add(v float64, labelValues ...string) { c.vec.WithLabelValues(labelValues...).Add(v) }
+
+type gauge struct{ vec *prometheus.GaugeVec }
+
+func (g gauge) Set(v float64, labelValues ...string) { g.vec.WithLabelValues(labelValues...).Set(v) }
+
+func (g gauge) Add(v float64, labelValues ...string) { g.vec.WithLabelValues(labelValues...).Add(v) }
+
+type histogram struct{ vec *prometheus.HistogramVec }
+
+func (h histogram) Observe(v float64, labelValues ...string) {
+	h.vec.WithLabelValues(labelValues...).Observe(v)
+}
@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/middleware/recovery"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+var (
+	requests = NewCounter("requests_total", "Requests handled, by transport, operation, code and error reason.",
+		"kind", "operation", "code", "reason")
+	seconds = NewHistogram("request_duration_seconds", "Request latency, by transport and operation.",
+		[]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		"kind", "operation")
+)
+
+// Server is a middleware that records rate, errors and duration of every operation.
+// A panic is recorded as the error recovery.Recovery turns it into, then passed on.
+func Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (reply any, err error) {
+			var kind, operation string
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				kind = tr.Kind().String()
+				operation = tr.Operation()
+			}
+
+			start := time.Now()
+			defer func() {
+				rerr := recover()
+				if rerr != nil {
+					err = recovery.ErrUnknownRequest
+				}
+				code, reason := 200, ""
+				if se := errors.FromError(err); se != nil {
+					code, reason = int(se.Code), se.Reason
+				}
+				requests.Inc(kind, operation, strconv.Itoa(code), reason)
+				seconds.Observe(time.Since(start).Seconds(), kind, operation)
+				if rerr != nil {
+					panic(rerr)
+				}
+			}()
+			return handler(ctx, req)
+		}
+	}
+}
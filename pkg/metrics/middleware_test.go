@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "success",
+			want: `server_requests_total{code="200",kind="",operation="",reason=""} 1`,
+		},
+		{
+			name: "error reason",
+			err:  errors.NotFound("USER_NOT_FOUND", "user not found"),
+			want: `server_requests_total{code="404",kind="",operation="",reason="USER_NOT_FOUND"} 1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Server()(func(ctx context.Context, req any) (any, error) {
+				return nil, tt.err
+			})
+			_, err := h(context.Background(), nil)
+			require.Equal(t, tt.err, err)
+
+			rec := httptest.NewRecorder()
+			Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+			body, err := io.ReadAll(rec.Body)
+			require.NoError(t, err)
+			require.Contains(t, string(body), tt.want)
+		})
+	}
+}
+
+func TestServerPanic(t *testing.T) {
+	h := Server()(func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+	require.PanicsWithValue(t, "boom", func() { _, _ = h(context.Background(), nil) })
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), `server_requests_total{code="500",kind="",operation="",reason="UNKNOWN"} 1`)
+}
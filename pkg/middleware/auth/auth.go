@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	authorizationHeader = "Authorization" // authorization in grpc metadata
+	bearerPrefix        = "Bearer "
+
+	reason = "UNAUTHORIZED"
+)
+
+var (
+	ErrMissingToken = errors.Unauthorized(reason, "missing bearer token")
+	ErrInvalidToken = errors.Unauthorized(reason, "token is invalid")
+	ErrTokenExpired = errors.Unauthorized(reason, "token has expired")
+	ErrForbidden    = errors.Forbidden("FORBIDDEN", "token is not accepted by this service")
+)
+
+// Claims is the jwt payload put into the request context
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+}
+
+type claimsKey struct{}
+
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*Claims)
+	return claims, ok
+}
+
+type options struct {
+	hmacSecret []byte
+	keySet     *KeySet
+	issuer     string
+	audience   []string
+}
+
+type Option func(*options)
+
+// WithHMACSecret accepts HS256 tokens signed with secret
+func WithHMACSecret(secret []byte) Option {
+	return func(o *options) {
+		o.hmacSecret = secret
+	}
+}
+
+// WithKeySet accepts RS256 and ES256 tokens signed by a key in the set, looked up by kid
+func WithKeySet(keySet *KeySet) Option {
+	return func(o *options) {
+		o.keySet = keySet
+	}
+}
+
+func WithIssuer(issuer string) Option {
+	return func(o *options) {
+		o.issuer = issuer
+	}
+}
+
+// WithAudience accepts tokens issued for any of the audiences
+func WithAudience(audience ...string) Option {
+	return func(o *options) {
+		o.audience = audience
+	}
+}
+
+// Server is a middleware that validates the bearer jwt from http headers or grpc metadata
+// and puts its claims into the context
+func Server(opts ...Option) middleware.Middleware {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var methods []string
+	if len(o.hmacSecret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if o.keySet != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+	parser := jwt.NewParser(jwt.WithValidMethods(methods), jwt.WithExpirationRequired())
+
+	keyFunc := func(token *jwt.Token) (any, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+			return o.hmacSecret, nil
+		}
+		kid, _ := token.Header["kid"].(string)
+		return o.keySet.Key(kid)
+	}
+
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return nil, ErrMissingToken
+			}
+			auth := tr.RequestHeader().Get(authorizationHeader)
+			if len(auth) <= len(bearerPrefix) || !strings.EqualFold(auth[:len(bearerPrefix)], bearerPrefix) {
+				return nil, ErrMissingToken
+			}
+
+			claims := &Claims{}
+			_, err := parser.ParseWithClaims(auth[len(bearerPrefix):], claims, keyFunc)
+			if err != nil {
+				if errors.Is(err, jwt.ErrTokenExpired) {
+					return nil, ErrTokenExpired.WithCause(err)
+				}
+				return nil, ErrInvalidToken.WithCause(err)
+			}
+			if !o.accept(claims) {
+				return nil, ErrForbidden
+			}
+
+			return handler(NewContext(ctx, claims), req)
+		}
+	}
+}
+
+// accept checks issuer and audience, a well signed token for another service is forbidden rather than unauthorized
+func (o *options) accept(claims *Claims) bool {
+	if o.issuer != "" && claims.Issuer != o.issuer {
+		return false
+	}
+	if len(o.audience) == 0 {
+		return true
+	}
+	for _, want := range o.audience {
+		for _, aud := range claims.Audience {
+			if aud == want {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type headerCarrier map[string]string
+
+func (h headerCarrier) Get(key string) string { return h[key] }
+func (h headerCarrier) Set(key, value string) { h[key] = value }
+func (h headerCarrier) Add(key, value string) { h[key] = value }
+func (h headerCarrier) Keys() []string        { return nil }
+func (h headerCarrier) Values(string) []string {
+	return nil
+}
+
+type testTransport struct {
+	header headerCarrier
+}
+
+func (t *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (t *testTransport) Endpoint() string                { return "" }
+func (t *testTransport) Operation() string               { return "" }
+func (t *testTransport) RequestHeader() transport.Header { return t.header }
+func (t *testTransport) ReplyHeader() transport.Header   { return headerCarrier{} }
+
+func call(t *testing.T, mw func(ctx context.Context, req any) (any, error), token string) (*Claims, error) {
+	t.Helper()
+	header := headerCarrier{}
+	if token != "" {
+		header[authorizationHeader] = bearerPrefix + token
+	}
+	ctx := transport.NewServerContext(context.Background(), &testTransport{header: header})
+	reply, err := mw(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return reply.(*Claims), nil
+}
+
+func newHandler(opts ...Option) func(ctx context.Context, req any) (any, error) {
+	return Server(opts...)(func(ctx context.Context, req any) (any, error) {
+		claims, _ := FromContext(ctx)
+		return claims, nil
+	})
+}
+
+func TestServerHS256(t *testing.T) {
+	secret := []byte("secret")
+	h := newHandler(WithHMACSecret(secret), WithIssuer("issuer"), WithAudience("server"))
+
+	sign := func(claims jwt.Claims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		require.NoError(t, err)
+		return token
+	}
+	valid := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "1",
+			Issuer:    "issuer",
+			Audience:  jwt.ClaimStrings{"server"},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+		Roles: []string{"admin"},
+	}
+
+	claims, err := call(t, h, sign(valid))
+	require.NoError(t, err)
+	require.Equal(t, "1", claims.Subject)
+	require.Equal(t, []string{"admin"}, claims.Roles)
+
+	_, err = call(t, h, "")
+	require.Equal(t, int32(401), errors.FromError(err).Code)
+
+	_, err = call(t, h, "not-a-jwt")
+	require.Equal(t, int32(401), errors.FromError(err).Code)
+
+	expired := valid
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	_, err = call(t, h, sign(expired))
+	require.Equal(t, ErrTokenExpired.Message, errors.FromError(err).Message)
+
+	otherAudience := valid
+	otherAudience.Audience = jwt.ClaimStrings{"other"}
+	_, err = call(t, h, sign(otherAudience))
+	require.Equal(t, int32(403), errors.FromError(err).Code)
+}
+
+func TestServerES256KeySetFile(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	enc := base64.RawURLEncoding
+	data, err := json.Marshal(jwks{Keys: []jwk{{
+		Kid: "k1",
+		Kty: "EC",
+		Crv: "P-256",
+		X:   enc.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   enc.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}}})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	keySet, err := LoadKeySetFile(path)
+	require.NoError(t, err)
+	h := newHandler(WithKeySet(keySet))
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+		Subject:   "2",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	})
+	token.Header["kid"] = "k1"
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	claims, err := call(t, h, signed)
+	require.NoError(t, err)
+	require.Equal(t, "2", claims.Subject)
+
+	// HS256 is not accepted without a secret
+	hs, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = call(t, h, hs)
+	require.Equal(t, int32(401), errors.FromError(err).Code)
+}
@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"server-template/pkg/log"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultRefreshInterval = time.Hour
+	// minRefreshInterval bounds refetching when a token carries an unknown kid
+	minRefreshInterval = time.Minute
+	fetchTimeout       = time.Second * 10
+)
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// KeySet holds the public keys of a JWKS document, keyed by kid.
+// A remote key set is refetched lazily when it is older than the refresh interval
+// or when a token carries an unknown kid.
+type KeySet struct {
+	mu          sync.RWMutex
+	keys        map[string]any
+	url         string
+	refresh     time.Duration
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	fetchMu     sync.Mutex // one refetch at a time, others keep using the current keys
+}
+
+// LoadKeySetFile reads a JWKS document from a local file
+func LoadKeySetFile(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "read jwks file %s failed", path)
+	}
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse jwks file %s failed", path)
+	}
+	return &KeySet{keys: keys}, nil
+}
+
+// NewRemoteKeySet fetches a JWKS document from url, refresh <= 0 uses one hour
+func NewRemoteKeySet(ctx context.Context, url string, refresh time.Duration) (*KeySet, error) {
+	if refresh <= 0 {
+		refresh = defaultRefreshInterval
+	}
+	ks := &KeySet{url: url, refresh: refresh}
+	if err := ks.fetch(ctx); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Key returns the public key for kid, an empty kid matches the only key of a single key set
+func (k *KeySet) Key(kid string) (any, error) {
+	k.mu.RLock()
+	key, ok := k.lookup(kid)
+	canRefetch := k.url != "" && time.Since(k.attemptedAt) > minRefreshInterval
+	stale := canRefetch && time.Since(k.fetchedAt) > k.refresh
+	k.mu.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+	if canRefetch && k.fetchMu.TryLock() {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		err := k.fetch(ctx)
+		cancel()
+		k.fetchMu.Unlock()
+		if err != nil {
+			// keep serving the keys we have
+			log.Warnf("refresh jwks %s failed, err: %+v", k.url, err)
+		}
+		k.mu.RLock()
+		key, ok = k.lookup(kid)
+		k.mu.RUnlock()
+	}
+	if !ok {
+		return nil, errors.Errorf("unknown key id: %q", kid)
+	}
+	return key, nil
+}
+
+func (k *KeySet) lookup(kid string) (any, bool) {
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, true
+		}
+	}
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+func (k *KeySet) fetch(ctx context.Context) error {
+	k.mu.Lock()
+	k.attemptedAt = time.Now()
+	k.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return errors.Wrapf(err, "new jwks request %s failed", k.url)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "fetch jwks %s failed", k.url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("fetch jwks %s failed, status: %d", k.url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "read jwks %s failed", k.url)
+	}
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return errors.Wrapf(err, "parse jwks %s failed", k.url)
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.fetchedAt = time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+// parseKeySet keeps the signing keys it understands, the others (eg: OKP or oct keys) are skipped
+func parseKeySet(data []byte) (map[string]any, error) {
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			log.Warnf("skip jwks key %q, err: %v", k.Kid, err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signing key found")
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		if k.N == "" || k.E == "" {
+			// eg: a key only published as x5c certificate chain
+			return nil, errors.New("missing rsa modulus or exponent")
+		}
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve: %s", k.Crv)
+		}
+		if k.X == "" || k.Y == "" {
+			return nil, errors.New("missing ec coordinates")
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, errors.Errorf("unsupported key type: %s", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode base64url failed")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeySet(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	enc := base64.RawURLEncoding
+	ec := jwk{
+		Kid: "ec",
+		Kty: "EC",
+		Crv: "P-256",
+		X:   enc.EncodeToString(key.X.FillBytes(make([]byte, 32))),
+		Y:   enc.EncodeToString(key.Y.FillBytes(make([]byte, 32))),
+	}
+	unsupported := []jwk{
+		{Kid: "okp", Kty: "OKP", Crv: "Ed25519", X: "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+		{Kid: "oct", Kty: "oct"},
+		{Kid: "secp256k1", Kty: "EC", Crv: "secp256k1", X: ec.X, Y: ec.Y},
+		{Kid: "x5c", Kty: "RSA"},
+	}
+
+	tests := []struct {
+		name     string
+		keys     []jwk
+		wantKids []string
+		wantErr  bool
+	}{
+		{name: "mixed", keys: append([]jwk{ec}, unsupported...), wantKids: []string{"ec"}},
+		{name: "encryption key", keys: []jwk{ec, {Kid: "enc", Kty: "EC", Use: "enc", Crv: "P-256", X: ec.X, Y: ec.Y}}, wantKids: []string{"ec"}},
+		{name: "no usable key", keys: unsupported, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(jwks{Keys: tt.keys})
+			require.NoError(t, err)
+
+			keys, err := parseKeySet(data)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			kids := make([]string, 0, len(keys))
+			for kid := range keys {
+				kids = append(kids, kid)
+			}
+			require.ElementsMatch(t, tt.wantKids, kids)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	authpb "server-template/api/auth"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var ErrPermissionDenied = errors.Forbidden("FORBIDDEN", "permission denied")
+
+// RequiredRoles reads the (api.auth.required_roles) option of every method, keyed by operation
+func RequiredRoles(services ...protoreflect.ServiceDescriptor) map[string][]string {
+	required := make(map[string][]string)
+	for _, service := range services {
+		methods := service.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			method := methods.Get(i)
+			roles, _ := proto.GetExtension(method.Options(), authpb.E_RequiredRoles).([]string)
+			if len(roles) == 0 {
+				continue
+			}
+			required[fmt.Sprintf("/%s/%s", service.FullName(), method.Name())] = roles
+		}
+	}
+	return required
+}
+
+// Authorize enforces the required roles declared on the service methods against the roles in the claims,
+// the caller needs any one of them. It must run after Server, methods without the option are not restricted.
+func Authorize(services ...protoreflect.ServiceDescriptor) middleware.Middleware {
+	required := RequiredRoles(services...)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			roles, ok := required[tr.Operation()]
+			if !ok {
+				return handler(ctx, req)
+			}
+			claims, ok := FromContext(ctx)
+			if !ok {
+				return nil, ErrMissingToken
+			}
+			if !claims.HasAnyRole(roles...) {
+				return nil, ErrPermissionDenied
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func (c *Claims) HasAnyRole(roles ...string) bool {
+	for _, want := range roles {
+		for _, role := range c.Roles {
+			if role == want {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"server-template/api/server"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/require"
+)
+
+type operationTransport struct {
+	testTransport
+	operation string
+}
+
+func (t *operationTransport) Operation() string { return t.operation }
+
+func TestAuthorize(t *testing.T) {
+	svc := server.File_server_server_proto.Services().ByName("Server")
+	require.Equal(t, []string{"admin"}, RequiredRoles(svc)[server.OperationServerDeleteUser])
+
+	h := Authorize(svc)(func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+	call := func(operation string, claims *Claims) error {
+		ctx := transport.NewServerContext(context.Background(), &operationTransport{operation: operation})
+		if claims != nil {
+			ctx = NewContext(ctx, claims)
+		}
+		_, err := h(ctx, nil)
+		return err
+	}
+
+	require.NoError(t, call(server.OperationServerGetUser, nil))
+	require.Equal(t, int32(401), errors.FromError(call(server.OperationServerDeleteUser, nil)).Code)
+	require.Equal(t, int32(403), errors.FromError(call(server.OperationServerDeleteUser, &Claims{})).Code)
+	require.NoError(t, call(server.OperationServerDeleteUser, &Claims{Roles: []string{"admin"}}))
+}
@@ -0,0 +1,239 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	lock "server-template/pkg/lock"
+	"server-template/pkg/log"
+	"server-template/pkg/middleware/auth"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-redis/redis/v8"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	Header         = "Idempotency-Key" // idempotency-key in grpc metadata
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	keyPrefix    = "idempotency:"
+	lockPrefix   = "lock:idempotency:"
+	lockWait     = time.Millisecond * 100
+	// lockMaxWait bounds how long a duplicate waits for the request holding its key
+	lockMaxWait  = time.Second * 10
+	redisTimeout = time.Second
+)
+
+var (
+	ErrInvalidKey = errors.BadRequest("IDEMPOTENCY_KEY_INVALID", "idempotency key must be 1 to 255 characters")
+	ErrKeyReused  = errors.New(422, "IDEMPOTENCY_KEY_REUSED", "idempotency key was already used with a different request")
+	ErrKeyInUse   = errors.Conflict("IDEMPOTENCY_KEY_IN_USE", "a request with the same idempotency key is still in progress")
+)
+
+// record is what a finished request leaves behind for its replays
+type record struct {
+	Fingerprint string `json:"fingerprint"`
+	Reply       []byte `json:"reply"` // anypb.Any
+}
+
+// Server is a middleware that makes requests carrying an Idempotency-Key safe to retry.
+// The first successful reply is stored for ttl and returned to every replay with the same key and payload,
+// concurrent duplicates wait on a lock until the first one finishes, up to 10s or the request deadline.
+// Errors are not stored, so a failed request can be retried with the same key. Without redis requests
+// run as if they had no key. Only select it for operations with side effects, see MutatingOperations.
+func Server(rdb redis.UniversalClient, ttl time.Duration) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (any, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			key := tr.RequestHeader().Get(Header)
+			if key == "" {
+				return handler(ctx, req)
+			}
+			if len(key) > maxKeyLength {
+				return nil, ErrInvalidKey
+			}
+			msg, ok := req.(proto.Message)
+			if !ok {
+				return handler(ctx, req)
+			}
+			fingerprint, err := fingerprintOf(tr.Operation(), msg)
+			if err != nil {
+				return nil, err
+			}
+
+			id := scope(ctx, key)
+			reply, found, err := lookup(ctx, rdb, id, fingerprint)
+			if err != nil {
+				if errors.Is(err, ErrKeyReused) {
+					return nil, err
+				}
+				log.WithContext(ctx).Warnf("idempotency lookup %s failed, run without it, err: %+v", tr.Operation(), err)
+				return handler(ctx, req)
+			}
+			if found {
+				tr.ReplyHeader().Set(ReplayedHeader, "true")
+				return reply, nil
+			}
+
+			unlock, err := waitLock(ctx, rdb, lockPrefix+id)
+			if err != nil {
+				return nil, err
+			}
+			defer unlock()
+
+			// the request we waited for may have finished meanwhile
+			reply, found, err = lookup(ctx, rdb, id, fingerprint)
+			if errors.Is(err, ErrKeyReused) {
+				return nil, err
+			}
+			if found {
+				tr.ReplyHeader().Set(ReplayedHeader, "true")
+				return reply, nil
+			}
+
+			resp, err := handler(ctx, req)
+			if err != nil {
+				return resp, err
+			}
+			if err := save(rdb, id, fingerprint, resp, ttl); err != nil {
+				log.WithContext(ctx).Warnf("idempotency save %s failed, err: %+v", tr.Operation(), err)
+			}
+			return resp, nil
+		}
+	}
+}
+
+// fingerprintOf hashes the operation and the deterministic wire form of the request
+func fingerprintOf(operation string, msg proto.Message) (string, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return "", errors.InternalServer("IDEMPOTENCY", "marshal request failed").WithCause(err)
+	}
+	h := sha256.New()
+	h.Write([]byte(operation))
+	h.Write([]byte{0})
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// scope keeps keys of different callers apart, the client key is hashed so its length and charset don't matter
+func scope(ctx context.Context, key string) string {
+	var subject string
+	if claims, ok := auth.FromContext(ctx); ok {
+		subject = claims.Subject
+	}
+	sum := sha256.Sum256([]byte(subject + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+func lookup(ctx context.Context, rdb redis.UniversalClient, id, fingerprint string) (proto.Message, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+	data, err := rdb.Get(ctx, keyPrefix+id).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var r record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, false, err
+	}
+	if r.Fingerprint != fingerprint {
+		return nil, false, ErrKeyReused
+	}
+	var a anypb.Any
+	if err := proto.Unmarshal(r.Reply, &a); err != nil {
+		return nil, false, err
+	}
+	reply, err := a.UnmarshalNew()
+	if err != nil {
+		return nil, false, err
+	}
+	return reply, true, nil
+}
+
+func save(rdb redis.UniversalClient, id, fingerprint string, reply any, ttl time.Duration) error {
+	msg, ok := reply.(proto.Message)
+	if !ok {
+		return errors.InternalServer("IDEMPOTENCY", "reply is not a proto message")
+	}
+	a, err := anypb.New(msg)
+	if err != nil {
+		return err
+	}
+	b, err := proto.Marshal(a)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(record{Fingerprint: fingerprint, Reply: b})
+	if err != nil {
+		return err
+	}
+	// the request is done, don't lose the reply to a client that went away
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	return rdb.Set(ctx, keyPrefix+id, data, ttl).Err()
+}
+
+// waitLock retries the lock until it is taken, lockMaxWait elapses or the request context is done
+func waitLock(ctx context.Context, rdb redis.UniversalClient, key string) (func() bool, error) {
+	locker := lock.NewLocker(rdb, lock.WithRetry(lock.FixedRetry(lockWait)), lock.WithMaxWait(lockMaxWait))
+	lease, err := locker.Acquire(ctx, key)
+	if err != nil {
+		return nil, lockError(err)
+	}
+	return func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+		defer cancel()
+		ok, _ := lease.Release(ctx)
+		return ok
+	}, nil
+}
+
+// lockError maps why the lock was not taken to the status the client should see
+func lockError(err error) error {
+	switch {
+	case errors.Is(err, lock.ErrNotObtained):
+		return ErrKeyInUse
+	case errors.Is(err, context.DeadlineExceeded):
+		return errors.GatewayTimeout("IDEMPOTENCY", "wait for idempotency lock timeout").WithCause(err)
+	case errors.Is(err, context.Canceled):
+		return errors.ClientClosed("IDEMPOTENCY", "request canceled").WithCause(err)
+	}
+	return errors.ServiceUnavailable("IDEMPOTENCY", "acquire idempotency lock failed").WithCause(err)
+}
+
+// MutatingOperations returns the operations of the services that are not mapped to http GET,
+// methods without a http rule are included
+func MutatingOperations(services ...protoreflect.ServiceDescriptor) []string {
+	var ops []string
+	for _, service := range services {
+		methods := service.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			method := methods.Get(i)
+			rule, _ := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+			if rule.GetGet() != "" {
+				continue
+			}
+			ops = append(ops, fmt.Sprintf("/%s/%s", service.FullName(), method.Name()))
+		}
+	}
+	return ops
+}
@@ -0,0 +1,249 @@
+package idempotency
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"server-template/api/server"
+	lock "server-template/pkg/lock"
+	"server-template/pkg/middleware/auth"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type headerCarrier map[string]string
+
+func (h headerCarrier) Get(key string) string { return h[key] }
+func (h headerCarrier) Set(key, value string) { h[key] = value }
+func (h headerCarrier) Add(key, value string) { h[key] = value }
+func (h headerCarrier) Keys() []string        { return nil }
+func (h headerCarrier) Values(string) []string {
+	return nil
+}
+
+type testTransport struct {
+	header headerCarrier
+	reply  headerCarrier
+}
+
+func (t *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (t *testTransport) Endpoint() string                { return "" }
+func (t *testTransport) Operation() string               { return "/api.server.Server/CreateUser" }
+func (t *testTransport) RequestHeader() transport.Header { return t.header }
+func (t *testTransport) ReplyHeader() transport.Header   { return t.reply }
+
+// createUser counts the calls and returns the call number as the user id
+type createUser struct {
+	calls atomic.Int32
+	delay time.Duration
+	err   error
+}
+
+func (c *createUser) handle(ctx context.Context, req any) (any, error) {
+	n := c.calls.Add(1)
+	time.Sleep(c.delay)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &server.CreateUserReply{Id: strconv.Itoa(int(n))}, nil
+}
+
+func newTestServer(t *testing.T, c *createUser) (func(ctx context.Context, key string, req *server.CreateUserReq) (*server.CreateUserReply, bool, error), *miniredis.Miniredis) {
+	m := miniredis.RunT(t)
+	h := Server(redis.NewClient(&redis.Options{Addr: m.Addr()}), time.Hour)(c.handle)
+	return func(ctx context.Context, key string, req *server.CreateUserReq) (*server.CreateUserReply, bool, error) {
+		tr := &testTransport{header: headerCarrier{Header: key}, reply: headerCarrier{}}
+		reply, err := h(transport.NewServerContext(ctx, tr), req)
+		if err != nil {
+			return nil, false, err
+		}
+		return reply.(*server.CreateUserReply), tr.reply[ReplayedHeader] == "true", nil
+	}, m
+}
+
+func TestFingerprint(t *testing.T) {
+	base, err := fingerprintOf("/api.server.Server/CreateUser", &server.CreateUserReq{Name: "a", Email: "a@b.c"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		operation string
+		req       *server.CreateUserReq
+		same      bool
+	}{
+		{
+			name:      "same payload",
+			operation: "/api.server.Server/CreateUser",
+			req:       &server.CreateUserReq{Name: "a", Email: "a@b.c"},
+			same:      true,
+		},
+		{
+			name:      "different payload",
+			operation: "/api.server.Server/CreateUser",
+			req:       &server.CreateUserReq{Name: "b", Email: "a@b.c"},
+		},
+		{
+			name:      "different operation",
+			operation: "/api.server.Server/UpdateUser",
+			req:       &server.CreateUserReq{Name: "a", Email: "a@b.c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fingerprintOf(tt.operation, tt.req)
+			require.NoError(t, err)
+			require.Equal(t, tt.same, got == base)
+		})
+	}
+}
+
+func TestServerReplay(t *testing.T) {
+	c := &createUser{}
+	call, _ := newTestServer(t, c)
+	ctx := context.Background()
+	req := &server.CreateUserReq{Name: "a", Email: "a@b.c"}
+
+	reply, replayed, err := call(ctx, "k1", req)
+	require.NoError(t, err)
+	require.False(t, replayed)
+	require.Equal(t, "1", reply.Id)
+
+	reply, replayed, err = call(ctx, "k1", &server.CreateUserReq{Name: "a", Email: "a@b.c"})
+	require.NoError(t, err)
+	require.True(t, replayed)
+	require.Equal(t, "1", reply.Id)
+
+	_, _, err = call(ctx, "k1", &server.CreateUserReq{Name: "b", Email: "a@b.c"})
+	require.ErrorIs(t, err, ErrKeyReused)
+	require.Equal(t, 422, errors.Code(err))
+
+	// keys are scoped by the caller
+	userCtx := auth.NewContext(ctx, &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "1"}})
+	reply, replayed, err = call(userCtx, "k1", req)
+	require.NoError(t, err)
+	require.False(t, replayed)
+	require.Equal(t, "2", reply.Id)
+
+	reply, replayed, err = call(ctx, "", req)
+	require.NoError(t, err)
+	require.False(t, replayed)
+	require.Equal(t, "3", reply.Id)
+	require.Equal(t, int32(3), c.calls.Load())
+}
+
+func TestServerErrorNotStored(t *testing.T) {
+	c := &createUser{err: errors.ServiceUnavailable("UNAVAILABLE", "try again")}
+	call, _ := newTestServer(t, c)
+	req := &server.CreateUserReq{Name: "a", Email: "a@b.c"}
+
+	_, _, err := call(context.Background(), "k1", req)
+	require.Error(t, err)
+
+	c.err = nil
+	reply, replayed, err := call(context.Background(), "k1", req)
+	require.NoError(t, err)
+	require.False(t, replayed)
+	require.Equal(t, "2", reply.Id)
+}
+
+func TestServerConcurrent(t *testing.T) {
+	c := &createUser{delay: lockWait * 3}
+	call, _ := newTestServer(t, c)
+	req := &server.CreateUserReq{Name: "a", Email: "a@b.c"}
+
+	const n = 5
+	var (
+		wg       sync.WaitGroup
+		replies  [n]*server.CreateUserReply
+		replayed [n]bool
+		errs     [n]error
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			replies[i], replayed[i], errs[i] = call(context.Background(), "k1", req)
+		}()
+	}
+	wg.Wait()
+
+	replays := 0
+	for i := 0; i < n; i++ {
+		require.NoError(t, errs[i])
+		require.Equal(t, "1", replies[i].Id)
+		if replayed[i] {
+			replays++
+		}
+	}
+	require.Equal(t, int32(1), c.calls.Load())
+	require.Equal(t, n-1, replays)
+}
+
+func TestServerWithoutRedis(t *testing.T) {
+	c := &createUser{}
+	call, m := newTestServer(t, c)
+	m.SetError("LOADING redis is loading")
+
+	for i := 1; i <= 2; i++ {
+		reply, replayed, err := call(context.Background(), "k1", &server.CreateUserReq{Name: "a", Email: "a@b.c"})
+		require.NoError(t, err)
+		require.False(t, replayed)
+		require.Equal(t, strconv.Itoa(i), reply.Id)
+	}
+}
+
+func TestServerLockWaitDeadline(t *testing.T) {
+	c := &createUser{delay: lockWait * 5}
+	call, _ := newTestServer(t, c)
+	req := &server.CreateUserReq{Name: "a", Email: "a@b.c"}
+
+	first := make(chan error, 1)
+	go func() {
+		_, _, err := call(context.Background(), "k1", req)
+		first <- err
+	}()
+	for c.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lockWait)
+	defer cancel()
+	_, _, err := call(ctx, "k1", req)
+	require.Equal(t, 504, errors.Code(err))
+	require.NoError(t, <-first)
+}
+
+func TestLockError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{err: lock.ErrNotObtained, want: 409},
+		{err: context.DeadlineExceeded, want: 504},
+		{err: context.Canceled, want: 499},
+		{err: errors.New(500, "REDIS", "redis is down"), want: 503},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, errors.Code(lockError(tt.err)), tt.err)
+	}
+}
+
+func TestMutatingOperations(t *testing.T) {
+	ops := MutatingOperations(server.File_server_server_proto.Services().ByName("Server"))
+	require.ElementsMatch(t, []string{
+		server.OperationServerCreateUser,
+		server.OperationServerUpdateUser,
+		server.OperationServerDeleteUser,
+		server.OperationServerRestoreUser,
+	}, ops)
+}
@@ -8,35 +8,45 @@ import (
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
-	"github.com/go-kratos/kratos/v2/transport/http"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type RequestIDKey struct{}
 
 const (
-	TraceIDHeader = "X-Request-ID"
-	CfRay         = "CF-Ray"
+	TraceIDHeader   = "X-Request-ID" // x-request-id in grpc metadata
+	CfRay           = "CF-Ray"
 	RequestIDHeader = "request_id"
 )
 
+// RequestIdHandler reads the request id from http headers or grpc metadata, falls back to the trace id
+// of the server span (taken from a w3c traceparent when present), generates one when there is no span,
+// and echoes it back in the reply header
 func RequestIdHandler(handler middleware.Handler) middleware.Handler {
 	return func(ctx context.Context, req interface{}) (reply interface{}, err error) {
 		var traceID string
-		if tr, ok := transport.FromServerContext(ctx); ok {
-			if ht, ok := tr.(http.Transporter); ok {
-				reqHeader := ht.RequestHeader()
-				traceID = reqHeader.Get(TraceIDHeader)
-				if traceID == "" {
-					traceID = reqHeader.Get(RequestIDHeader)
-				}
-				if traceID == "" {
-					traceID = reqHeader.Get(CfRay)
-				}
+		tr, ok := transport.FromServerContext(ctx)
+		if ok {
+			reqHeader := tr.RequestHeader()
+			traceID = reqHeader.Get(TraceIDHeader)
+			if traceID == "" {
+				traceID = reqHeader.Get(RequestIDHeader)
+			}
+			if traceID == "" {
+				traceID = reqHeader.Get(CfRay)
+			}
+		}
+		if traceID == "" {
+			if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+				traceID = sc.TraceID().String()
 			}
 		}
 		if traceID == "" {
 			traceID = pkg.GenShortID()
 		}
+		if ok {
+			tr.ReplyHeader().Set(TraceIDHeader, traceID)
+		}
 		ctx = context.WithValue(ctx, RequestIDKey{}, traceID)
 		return handler(ctx, req)
 	}
@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pkgLog "server-template/pkg/log"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+// Server is the kratos logging.Server with the (api.log.sensitive) fields of the request masked,
+// the reply is logged at debug level with the same masking
+func Server(logger log.Logger) middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req any) (reply any, err error) {
+			var (
+				code      = int32(status.FromGRPCCode(codes.OK))
+				reason    string
+				kind      string
+				operation string
+			)
+			startTime := time.Now()
+			if info, ok := transport.FromServerContext(ctx); ok {
+				kind = info.Kind().String()
+				operation = info.Operation()
+			}
+			reply, err = handler(ctx, req)
+			if se := errors.FromError(err); se != nil {
+				code = se.Code
+				reason = se.Reason
+			}
+			helper := log.NewHelper(log.WithContext(ctx, logger))
+			level, stack := extractError(err)
+			helper.Log(level,
+				"kind", "server",
+				"component", kind,
+				"operation", operation,
+				"args", extractArgs(req),
+				"code", code,
+				"reason", reason,
+				"stack", stack,
+				"latency", time.Since(startTime).Seconds(),
+			)
+			if err == nil {
+				helper.Log(log.LevelDebug,
+					"kind", "server",
+					"component", kind,
+					"operation", operation,
+					"reply", lazyArgs{reply},
+				)
+			} else {
+				helper.Log(log.LevelDebug,
+					"kind", "server",
+					"component", kind,
+					"operation", operation,
+					"cause", lazyError{err},
+				)
+			}
+			return
+		}
+	}
+}
+
+// extractArgs returns the string of a request or reply, proto messages are redacted first
+func extractArgs(v any) string {
+	if m, ok := v.(proto.Message); ok {
+		return fmt.Sprint(pkgLog.Redact(m))
+	}
+	if stringer, ok := v.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return fmt.Sprintf("%+v", v)
+}
+
+// lazyArgs defers formatting until the entry is written, a disabled debug level costs nothing
+type lazyArgs struct {
+	v any
+}
+
+func (a lazyArgs) String() string {
+	return extractArgs(a.v)
+}
+
+// lazyError formats the whole error chain, only at debug level as causes may quote user data
+type lazyError struct {
+	err error
+}
+
+func (e lazyError) String() string {
+	return fmt.Sprintf("%+v", e.err)
+}
+
+// extractError leaves out the cause of api errors, eg: a duplicate entry error quotes the duplicated email
+func extractError(err error) (log.Level, string) {
+	if err == nil {
+		return log.LevelInfo, ""
+	}
+	var se *errors.Error
+	if errors.As(err, &se) {
+		return log.LevelError, fmt.Sprintf("code = %d reason = %s message = %s", se.Code, se.Reason, se.Message)
+	}
+	return log.LevelError, fmt.Sprintf("%+v", err)
+}
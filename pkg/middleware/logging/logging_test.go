@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"server-template/api/server"
+	"server-template/internal/conf"
+	"server-template/pkg/env"
+	pkgLog "server-template/pkg/log"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	dupEmail := errors.Wrap(
+		server.ErrorAlreadyExists("record already exists").WithCause(&mysql.MySQLError{
+			Number:  1062,
+			Message: "Duplicate entry 'a@b.c' for key 'user_detail.uk_live_email'",
+		}),
+		"user create fail",
+	)
+	tests := []struct {
+		name    string
+		level   string
+		err     error
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "success",
+			level:   "debug",
+			want:    []string{"code=200", "reply="},
+			notWant: []string{"a@b.c"},
+		},
+		{
+			name:    "duplicate email",
+			level:   "info",
+			err:     dupEmail,
+			want:    []string{"code=409", "reason=ALREADY_EXISTS", "message = record already exists"},
+			notWant: []string{"a@b.c", "Duplicate entry"},
+		},
+		{
+			name:  "cause at debug level",
+			level: "debug",
+			err:   dupEmail,
+			want:  []string{"Duplicate entry"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := pkgLog.New(&buf, &conf.Log{Level: tt.level, Encoding: "logfmt"}, env.ModeProd)
+			h := Server(logger)(func(ctx context.Context, req any) (any, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &server.User{Id: "1", Email: "a@b.c"}, nil
+			})
+
+			_, err := h(context.Background(), &server.CreateUserReq{Name: "a", Email: "a@b.c"})
+			require.Equal(t, tt.err, err)
+			for _, s := range tt.want {
+				require.Contains(t, buf.String(), s)
+			}
+			for _, s := range tt.notWant {
+				require.NotContains(t, buf.String(), s)
+			}
+		})
+	}
+}
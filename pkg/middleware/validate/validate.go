@@ -23,3 +23,27 @@ func ProtoValidate() middleware.Middleware {
 		}
 	}
 }
+
+// FieldViolation is a single protovalidate rule violation
+type FieldViolation struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// FieldViolations extracts the protovalidate violations from err, it returns nil when err is not caused by validation
+func FieldViolations(err error) []FieldViolation {
+	var valErr *protovalidate.ValidationError
+	if !errors.As(err, &valErr) {
+		return nil
+	}
+	violations := make([]FieldViolation, 0, len(valErr.Violations))
+	for _, v := range valErr.Violations {
+		violations = append(violations, FieldViolation{
+			Field:   protovalidate.FieldPathString(v.Proto.GetField()),
+			Rule:    v.Proto.GetRuleId(),
+			Message: v.Proto.GetMessage(),
+		})
+	}
+	return violations
+}
@@ -0,0 +1,106 @@
+// Package outbox publishes the events stored in the outbox table to a sink. Events are written in the
+// transaction of the change that raised them, the relay delivers them at least once and in id order,
+// consumers dedupe by Message.ID.
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"server-template/pkg/log"
+	"server-template/pkg/metrics"
+	"server-template/pkg/retry"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultBatchSize = 100
+	// sendRetryTime bounds the retries of one message, it is marked failed and retried on the next poll
+	sendRetryTime = time.Second * 5
+)
+
+var (
+	publishedTotal = metrics.NewCounter("outbox_published_total", "Outbox events published, by topic.", "topic")
+	failedTotal    = metrics.NewCounter("outbox_failed_total", "Outbox deliveries that failed after retrying, by topic.", "topic")
+)
+
+// Message is one outbox row
+type Message struct {
+	ID          int64
+	Topic       string
+	AggregateID string
+	Payload     []byte // json
+	Attempts    int32
+	CreatedAt   time.Time
+}
+
+// Store reads and updates the outbox table
+type Store interface {
+	// Pending returns at most limit undelivered messages ordered by id
+	Pending(ctx context.Context, limit int32) ([]*Message, error)
+	Delivered(ctx context.Context, id int64) error
+	Failed(ctx context.Context, id int64, cause error) error
+	// Purge deletes at most limit messages delivered before the given time
+	Purge(ctx context.Context, before time.Time, limit int32) (int64, error)
+}
+
+// Sink publishes messages to the other systems
+type Sink interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Relay moves pending messages from the store to the sink
+type Relay struct {
+	store     Store
+	sink      Sink
+	batchSize int32
+	retryTime time.Duration
+}
+
+func NewRelay(store Store, sink Sink, batchSize int32) *Relay {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return &Relay{store: store, sink: sink, batchSize: batchSize, retryTime: sendRetryTime}
+}
+
+// RelayOnce publishes one batch and returns how many messages were delivered. It stops at the first
+// message the sink keeps rejecting, so later messages are not published before it.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	msgs, err := r.store.Pending(ctx, r.batchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, "list pending outbox failed")
+	}
+	for i, msg := range msgs {
+		if err := r.send(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return i, ctx.Err()
+			}
+			failedTotal.Inc(msg.Topic)
+			if ferr := r.store.Failed(ctx, msg.ID, err); ferr != nil {
+				log.WithContext(ctx).Warnf("record outbox %d failure failed, err: %+v", msg.ID, ferr)
+			}
+			return i, errors.Wrapf(err, "publish outbox %d failed", msg.ID)
+		}
+		// a message sent but not marked is sent again, consumers dedupe by id
+		if err := r.store.Delivered(ctx, msg.ID); err != nil {
+			return i, errors.Wrapf(err, "mark outbox %d delivered failed", msg.ID)
+		}
+		publishedTotal.Inc(msg.Topic)
+	}
+	return len(msgs), nil
+}
+
+func (r *Relay) send(ctx context.Context, msg *Message) error {
+	policy := backoff.NewExponentialBackOff()
+	policy.InitialInterval = time.Millisecond * 50
+	policy.MaxElapsedTime = r.retryTime
+	return retry.BackoffRetryWithPolicy(func() error {
+		if ctx.Err() != nil {
+			return retry.Permanent(ctx.Err())
+		}
+		return r.sink.Send(ctx, msg)
+	}, policy)
+}
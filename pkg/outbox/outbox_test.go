@@ -0,0 +1,106 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu        sync.Mutex
+	msgs      []*Message
+	delivered map[int64]bool
+	failed    map[int64]int
+}
+
+func newMemoryStore(topics ...string) *memoryStore {
+	s := &memoryStore{delivered: map[int64]bool{}, failed: map[int64]int{}}
+	for i, topic := range topics {
+		s.msgs = append(s.msgs, &Message{ID: int64(i + 1), Topic: topic, Payload: []byte(`{}`)})
+	}
+	return s
+}
+
+func (s *memoryStore) Pending(ctx context.Context, limit int32) ([]*Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var msgs []*Message
+	for _, m := range s.msgs {
+		if !s.delivered[m.ID] && len(msgs) < int(limit) {
+			msgs = append(msgs, m)
+		}
+	}
+	return msgs, nil
+}
+
+func (s *memoryStore) Delivered(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delivered[id] = true
+	return nil
+}
+
+func (s *memoryStore) Failed(ctx context.Context, id int64, cause error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed[id]++
+	return nil
+}
+
+func (s *memoryStore) Purge(ctx context.Context, before time.Time, limit int32) (int64, error) {
+	return 0, nil
+}
+
+func TestRelayOnce(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore("a", "b", "c")
+	sink := NewMemorySink()
+	relay := NewRelay(store, sink, 2)
+
+	n, err := relay.RelayOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	n, err = relay.RelayOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	n, err = relay.RelayOnce(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+
+	var topics []string
+	for _, m := range sink.Messages() {
+		topics = append(topics, m.Topic)
+	}
+	require.Equal(t, []string{"a", "b", "c"}, topics)
+}
+
+func TestRelayOnceStopsAtFailure(t *testing.T) {
+	store := newMemoryStore("a", "b")
+	sink := NewMemorySink()
+	sink.SetErr(errors.New("sink down"))
+	relay := NewRelay(store, sink, 10)
+	relay.retryTime = time.Millisecond * 100
+
+	n, err := relay.RelayOnce(context.Background())
+	require.Error(t, err)
+	require.Equal(t, 0, n)
+	require.Equal(t, 1, store.failed[1])
+	require.Zero(t, store.failed[2], "later messages must wait for the failed one")
+	require.Empty(t, sink.Messages())
+
+	// stopping the relay is not a failed delivery
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = relay.RelayOnce(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, store.failed[1])
+
+	sink.SetErr(nil)
+	n, err = relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+}
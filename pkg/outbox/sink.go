@@ -0,0 +1,80 @@
+package outbox
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+const defaultStreamPrefix = "events:"
+
+// RedisStreamSink appends every message to the stream prefix+topic, eg: events:user.created
+type RedisStreamSink struct {
+	rdb    redis.UniversalClient
+	prefix string
+	maxLen int64
+}
+
+// NewRedisStreamSink keeps about maxLen entries per stream, 0 keeps all of them.
+// An empty prefix uses events:
+func NewRedisStreamSink(rdb redis.UniversalClient, prefix string, maxLen int64) *RedisStreamSink {
+	if prefix == "" {
+		prefix = defaultStreamPrefix
+	}
+	return &RedisStreamSink{rdb: rdb, prefix: prefix, maxLen: maxLen}
+}
+
+func (s *RedisStreamSink) Send(ctx context.Context, msg *Message) error {
+	err := s.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.prefix + msg.Topic,
+		MaxLen: s.maxLen,
+		Approx: s.maxLen > 0,
+		Values: map[string]any{
+			"id":           strconv.FormatInt(msg.ID, 10),
+			"topic":        msg.Topic,
+			"aggregate_id": msg.AggregateID,
+			"payload":      msg.Payload,
+			"created_at":   msg.CreatedAt.UTC().Format(time.RFC3339Nano),
+		},
+	}).Err()
+	return errors.Wrapf(err, "xadd %s failed", s.prefix+msg.Topic)
+}
+
+// MemorySink keeps the sent messages, for tests
+type MemorySink struct {
+	mu   sync.Mutex
+	msgs []*Message
+	err  error
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Send(ctx context.Context, msg *Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.msgs = append(s.msgs, msg)
+	return nil
+}
+
+// SetErr fails every following send with err, nil accepts them again
+func (s *MemorySink) SetErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// Messages returns the sent messages in order
+func (s *MemorySink) Messages() []*Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Message(nil), s.msgs...)
+}
@@ -13,6 +13,10 @@ func DecodePageToken(pageToken string) (id int64, err error) {
 		err = errors.Wrap(err, "decode page token failed")
 		return
 	}
+	if len(data) != 8 {
+		err = errors.Errorf("decode page token failed, invalid length: %d", len(data))
+		return
+	}
 
 	id = BytesToInt64(data)
 	return
@@ -0,0 +1,59 @@
+package pkg
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageToken(t *testing.T) {
+	tests := []struct {
+		name string
+		id   int64
+	}{
+		{
+			name: "zero",
+			id:   0,
+		},
+		{
+			name: "one",
+			id:   1,
+		},
+		{
+			name: "max int64",
+			id:   math.MaxInt64,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := DecodePageToken(EncodePageToken(tt.id))
+			require.NoError(t, err)
+			require.Equal(t, tt.id, id)
+		})
+	}
+}
+
+func TestDecodePageTokenInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		pageToken string
+	}{
+		{
+			name:      "not base64",
+			pageToken: "!!!",
+		},
+		{
+			name:      "short",
+			pageToken: "AQ==",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodePageToken(tt.pageToken)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"context"
+
+	"server-template/pkg/middleware/auth"
+)
+
+// Subject is the caller asking for permission
+type Subject struct {
+	ID    string
+	Roles []string
+}
+
+// Resource is the object an action is performed on
+type Resource struct {
+	Type    string
+	ID      string
+	OwnerID string
+}
+
+// Rule allows or denies subject to perform action on resource
+type Rule func(sub Subject, action string, res Resource) bool
+
+// Owner allows the subject that owns the resource
+func Owner(sub Subject, action string, res Resource) bool {
+	return sub.ID != "" && sub.ID == res.OwnerID
+}
+
+// HasRole allows subjects with any one of roles
+func HasRole(roles ...string) Rule {
+	return func(sub Subject, action string, res Resource) bool {
+		for _, want := range roles {
+			for _, role := range sub.Roles {
+				if role == want {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// Any allows when any one of rules allows
+func Any(rules ...Rule) Rule {
+	return func(sub Subject, action string, res Resource) bool {
+		for _, rule := range rules {
+			if rule(sub, action, res) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+type SubjectFunc func(ctx context.Context) (Subject, bool)
+
+// FromClaims reads the subject from the jwt claims in the context
+func FromClaims(ctx context.Context) (Subject, bool) {
+	claims, ok := auth.FromContext(ctx)
+	if !ok {
+		return Subject{}, false
+	}
+	return Subject{ID: claims.Subject, Roles: claims.Roles}, true
+}
+
+type Option func(*Engine)
+
+// WithSuperRoles allows subjects with any one of roles to do everything
+func WithSuperRoles(roles ...string) Option {
+	return func(e *Engine) {
+		e.superRule = HasRole(roles...)
+	}
+}
+
+// WithAllowAnonymous allows requests without a subject, used when authentication is disabled
+func WithAllowAnonymous() Option {
+	return func(e *Engine) {
+		e.allowAnonymous = true
+	}
+}
+
+func WithSubjectFunc(f SubjectFunc) Option {
+	return func(e *Engine) {
+		e.subject = f
+	}
+}
+
+// Engine decides whether the subject in the context may perform an action on a resource.
+// It denies by default, rules are registered per resource type and action.
+type Engine struct {
+	rules          map[string]Rule
+	superRule      Rule
+	allowAnonymous bool
+	subject        SubjectFunc
+}
+
+func NewEngine(opts ...Option) *Engine {
+	e := &Engine{
+		rules:   make(map[string]Rule),
+		subject: FromClaims,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Register sets the rule for action on resourceType, it is not safe to call concurrently with Can
+func (e *Engine) Register(resourceType, action string, rule Rule) {
+	e.rules[resourceType+":"+action] = rule
+}
+
+func (e *Engine) Can(ctx context.Context, action string, res Resource) bool {
+	sub, ok := e.subject(ctx)
+	if !ok {
+		return e.allowAnonymous
+	}
+	if e.superRule != nil && e.superRule(sub, action, res) {
+		return true
+	}
+	rule, ok := e.rules[res.Type+":"+action]
+	if !ok {
+		return false
+	}
+	return rule(sub, action, res)
+}
@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type subjectKey struct{}
+
+func withSubject(ctx context.Context, sub Subject) context.Context {
+	return context.WithValue(ctx, subjectKey{}, sub)
+}
+
+func subjectFromContext(ctx context.Context) (Subject, bool) {
+	sub, ok := ctx.Value(subjectKey{}).(Subject)
+	return sub, ok
+}
+
+func TestEngineCan(t *testing.T) {
+	e := NewEngine(WithSuperRoles("admin"), WithSubjectFunc(subjectFromContext))
+	e.Register("user", "update", Owner)
+	e.Register("user", "export", HasRole("auditor"))
+
+	self := Resource{Type: "user", ID: "1", OwnerID: "1"}
+	other := Resource{Type: "user", ID: "2", OwnerID: "2"}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		action string
+		res    Resource
+		want   bool
+	}{
+		{
+			name:   "anonymous denied",
+			ctx:    context.Background(),
+			action: "update",
+			res:    self,
+			want:   false,
+		},
+		{
+			name:   "owner allowed",
+			ctx:    withSubject(context.Background(), Subject{ID: "1"}),
+			action: "update",
+			res:    self,
+			want:   true,
+		},
+		{
+			name:   "other user denied",
+			ctx:    withSubject(context.Background(), Subject{ID: "1"}),
+			action: "update",
+			res:    other,
+			want:   false,
+		},
+		{
+			name:   "admin allowed",
+			ctx:    withSubject(context.Background(), Subject{ID: "9", Roles: []string{"admin"}}),
+			action: "update",
+			res:    other,
+			want:   true,
+		},
+		{
+			name:   "role allowed",
+			ctx:    withSubject(context.Background(), Subject{ID: "3", Roles: []string{"auditor"}}),
+			action: "export",
+			res:    other,
+			want:   true,
+		},
+		{
+			name:   "unregistered action denied",
+			ctx:    withSubject(context.Background(), Subject{ID: "1"}),
+			action: "delete",
+			res:    self,
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, e.Can(tt.ctx, tt.action, tt.res))
+		})
+	}
+}
+
+func TestEngineAllowAnonymous(t *testing.T) {
+	e := NewEngine(WithAllowAnonymous(), WithSubjectFunc(subjectFromContext))
+	require.True(t, e.Can(context.Background(), "update", Resource{Type: "user"}))
+}
@@ -0,0 +1,100 @@
+// Package queue runs background jobs on redis streams. Every job has its own stream, the workers read it
+// through a consumer group, so each job is handled by one worker, at least once.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"server-template/pkg/retry"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultPrefix = "queue:"
+	payloadField  = "payload"
+)
+
+// Enqueuer adds a job to the stream of its name
+type Enqueuer interface {
+	Enqueue(ctx context.Context, job string, payload []byte) (string, error)
+}
+
+// Job is a job whose payload is T encoded as json, declare it once and use it to enqueue and to
+// register the handler, eg:
+//
+//	var SendWelcomeEmail = queue.NewJob[WelcomeEmail]("user.welcome_email")
+type Job[T any] struct {
+	name string
+}
+
+func NewJob[T any](name string) Job[T] {
+	return Job[T]{name: name}
+}
+
+func (j Job[T]) Name() string {
+	return j.name
+}
+
+// Enqueue adds the job and returns its stream entry id
+func (j Job[T]) Enqueue(ctx context.Context, q Enqueuer, payload T) (string, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return "", errors.Wrapf(err, "marshal %s job failed", j.name)
+	}
+	return q.Enqueue(ctx, j.name, b)
+}
+
+// Handle registers fn as the handler of job, a payload that can't be decoded goes to the dead letter stream
+func Handle[T any](w *Worker, job Job[T], fn func(ctx context.Context, payload T) error, opts ...HandlerOption) {
+	w.Register(job.name, func(ctx context.Context, b []byte) error {
+		var payload T
+		if err := json.Unmarshal(b, &payload); err != nil {
+			return SkipRetry(errors.Wrapf(err, "unmarshal %s job failed", job.name))
+		}
+		return fn(ctx, payload)
+	}, opts...)
+}
+
+// SkipRetry wraps an error that retrying won't fix, the job goes to the dead letter stream at once
+func SkipRetry(err error) error {
+	return retry.Permanent(err)
+}
+
+// Client enqueues jobs
+type Client struct {
+	rdb    redis.UniversalClient
+	prefix string
+}
+
+// NewClient enqueues into the streams prefix+{job}, an empty prefix uses queue:
+func NewClient(rdb redis.UniversalClient, prefix string) *Client {
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+	return &Client{rdb: rdb, prefix: prefix}
+}
+
+// Enqueue adds the job to its stream. The streams are not trimmed, workers delete the jobs they finish.
+func (c *Client) Enqueue(ctx context.Context, job string, payload []byte) (string, error) {
+	id, err := c.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: streamKey(c.prefix, job),
+		Values: map[string]any{
+			payloadField:  payload,
+			"enqueued_at": time.Now().UTC().Format(time.RFC3339Nano),
+		},
+	}).Result()
+	return id, errors.Wrapf(err, "enqueue %s job failed", job)
+}
+
+// streamKey hash tags the job name so a job stream and its dead letter stream share a cluster slot
+func streamKey(prefix, job string) string {
+	return prefix + "{" + job + "}"
+}
+
+func deadKey(prefix, job string) string {
+	return streamKey(prefix, job) + ":dead"
+}
@@ -0,0 +1,136 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+)
+
+type welcome struct {
+	UserID int64 `json:"user_id"`
+}
+
+var welcomeJob = NewJob[welcome]("user.welcome_email")
+
+type memoryQueue struct {
+	jobs map[string][][]byte
+}
+
+func (q *memoryQueue) Enqueue(ctx context.Context, job string, payload []byte) (string, error) {
+	q.jobs[job] = append(q.jobs[job], payload)
+	return "1-0", nil
+}
+
+func TestRun(t *testing.T) {
+	errFail := errors.New("fail")
+	tests := []struct {
+		name     string
+		payload  string
+		fn       func(calls int) error
+		wantErr  bool
+		wantCall int
+	}{
+		{name: "ok", payload: `{"user_id":1}`, fn: func(int) error { return nil }, wantCall: 1},
+		{name: "retried", payload: `{"user_id":1}`, fn: func(calls int) error {
+			if calls < 3 {
+				return errFail
+			}
+			return nil
+		}, wantCall: 3},
+		{name: "retries exhausted", payload: `{"user_id":1}`, fn: func(int) error { return errFail }, wantErr: true, wantCall: 3},
+		{name: "skip retry", payload: `{"user_id":1}`, fn: func(int) error { return SkipRetry(errFail) }, wantErr: true, wantCall: 1},
+		{name: "panic", payload: `{"user_id":1}`, fn: func(int) error { panic("boom") }, wantErr: true, wantCall: 1},
+		{name: "bad payload", payload: `{`, fn: func(int) error { return nil }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWorker(nil)
+			calls := 0
+			Handle(w, welcomeJob, func(ctx context.Context, payload welcome) error {
+				calls++
+				require.Equal(t, int64(1), payload.UserID)
+				return tt.fn(calls)
+			}, WithMaxRetries(2), WithBackoff(time.Millisecond, time.Millisecond))
+
+			err := w.run(context.Background(), w.handlers[0], []byte(tt.payload))
+			require.Equal(t, tt.wantErr, err != nil, err)
+			require.Equal(t, tt.wantCall, calls)
+		})
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	q := &memoryQueue{jobs: map[string][][]byte{}}
+	_, err := welcomeJob.Enqueue(context.Background(), q, welcome{UserID: 1})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte(`{"user_id":1}`)}, q.jobs["user.welcome_email"])
+	require.Equal(t, "queue:{user.welcome_email}", streamKey(defaultPrefix, welcomeJob.Name()))
+}
+
+func TestParseAutoClaim(t *testing.T) {
+	msgs, next, err := parseAutoClaim([]any{
+		"0-0",
+		[]any{
+			[]any{"1-0", []any{"payload", `{"user_id":1}`}},
+			nil, // deleted while pending, redis 6.2
+		},
+		[]any{"2-0"}, // deleted ids, redis 7
+	})
+	require.NoError(t, err)
+	require.Equal(t, "0-0", next)
+	require.Len(t, msgs, 1)
+	require.Equal(t, "1-0", msgs[0].ID)
+	require.Equal(t, `{"user_id":1}`, msgs[0].Values["payload"])
+
+	_, _, err = parseAutoClaim("OK")
+	require.Error(t, err)
+}
+
+func TestWorkerStartStop(t *testing.T) {
+	m := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: m.Addr()})
+
+	w := NewWorker(rdb, WithLogger(log.DefaultLogger))
+	handled := make(chan int64, 1)
+	Handle(w, welcomeJob, func(ctx context.Context, payload welcome) error {
+		handled <- payload.UserID
+		return nil
+	})
+	done := make(chan error)
+	go func() { done <- w.Start(context.Background()) }()
+
+	_, err := welcomeJob.Enqueue(context.Background(), NewClient(rdb, ""), welcome{UserID: 1})
+	require.NoError(t, err)
+	select {
+	case id := <-handled:
+		require.Equal(t, int64(1), id)
+	case <-time.After(time.Second * 5):
+		t.Fatal("job not handled")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, w.Stop(ctx))
+	require.NoError(t, <-done)
+}
+
+func TestWorkerStopBeforeStart(t *testing.T) {
+	m := miniredis.RunT(t)
+	w := NewWorker(redis.NewClient(&redis.Options{Addr: m.Addr()}), WithLogger(log.DefaultLogger))
+	require.NoError(t, w.Stop(context.Background()))
+
+	done := make(chan error)
+	go func() { done <- w.Start(context.Background()) }()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("worker did not stop")
+	}
+}
@@ -0,0 +1,527 @@
+package queue
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"server-template/pkg"
+	"server-template/pkg/metrics"
+	"server-template/pkg/retry"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultGroup         = "workers"
+	defaultConcurrency   = 10
+	defaultBlock         = time.Second * 5
+	defaultClaimIdle     = time.Minute * 10
+	defaultMaxDeliveries = 5
+
+	defaultMaxRetries      = 3
+	defaultTimeout         = time.Minute
+	defaultInitialInterval = time.Second
+	defaultMaxInterval     = time.Second * 30
+
+	// maxErrorLen bounds the error kept with a dead job
+	maxErrorLen = 1024
+)
+
+var (
+	jobsTotal   = metrics.NewCounter("queue_jobs_total", "Jobs handled, by job and result: done or dead.", "job", "result")
+	jobDuration = metrics.NewHistogram("queue_job_duration_seconds", "Time to handle a job with its retries, by job.", nil, "job")
+)
+
+var _ transport.Server = (*Worker)(nil)
+
+// Handler handles the payload of a job, it is retried while it returns an error
+type Handler func(ctx context.Context, payload []byte) error
+
+type handler struct {
+	job    string
+	stream string
+	dead   string
+	fn     Handler
+
+	maxRetries      uint64
+	timeout         time.Duration
+	initialInterval time.Duration
+	maxInterval     time.Duration
+}
+
+// HandlerOption configures how a job is handled
+type HandlerOption func(*handler)
+
+// WithMaxRetries retries a failed job n times before it goes to the dead letter stream, defaults to 3
+func WithMaxRetries(n uint64) HandlerOption {
+	return func(h *handler) {
+		h.maxRetries = n
+	}
+}
+
+// WithTimeout bounds one attempt, defaults to 1m
+func WithTimeout(d time.Duration) HandlerOption {
+	return func(h *handler) {
+		h.timeout = d
+	}
+}
+
+// WithBackoff waits initial before the first retry, doubling up to max, defaults to 1s and 30s
+func WithBackoff(initial, max time.Duration) HandlerOption {
+	return func(h *handler) {
+		h.initialInterval = initial
+		h.maxInterval = max
+	}
+}
+
+type options struct {
+	prefix        string
+	group         string
+	consumer      string
+	concurrency   int
+	block         time.Duration
+	claimIdle     time.Duration
+	maxDeliveries int64
+	deadMaxLen    int64
+	logger        log.Logger
+}
+
+// Option configures a Worker
+type Option func(*options)
+
+// WithPrefix reads the streams prefix+{job}, defaults to queue:
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithGroup sets the consumer group, defaults to workers
+func WithGroup(group string) Option {
+	return func(o *options) {
+		o.group = group
+	}
+}
+
+// WithConsumer names this worker in the group, defaults to the hostname
+func WithConsumer(consumer string) Option {
+	return func(o *options) {
+		o.consumer = consumer
+	}
+}
+
+// WithConcurrency sets how many jobs are handled at the same time, defaults to 10
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithClaimIdle reclaims the jobs left pending longer than d by a crashed worker, defaults to 10m.
+// Keep it above the longest job with its retries or the job runs twice.
+func WithClaimIdle(d time.Duration) Option {
+	return func(o *options) {
+		o.claimIdle = d
+	}
+}
+
+// WithMaxDeliveries sends a job reclaimed more than n times to the dead letter stream, defaults to 5
+func WithMaxDeliveries(n int64) Option {
+	return func(o *options) {
+		o.maxDeliveries = n
+	}
+}
+
+// WithDeadMaxLen keeps about n entries per dead letter stream, defaults to 0 which keeps all of them
+func WithDeadMaxLen(n int64) Option {
+	return func(o *options) {
+		o.deadMaxLen = n
+	}
+}
+
+func WithLogger(logger log.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// Worker handles the jobs registered on it, it starts and stops with kratos.App.
+// Jobs still running on Stop get until the stop timeout to finish, then their context is cancelled
+// and they are left pending to be reclaimed.
+type Worker struct {
+	rdb      redis.UniversalClient
+	opts     options
+	handlers []*handler
+	log      *log.Helper
+
+	quit      context.Context // done on Stop, even one called before Start, stops fetching
+	cancel    context.CancelFunc
+	jobQuit   context.Context // done when Stop times out, cancels the running jobs
+	jobCancel context.CancelFunc
+	started   atomic.Bool
+	done      chan struct{}
+}
+
+func NewWorker(rdb redis.UniversalClient, opts ...Option) *Worker {
+	o := options{
+		prefix:        defaultPrefix,
+		group:         defaultGroup,
+		concurrency:   defaultConcurrency,
+		block:         defaultBlock,
+		claimIdle:     defaultClaimIdle,
+		maxDeliveries: defaultMaxDeliveries,
+		logger:        log.GetLogger(),
+	}
+	o.consumer, _ = os.Hostname()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.prefix == "" {
+		o.prefix = defaultPrefix
+	}
+	if o.concurrency <= 0 {
+		o.concurrency = defaultConcurrency
+	}
+	if o.claimIdle <= 0 {
+		o.claimIdle = defaultClaimIdle
+	}
+	w := &Worker{
+		rdb:  rdb,
+		opts: o,
+		log:  log.NewHelper(log.With(o.logger, "module", "queue")),
+		done: make(chan struct{}),
+	}
+	w.quit, w.cancel = context.WithCancel(context.Background())
+	w.jobQuit, w.jobCancel = context.WithCancel(context.Background())
+	return w
+}
+
+// Register handles job with fn, call it before Start. Use Handle for a typed job.
+func (w *Worker) Register(job string, fn Handler, opts ...HandlerOption) {
+	h := &handler{
+		job:             job,
+		stream:          streamKey(w.opts.prefix, job),
+		dead:            deadKey(w.opts.prefix, job),
+		fn:              fn,
+		maxRetries:      defaultMaxRetries,
+		timeout:         defaultTimeout,
+		initialInterval: defaultInitialInterval,
+		maxInterval:     defaultMaxInterval,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	w.handlers = append(w.handlers, h)
+}
+
+type delivery struct {
+	h         *handler
+	msg       redis.XMessage
+	reclaimed bool
+}
+
+func (w *Worker) Start(ctx context.Context) error {
+	w.started.Store(true)
+	defer close(w.done)
+	fetchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(w.quit, cancel)()
+	jobCtx, jobCancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer jobCancel()
+	defer context.AfterFunc(w.jobQuit, jobCancel)()
+
+	for _, h := range w.handlers {
+		if err := w.createGroup(ctx, h); err != nil {
+			return err
+		}
+	}
+	w.log.Infof("queue worker started, jobs: %d, concurrency: %d", len(w.handlers), w.opts.concurrency)
+
+	deliveries := make(chan delivery)
+	var workers sync.WaitGroup
+	for i := 0; i < w.opts.concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for d := range deliveries {
+				w.process(jobCtx, d)
+			}
+		}()
+	}
+
+	// streams of different jobs may live on different cluster slots, so each one is read on its own
+	var fetchers sync.WaitGroup
+	for _, h := range w.handlers {
+		fetchers.Add(1)
+		go func() {
+			defer fetchers.Done()
+			defer pkg.Recover(nil)
+			w.fetch(fetchCtx, h, deliveries)
+		}()
+	}
+	fetchers.Add(1)
+	go func() {
+		defer fetchers.Done()
+		defer pkg.Recover(nil)
+		w.reclaim(fetchCtx, deliveries)
+	}()
+
+	fetchers.Wait()
+	close(deliveries)
+	workers.Wait()
+	return nil
+}
+
+func (w *Worker) Stop(ctx context.Context) error {
+	w.cancel()
+	// a Start that comes later returns at once
+	if !w.started.Load() {
+		return nil
+	}
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		w.jobCancel()
+		return ctx.Err()
+	}
+}
+
+func (w *Worker) createGroup(ctx context.Context, h *handler) error {
+	err := w.rdb.XGroupCreateMkStream(ctx, h.stream, w.opts.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return errors.Wrapf(err, "create group %s of %s failed", w.opts.group, h.stream)
+	}
+	return nil
+}
+
+// fetch reads the new jobs of h, it waits for free workers before reading more
+func (w *Worker) fetch(ctx context.Context, h *handler, out chan<- delivery) {
+	args := &redis.XReadGroupArgs{
+		Group:    w.opts.group,
+		Consumer: w.opts.consumer,
+		Streams:  []string{h.stream, ">"},
+		Count:    int64(w.opts.concurrency),
+		Block:    w.opts.block,
+	}
+	for ctx.Err() == nil {
+		streams, err := w.rdb.XReadGroup(ctx, args).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+				continue
+			}
+			w.log.Warnf("read %s failed, err: %+v", h.stream, err)
+			// the stream or the group was deleted
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				if err := w.createGroup(ctx, h); err != nil {
+					w.log.Warnf("%+v", err)
+				}
+			}
+			sleep(ctx, time.Second)
+			continue
+		}
+		for _, s := range streams {
+			for _, msg := range s.Messages {
+				select {
+				case out <- delivery{h: h, msg: msg}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// reclaim takes over the jobs of crashed workers
+func (w *Worker) reclaim(ctx context.Context, out chan<- delivery) {
+	ticker := time.NewTicker(w.opts.claimIdle / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, h := range w.handlers {
+			w.claim(ctx, h, out)
+		}
+	}
+}
+
+func (w *Worker) claim(ctx context.Context, h *handler, out chan<- delivery) {
+	start := "0-0"
+	for {
+		msgs, next, err := w.autoClaim(ctx, h.stream, start)
+		if err != nil {
+			if ctx.Err() == nil {
+				w.log.Warnf("reclaim %s failed, err: %+v", h.stream, err)
+			}
+			return
+		}
+		for _, msg := range msgs {
+			select {
+			case out <- delivery{h: h, msg: msg, reclaimed: true}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if next == "0-0" {
+			return
+		}
+		start = next
+	}
+}
+
+// autoClaim runs XAUTOCLAIM, go-redis v8 can't read the reply of redis 7 which adds the deleted ids
+func (w *Worker) autoClaim(ctx context.Context, stream, start string) ([]redis.XMessage, string, error) {
+	reply, err := w.rdb.Do(ctx, "xautoclaim", stream, w.opts.group, w.opts.consumer,
+		w.opts.claimIdle.Milliseconds(), start, "count", w.opts.concurrency).Result()
+	if err != nil {
+		return nil, "", err
+	}
+	return parseAutoClaim(reply)
+}
+
+func parseAutoClaim(reply any) ([]redis.XMessage, string, error) {
+	arr, ok := reply.([]any)
+	if !ok || len(arr) < 2 {
+		return nil, "", errors.Errorf("unexpected xautoclaim reply %v", reply)
+	}
+	next, ok := arr[0].(string)
+	entries, ok2 := arr[1].([]any)
+	if !ok || !ok2 {
+		return nil, "", errors.Errorf("unexpected xautoclaim reply %v", reply)
+	}
+	msgs := make([]redis.XMessage, 0, len(entries))
+	for _, e := range entries {
+		// redis 6.2 returns nil for the entries deleted while pending
+		entry, ok := e.([]any)
+		if !ok || len(entry) != 2 {
+			continue
+		}
+		id, _ := entry[0].(string)
+		fields, _ := entry[1].([]any)
+		values := make(map[string]any, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			if k, ok := fields[i].(string); ok {
+				values[k] = fields[i+1]
+			}
+		}
+		msgs = append(msgs, redis.XMessage{ID: id, Values: values})
+	}
+	return msgs, next, nil
+}
+
+func (w *Worker) process(ctx context.Context, d delivery) {
+	if d.reclaimed {
+		n, err := w.deliveryCount(ctx, d)
+		if err != nil {
+			w.log.Warnf("read deliveries of %s %s failed, err: %+v", d.h.job, d.msg.ID, err)
+		} else if w.opts.maxDeliveries > 0 && n > w.opts.maxDeliveries {
+			w.bury(ctx, d, errors.Errorf("delivered %d times", n))
+			return
+		}
+	}
+
+	payload, _ := d.msg.Values[payloadField].(string)
+	start := time.Now()
+	err := w.run(ctx, d.h, []byte(payload))
+	jobDuration.Observe(time.Since(start).Seconds(), d.h.job)
+	if err != nil && ctx.Err() != nil {
+		// stopped, the job stays pending and is reclaimed
+		return
+	}
+	if err != nil {
+		w.log.Errorf("job %s %s failed, err: %+v", d.h.job, d.msg.ID, err)
+		w.bury(ctx, d, err)
+		return
+	}
+	jobsTotal.Inc(d.h.job, "done")
+	ctx = context.WithoutCancel(ctx)
+	_, err = w.rdb.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.XAck(ctx, d.h.stream, w.opts.group, d.msg.ID)
+		p.XDel(ctx, d.h.stream, d.msg.ID)
+		return nil
+	})
+	if err != nil {
+		w.log.Warnf("ack job %s %s failed, it will run again, err: %+v", d.h.job, d.msg.ID, err)
+	}
+}
+
+// run calls the handler, retrying with backoff, a panic is not retried
+func (w *Worker) run(ctx context.Context, h *handler, payload []byte) error {
+	policy := backoff.NewExponentialBackOff()
+	policy.InitialInterval = h.initialInterval
+	policy.MaxInterval = h.maxInterval
+	policy.MaxElapsedTime = 0 // bounded by maxRetries
+	return retry.BackoffRetryN(ctx, func() error {
+		return h.attempt(ctx, payload)
+	}, policy, h.maxRetries)
+}
+
+func (h *handler) attempt(ctx context.Context, payload []byte) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	defer pkg.Recover(func() {
+		err = SkipRetry(errors.Errorf("job %s panicked", h.job))
+	})
+	return h.fn(ctx, payload)
+}
+
+func (w *Worker) deliveryCount(ctx context.Context, d delivery) (int64, error) {
+	pending, err := w.rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: d.h.stream,
+		Group:  w.opts.group,
+		Start:  d.msg.ID,
+		End:    d.msg.ID,
+		Count:  1,
+	}).Result()
+	if err != nil || len(pending) == 0 {
+		return 0, err
+	}
+	return pending[0].RetryCount, nil
+}
+
+// bury moves the job to its dead letter stream
+func (w *Worker) bury(ctx context.Context, d delivery, cause error) {
+	jobsTotal.Inc(d.h.job, "dead")
+	msg := cause.Error()
+	if len(msg) > maxErrorLen {
+		msg = msg[:maxErrorLen]
+	}
+	ctx = context.WithoutCancel(ctx)
+	_, err := w.rdb.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.XAdd(ctx, &redis.XAddArgs{
+			Stream: d.h.dead,
+			MaxLen: w.opts.deadMaxLen,
+			Approx: w.opts.deadMaxLen > 0,
+			Values: map[string]any{
+				payloadField: d.msg.Values[payloadField],
+				"id":         d.msg.ID,
+				"error":      msg,
+				"failed_at":  time.Now().UTC().Format(time.RFC3339Nano),
+			},
+		})
+		p.XAck(ctx, d.h.stream, w.opts.group, d.msg.ID)
+		p.XDel(ctx, d.h.stream, d.msg.ID)
+		return nil
+	})
+	if err != nil {
+		w.log.Errorf("bury job %s %s failed, err: %+v", d.h.job, d.msg.ID, err)
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
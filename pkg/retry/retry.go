@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -26,3 +27,8 @@ func BackoffRetryWithPolicy(f func() error, p *backoff.ExponentialBackOff) error
 func Permanent(err error) error {
 	return backoff.Permanent(err)
 }
+
+// BackoffRetryN retries f at most n times, it stops waiting when ctx is done
+func BackoffRetryN(ctx context.Context, f func() error, p *backoff.ExponentialBackOff, n uint64) error {
+	return backoff.Retry(f, backoff.WithContext(backoff.WithMaxRetries(p, n), ctx))
+}
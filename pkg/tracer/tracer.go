@@ -0,0 +1,99 @@
+package tracer
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"server-template/internal/conf"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Init installs the global tracer provider and the w3c trace context propagator.
+// The returned shutdown flushes buffered spans, call it after the app stopped.
+func Init(c *conf.Trace, name, version, env string) (shutdown func(context.Context) error, err error) {
+	ratio := 1.0
+	if c.SampleRatio != nil {
+		ratio = c.GetSampleRatio()
+	}
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(name),
+			semconv.ServiceVersion(version),
+			semconv.DeploymentEnvironment(env),
+		)),
+	}
+
+	var closer io.Closer
+	exporter, closer, err := newExporter(c)
+	if err != nil {
+		return nil, err
+	}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{}, propagation.Baggage{},
+	))
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closer != nil {
+			if cErr := closer.Close(); cErr != nil && err == nil {
+				err = cErr
+			}
+		}
+		return err
+	}, nil
+}
+
+func newExporter(c *conf.Trace) (sdktrace.SpanExporter, io.Closer, error) {
+	ctx := context.Background()
+	switch c.GetExporter() {
+	case conf.Trace_NONE:
+		return nil, nil, nil
+	case conf.Trace_OTLP_GRPC:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(c.Endpoint), otlptracegrpc.WithHeaders(c.Headers)}
+		if c.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exp, err := otlptracegrpc.New(ctx, opts...)
+		return exp, nil, errors.Wrap(err, "new otlp grpc exporter failed")
+	case conf.Trace_OTLP_HTTP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(c.Endpoint), otlptracehttp.WithHeaders(c.Headers)}
+		if c.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exp, err := otlptracehttp.New(ctx, opts...)
+		return exp, nil, errors.Wrap(err, "new otlp http exporter failed")
+	case conf.Trace_STDOUT:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exp, nil, errors.Wrap(err, "new stdout exporter failed")
+	case conf.Trace_FILE:
+		f, err := os.OpenFile(c.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "open trace file %s failed", c.File)
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, nil, errors.Wrap(err, "new file exporter failed")
+		}
+		return exp, f, nil
+	}
+	return nil, nil, errors.Errorf("unknown trace exporter: %v", c.GetExporter())
+}